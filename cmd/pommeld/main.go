@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 
+	"github.com/pommel-dev/pommel/internal/api"
 	"github.com/pommel-dev/pommel/internal/config"
 	"github.com/pommel-dev/pommel/internal/daemon"
 )
@@ -48,6 +49,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Serve the API router, which runs the full hybrid search pipeline
+	searcher := api.NewSearchServiceAdapter(d.SearchService())
+	d.SetHandler(api.NewRouter(d.Indexer(), cfg, searcher))
+
 	// Set up signal handling
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -49,6 +49,77 @@ var (
 		Suggestion: "Try a shorter, more focused query. Semantic search works best with concise descriptions",
 	}
 
+	// ErrInvalidScope is returned when a search scope can't be resolved.
+	ErrInvalidScope = APIError{
+		Code:       "INVALID_SCOPE",
+		Message:    "Search scope could not be resolved",
+		Suggestion: "Use 'pm subprojects' to list valid sub-project IDs, or search everything with --all",
+	}
+
+	// ErrInvalidFusion is returned when a search's fusion options are invalid.
+	ErrInvalidFusion = APIError{
+		Code:       "INVALID_FUSION",
+		Message:    "Search fusion options are not valid",
+		Suggestion: "Use fusion 'rrf' or 'linear', a non-negative rrf_k, and non-negative weights with at least one above zero",
+	}
+
+	// ErrInvalidTests is returned when a search's test-code inclusion mode is unknown.
+	ErrInvalidTests = APIError{
+		Code:       "INVALID_TESTS",
+		Message:    "Search tests mode is not valid",
+		Suggestion: "Use tests 'include', 'exclude' or 'only'",
+	}
+
+	// ErrInvalidDiversity is returned when a search's diversity options are out of range.
+	ErrInvalidDiversity = APIError{
+		Code:       "INVALID_DIVERSITY",
+		Message:    "Search diversity options are out of range",
+		Suggestion: "Use a diversity between 0 and 1 and a non-negative max_per_file",
+	}
+
+	// ErrInvalidMinScore is returned when a search's score cutoffs are out of range.
+	ErrInvalidMinScore = APIError{
+		Code:       "INVALID_MIN_SCORE",
+		Message:    "Search score cutoffs are out of range",
+		Suggestion: "Use a non-negative min_score and a min_relative_score between 0 and 1",
+	}
+
+	// ErrInvalidContextLines is returned when a search asks for a negative
+	// number of context lines.
+	ErrInvalidContextLines = APIError{
+		Code:       "INVALID_CONTEXT_LINES",
+		Message:    "Search context_lines must not be negative",
+		Suggestion: "Use a context_lines between 0 and 50",
+	}
+
+	// ErrInvalidCursor is returned when a search cursor can't be decoded.
+	ErrInvalidCursor = APIError{
+		Code:       "INVALID_CURSOR",
+		Message:    "Search cursor is not valid",
+		Suggestion: "Pass the next_cursor value of a previous search response unchanged",
+	}
+
+	// ErrCursorExpired is returned when the index has changed since a search cursor was issued.
+	ErrCursorExpired = APIError{
+		Code:       "CURSOR_EXPIRED",
+		Message:    "Search cursor has expired because the index changed or its results are no longer cached",
+		Suggestion: "Run the search again from the first page",
+	}
+
+	// ErrSimilarSourceMissing is returned when a similarity request doesn't identify a source chunk.
+	ErrSimilarSourceMissing = APIError{
+		Code:       "SIMILAR_SOURCE_MISSING",
+		Message:    "Similarity search needs a source chunk",
+		Suggestion: "Pass a chunk ID from a search result, or a file and line such as 'internal/api/handlers.go:42'",
+	}
+
+	// ErrSourceChunkNotFound is returned when the source chunk of a similarity search isn't indexed.
+	ErrSourceChunkNotFound = APIError{
+		Code:       "SOURCE_CHUNK_NOT_FOUND",
+		Message:    "Source chunk not found in the index",
+		Suggestion: "Check the chunk ID or file location. Recently changed files may still be indexing; see 'pm status'",
+	}
+
 	// ErrInvalidJSON is returned when the request body contains invalid JSON.
 	ErrInvalidJSON = APIError{
 		Code:       "INVALID_JSON",
@@ -197,6 +268,13 @@ var (
 		Message:    "No indexable files found in the project",
 		Suggestion: "Check your .pommelignore patterns. Pommel indexes: .go, .py, .js, .ts, .java, .rs, .cs files by default",
 	}
+
+	// ErrFTSRepairFailed is returned when the keyword index cannot be checked or rebuilt.
+	ErrFTSRepairFailed = APIError{
+		Code:       "FTS_REPAIR_FAILED",
+		Message:    "Failed to repair the keyword search index",
+		Suggestion: "Try 'pm reindex --force' to rebuild the whole index",
+	}
 )
 
 // =============================================================================
@@ -215,6 +293,11 @@ func WriteBadRequest(w http.ResponseWriter, err APIError) {
 	WriteError(w, http.StatusBadRequest, err)
 }
 
+// WriteNotFound writes a 404 Not Found response with the given error.
+func WriteNotFound(w http.ResponseWriter, err APIError) {
+	WriteError(w, http.StatusNotFound, err)
+}
+
 // WriteInternalError writes a 500 Internal Server Error response with the given error.
 func WriteInternalError(w http.ResponseWriter, err APIError) {
 	WriteError(w, http.StatusInternalServerError, err)
@@ -3,6 +3,9 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"strings"
@@ -24,6 +27,7 @@ type Handler struct {
 // Searcher defines the interface for search operations
 type Searcher interface {
 	Search(ctx context.Context, req SearchRequest) (*SearchResponse, error)
+	Similar(ctx context.Context, req SimilarRequest) (*SimilarResponse, error)
 }
 
 // NewHandler creates a new Handler instance
@@ -72,18 +76,52 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
 	stats := h.indexer.Stats()
 
+	indexStatus := &IndexStatus{
+		TotalFiles:     stats.TotalFiles,
+		TotalChunks:    stats.TotalChunks,
+		LastIndexedAt:  stats.LastIndexedAt,
+		IndexingActive: stats.IndexingActive,
+		PendingChanges: int(stats.PendingFiles),
+		Reconciling:    stats.Reconciling,
+		ChunksEmbedded: stats.ChunksEmbedded,
+		ChunksReused:   stats.ChunksReused,
+		FailedFiles:    stats.FailedFiles,
+	}
+	if stats.Reconciling {
+		indexStatus.Message = fmt.Sprintf("reconciling %d changes", stats.PendingFiles)
+	}
+
+	// Add progress information if indexing is active
+	if stats.IndexingActive && stats.FilesToProcess > 0 {
+		percentComplete := float64(stats.FilesProcessed) / float64(stats.FilesToProcess) * 100
+
+		// Calculate ETA from the file processing rate so far
+		var etaSeconds float64
+		if stats.FilesProcessed > 0 && !stats.IndexingStarted.IsZero() {
+			elapsed := time.Since(stats.IndexingStarted).Seconds()
+			rate := float64(stats.FilesProcessed) / elapsed
+			if rate > 0 {
+				remaining := stats.FilesToProcess - stats.FilesProcessed
+				etaSeconds = float64(remaining) / rate
+			}
+		}
+
+		indexStatus.Progress = &IndexProgress{
+			FilesToProcess:  stats.FilesToProcess,
+			FilesProcessed:  stats.FilesProcessed,
+			PercentComplete: percentComplete,
+			IndexingStarted: stats.IndexingStarted,
+			ETASeconds:      etaSeconds,
+		}
+	}
+
 	response := StatusResponse{
 		Daemon: &DaemonStatus{
 			Running:       true,
 			PID:           os.Getpid(),
 			UptimeSeconds: time.Since(h.startTime).Seconds(),
 		},
-		Index: &IndexStatus{
-			TotalFiles:     stats.TotalFiles,
-			TotalChunks:    stats.TotalChunks,
-			LastIndexedAt:  stats.LastIndexedAt,
-			IndexingActive: stats.IndexingActive,
-		},
+		Index: indexStatus,
 		Dependencies: &DependenciesStatus{
 			Database: true,
 			Embedder: true,
@@ -103,13 +141,79 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 	// Trim whitespace from query before validation
 	req.Query = strings.TrimSpace(req.Query)
 
-	if req.Query == "" {
+	// A cursor carries its own query
+	if req.Query == "" && req.Cursor == "" {
 		WriteBadRequest(w, ErrQueryEmpty)
 		return
 	}
 
+	if req.Diversity < 0 || req.Diversity > 1 || req.MaxPerFile < 0 {
+		WriteBadRequest(w, ErrInvalidDiversity)
+		return
+	}
+
+	if (req.MinScore != nil && *req.MinScore < 0) || !inUnitRange(req.MinRelativeScore) {
+		WriteBadRequest(w, ErrInvalidMinScore)
+		return
+	}
+
+	if req.ContextLines < 0 {
+		WriteBadRequest(w, ErrInvalidContextLines)
+		return
+	}
+
 	response, err := h.searcher.Search(r.Context(), req)
-	if err != nil {
+	switch {
+	case errors.Is(err, search.ErrInvalidScope):
+		WriteBadRequest(w, ErrInvalidScope.WithDetails(err.Error()))
+		return
+	case errors.Is(err, search.ErrInvalidFusion):
+		WriteBadRequest(w, ErrInvalidFusion.WithDetails(err.Error()))
+		return
+	case errors.Is(err, search.ErrInvalidTests):
+		WriteBadRequest(w, ErrInvalidTests.WithDetails(err.Error()))
+		return
+	case errors.Is(err, search.ErrInvalidCursor):
+		WriteBadRequest(w, ErrInvalidCursor.WithDetails(err.Error()))
+		return
+	case errors.Is(err, search.ErrCursorExpired):
+		WriteError(w, http.StatusGone, ErrCursorExpired.WithDetails(err.Error()))
+		return
+	case err != nil:
+		WriteInternalError(w, ErrSearchFailed.WithDetails(err.Error()))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Similar handles POST /similar requests
+func (h *Handler) Similar(w http.ResponseWriter, r *http.Request) {
+	var req SimilarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, ErrInvalidJSON.WithDetails(err.Error()))
+		return
+	}
+
+	req.ChunkID = strings.TrimSpace(req.ChunkID)
+	req.File = strings.TrimSpace(req.File)
+	if req.ChunkID == "" && (req.File == "" || req.Line <= 0) {
+		WriteBadRequest(w, ErrSimilarSourceMissing)
+		return
+	}
+
+	response, err := h.searcher.Similar(r.Context(), req)
+	switch {
+	case errors.Is(err, search.ErrSourceNotFound):
+		WriteNotFound(w, ErrSourceChunkNotFound.WithDetails(err.Error()))
+		return
+	case errors.Is(err, search.ErrInvalidScope):
+		WriteBadRequest(w, ErrInvalidScope.WithDetails(err.Error()))
+		return
+	case errors.Is(err, search.ErrInvalidTests):
+		WriteBadRequest(w, ErrInvalidTests.WithDetails(err.Error()))
+		return
+	case err != nil:
 		WriteInternalError(w, ErrSearchFailed.WithDetails(err.Error()))
 		return
 	}
@@ -132,6 +236,70 @@ func (h *Handler) Reindex(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusAccepted, response)
 }
 
+// RepairFTS handles POST /index/repair-fts requests.
+// It detects drift between the chunks table and the FTS keyword index and
+// rebuilds the keyword index if needed.
+func (h *Handler) RepairFTS(w http.ResponseWriter, r *http.Request) {
+	// An empty body is allowed and means no force
+	var req RepairFTSRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		WriteBadRequest(w, ErrInvalidJSON.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := h.indexer.RepairFTS(r.Context(), req.Force)
+	if err != nil {
+		WriteInternalError(w, ErrFTSRepairFailed.WithDetails(err.Error()))
+		return
+	}
+
+	drift := result.Drift
+	response := RepairFTSResponse{
+		Status:     "in_sync",
+		Message:    "Keyword index is in sync with the chunk index",
+		ChunkCount: drift.ChunkCount,
+		FTSCount:   drift.FTSCount,
+		Missing:    drift.Missing,
+		Orphaned:   drift.Orphaned,
+		Stale:      drift.Stale,
+		Duplicates: drift.Duplicates,
+	}
+	if result.Rebuilt {
+		response.Status = "rebuilt"
+		response.Message = fmt.Sprintf("Keyword index rebuilt with %d entries", result.Entries)
+		response.Entries = result.Entries
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Subprojects handles GET /subprojects requests
+func (h *Handler) Subprojects(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.indexer.Subprojects(r.Context())
+	if err != nil {
+		WriteInternalError(w, ErrDatabaseUnavailable.WithDetails(err.Error()))
+		return
+	}
+
+	response := SubprojectsResponse{
+		Subprojects: make([]SubprojectInfo, 0, len(summaries)),
+		Total:       len(summaries),
+	}
+	for _, sp := range summaries {
+		response.Subprojects = append(response.Subprojects, SubprojectInfo{
+			ID:         sp.ID,
+			Path:       sp.Path,
+			Name:       sp.Name,
+			MarkerFile: sp.MarkerFile,
+			Language:   sp.LanguageHint,
+			Files:      sp.Files,
+			Chunks:     sp.Chunks,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
 // Config handles GET /config requests
 func (h *Handler) Config(w http.ResponseWriter, r *http.Request) {
 	response := ConfigResponse{
@@ -144,6 +312,11 @@ func (h *Handler) Config(w http.ResponseWriter, r *http.Request) {
 // Helpers
 // =============================================================================
 
+// inUnitRange reports whether an optional value is unset or between 0 and 1.
+func inUnitRange(v *float64) bool {
+	return v == nil || (*v >= 0 && *v <= 1)
+}
+
 // writeJSON writes a JSON response with the given status code
 func writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -169,10 +342,32 @@ func NewSearchServiceAdapter(service *search.Service) *SearchServiceAdapter {
 func (a *SearchServiceAdapter) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
 	// Convert SearchRequest to search.Query
 	query := search.Query{
-		Text:       req.Query,
-		Limit:      req.Limit,
-		Levels:     req.Levels,
-		PathPrefix: req.PathPrefix,
+		Text:             req.Query,
+		Snippet:          req.Snippet,
+		Limit:            req.Limit,
+		Levels:           req.Levels,
+		PathPrefix:       req.PathPrefix,
+		Languages:        req.Languages,
+		Extensions:       req.Extensions,
+		Include:          req.Include,
+		Exclude:          req.Exclude,
+		Tests:            req.Tests,
+		Scope:            search.Scope{Mode: req.Scope.Mode, Value: req.Scope.Value},
+		HybridEnabled:    req.HybridEnabled,
+		RerankEnabled:    req.RerankEnabled,
+		Fusion:           req.Fusion,
+		RRFK:             req.RRFK,
+		VectorWeight:     req.VectorWeight,
+		KeywordWeight:    req.KeywordWeight,
+		Diversity:        req.Diversity,
+		MaxPerFile:       req.MaxPerFile,
+		MinScore:         req.MinScore,
+		MinRelativeScore: req.MinRelativeScore,
+		Page:             req.Page,
+		Cursor:           req.Cursor,
+		ContextLines:     req.ContextLines,
+		IncludeParent:    req.IncludeParent,
+		IncludeImports:   req.IncludeImports,
 	}
 
 	// Call search service
@@ -184,35 +379,161 @@ func (a *SearchServiceAdapter) Search(ctx context.Context, req SearchRequest) (*
 	// Convert search.Response to SearchResponse
 	results := make([]SearchResult, 0, len(resp.Results))
 	for _, r := range resp.Results {
-		result := SearchResult{
-			ID:            r.Chunk.ID,
-			File:          r.Chunk.FilePath,
-			StartLine:     r.Chunk.StartLine,
-			EndLine:       r.Chunk.EndLine,
-			Level:         string(r.Chunk.Level),
-			Language:      r.Chunk.Language,
-			Name:          r.Chunk.Name,
-			Score:         r.Score,
-			Content:       r.Chunk.Content,
-			MatchedSplits: r.MatchedSplits,
-		}
+		results = append(results, searchResult(r))
+	}
 
-		// Convert parent info if present
-		if r.Parent != nil {
-			result.Parent = &ParentInfo{
-				ID:    r.Parent.ID,
-				Name:  r.Parent.Name,
-				Level: r.Parent.Level,
-			}
-		}
+	return &SearchResponse{
+		Query:         resp.Query,
+		Results:       results,
+		TotalResults:  resp.TotalResults,
+		SearchTimeMs:  resp.SearchTimeMs,
+		Scope:         scopeResponse(resp.Scope),
+		HybridEnabled: resp.HybridEnabled,
+		RerankEnabled: resp.RerankEnabled,
+		Confidence:    resp.Confidence,
+		NextCursor:    resp.NextCursor,
+	}, nil
+}
 
-		results = append(results, result)
+// Similar implements the Searcher interface by delegating to the search service.
+func (a *SearchServiceAdapter) Similar(ctx context.Context, req SimilarRequest) (*SimilarResponse, error) {
+	resp, err := a.service.Similar(ctx, search.SimilarQuery{
+		ChunkID:    req.ChunkID,
+		File:       req.File,
+		Line:       req.Line,
+		Limit:      req.Limit,
+		Levels:     req.Levels,
+		PathPrefix: req.PathPrefix,
+		Languages:  req.Languages,
+		Extensions: req.Extensions,
+		Include:    req.Include,
+		Exclude:    req.Exclude,
+		Tests:      req.Tests,
+		Scope:      search.Scope{Mode: req.Scope.Mode, Value: req.Scope.Value},
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &SearchResponse{
-		Query:        resp.Query,
+	results := make([]SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, searchResult(r))
+	}
+
+	return &SimilarResponse{
+		Source:       searchResult(search.Result{Chunk: resp.Source}),
 		Results:      results,
 		TotalResults: resp.TotalResults,
 		SearchTimeMs: resp.SearchTimeMs,
+		Scope:        scopeResponse(resp.Scope),
 	}, nil
 }
+
+// searchResult converts a search result to its API form.
+func searchResult(r search.Result) SearchResult {
+	result := SearchResult{
+		ID:            r.Chunk.ID,
+		File:          r.Chunk.FilePath,
+		StartLine:     r.Chunk.StartLine,
+		EndLine:       r.Chunk.EndLine,
+		Level:         string(r.Chunk.Level),
+		Language:      r.Chunk.Language,
+		Name:          r.Chunk.Name,
+		Signature:     r.Chunk.Signature,
+		Score:         r.Score,
+		Content:       r.Chunk.Content,
+		ParentChunkID: r.Chunk.ParentChunkID,
+		ChunkIndex:    r.Chunk.ChunkIndex,
+		IsPartial:     r.Chunk.IsPartial,
+		MatchedSplits: r.MatchedSplits,
+		MatchSource:   r.MatchSource,
+		MatchReasons:  r.MatchReasons,
+		Snippet:       sourceLines(r.Snippet),
+		Context:       resultContext(r.Context),
+	}
+
+	for _, h := range r.Highlights {
+		result.Highlights = append(result.Highlights, Highlight{
+			Line:     h.Line,
+			StartCol: h.StartColumn,
+			EndCol:   h.EndColumn,
+			Term:     h.Term,
+		})
+	}
+
+	if r.Chunk.SubprojectID != nil {
+		result.SubprojectID = *r.Chunk.SubprojectID
+	}
+
+	if r.ScoreDetails != nil {
+		result.ScoreDetails = &ScoreDetails{
+			VectorScore:         r.ScoreDetails.VectorScore,
+			KeywordScore:        r.ScoreDetails.KeywordScore,
+			RRFScore:            r.ScoreDetails.RRFScore,
+			Fusion:              r.ScoreDetails.Fusion,
+			VectorContribution:  r.ScoreDetails.VectorContribution,
+			KeywordContribution: r.ScoreDetails.KeywordContribution,
+			RerankerScore:       r.ScoreDetails.RerankerScore,
+			Relevance:           r.ScoreDetails.Relevance,
+			SignalScores:        r.ScoreDetails.SignalScores,
+		}
+	}
+
+	// Convert parent info if present
+	if r.Parent != nil {
+		result.Parent = &ParentInfo{
+			ID:    r.Parent.ID,
+			Name:  r.Parent.Name,
+			Level: r.Parent.Level,
+		}
+	}
+
+	return result
+}
+
+// resultContext converts a result's context to its API form.
+func resultContext(rc *search.ResultContext) *ResultContext {
+	if rc == nil {
+		return nil
+	}
+	result := &ResultContext{
+		Before:  sourceLines(rc.Before),
+		After:   sourceLines(rc.After),
+		Imports: sourceLines(rc.Imports),
+	}
+	if rc.Parent != nil {
+		result.Parent = &ParentContext{
+			ID:        rc.Parent.ID,
+			Name:      rc.Parent.Name,
+			Level:     rc.Parent.Level,
+			Signature: rc.Parent.Signature,
+			Header:    sourceLines(rc.Parent.Header),
+		}
+	}
+	return result
+}
+
+// sourceLines converts a range of source lines to its API form.
+func sourceLines(lines *search.SourceLines) *SourceLines {
+	if lines == nil {
+		return nil
+	}
+	return &SourceLines{StartLine: lines.StartLine, EndLine: lines.EndLine, Content: lines.Content}
+}
+
+// scopeResponse converts a resolved search scope to its API form.
+func scopeResponse(scope *search.ResolvedScope) *SearchScopeResponse {
+	if scope == nil {
+		return nil
+	}
+	resp := &SearchScopeResponse{Mode: scope.Mode}
+	if scope.Subproject != "" {
+		subproject := scope.Subproject
+		resp.Subproject = &subproject
+	}
+	if scope.Path != "" {
+		path := scope.Path
+		resp.ResolvedPath = &path
+	}
+	return resp
+}
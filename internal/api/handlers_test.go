@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -15,6 +18,8 @@ import (
 	"github.com/pommel-dev/pommel/internal/daemon"
 	"github.com/pommel-dev/pommel/internal/db"
 	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/pommel-dev/pommel/internal/search"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -95,7 +100,8 @@ func setupTestIndexer(t *testing.T, projectRoot string, cfg *config.Config, data
 
 // mockSearcher implements the Searcher interface for testing
 type mockSearcher struct {
-	searchFunc func(ctx context.Context, req SearchRequest) (*SearchResponse, error)
+	searchFunc  func(ctx context.Context, req SearchRequest) (*SearchResponse, error)
+	similarFunc func(ctx context.Context, req SimilarRequest) (*SimilarResponse, error)
 }
 
 func (m *mockSearcher) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
@@ -110,6 +116,13 @@ func (m *mockSearcher) Search(ctx context.Context, req SearchRequest) (*SearchRe
 	}, nil
 }
 
+func (m *mockSearcher) Similar(ctx context.Context, req SimilarRequest) (*SimilarResponse, error) {
+	if m.similarFunc != nil {
+		return m.similarFunc(ctx, req)
+	}
+	return &SimilarResponse{Results: []SearchResult{}}, nil
+}
+
 // newTestHandler creates a Handler with test dependencies
 func newTestHandler(t *testing.T) (*Handler, func()) {
 	tmpDir := t.TempDir()
@@ -257,6 +270,33 @@ func TestStatusEndpointResponseHasIndexInfo(t *testing.T) {
 	assert.GreaterOrEqual(t, response.Index.TotalChunks, int64(0), "TotalChunks should be non-negative")
 }
 
+// TestStatusEndpointReportsEmbeddingReuse verifies that reused and embedded chunk counts are reported
+func TestStatusEndpointReportsEmbeddingReuse(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+	handler := NewHandler(indexer, cfg, &mockSearcher{})
+
+	ctx := context.Background()
+	path := filepath.Join(tmpDir, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0644))
+	require.NoError(t, indexer.IndexFile(ctx, path))
+
+	// Re-indexing unchanged content reuses every embedding
+	require.NoError(t, indexer.IndexFile(ctx, path))
+
+	rr := httptest.NewRecorder()
+	handler.Status(rr, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	var response StatusResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.NotNil(t, response.Index)
+	assert.Greater(t, response.Index.ChunksEmbedded, int64(0))
+	assert.Equal(t, response.Index.ChunksEmbedded, response.Index.ChunksReused)
+}
+
 // TestStatusEndpointResponseHasDependencies verifies that status response contains dependency info
 func TestStatusEndpointResponseHasDependencies(t *testing.T) {
 	handler, cleanup := newTestHandler(t)
@@ -1048,6 +1088,102 @@ func TestReindexEndpointResponseHasMessage(t *testing.T) {
 	assert.NotEmpty(t, response.Message, "expected message to be present")
 }
 
+// =============================================================================
+// Repair FTS Endpoint Tests
+// =============================================================================
+
+// TestRepairFTSEndpointReportsInSync verifies that an empty index is reported as in sync
+func TestRepairFTSEndpointReportsInSync(t *testing.T) {
+	handler, cleanup := newTestHandler(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/index/repair-fts", nil)
+	rr := httptest.NewRecorder()
+
+	handler.RepairFTS(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response RepairFTSResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "in_sync", response.Status)
+	assert.NotEmpty(t, response.Message)
+}
+
+// TestSubprojectsEndpointListsSubprojectsWithCounts verifies that GET /subprojects
+// reports synced sub-projects with their indexed file and chunk counts
+func TestSubprojectsEndpointListsSubprojectsWithCounts(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Subprojects.AutoDetect = true
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+
+	dir := filepath.Join(tmpDir, "services", "billing")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module billing\n"), 0644))
+	path := filepath.Join(dir, "invoice.go")
+	require.NoError(t, os.WriteFile(path, []byte("package billing\n\nfunc Total() int {\n\treturn 0\n}\n"), 0644))
+
+	ctx := context.Background()
+	require.NoError(t, indexer.SyncSubprojects(ctx))
+	require.NoError(t, indexer.IndexFile(ctx, path))
+
+	handler := NewHandler(indexer, cfg, &mockSearcher{})
+	req := httptest.NewRequest(http.MethodGet, "/subprojects", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Subprojects(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response SubprojectsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	require.Len(t, response.Subprojects, 1)
+	sp := response.Subprojects[0]
+	assert.Equal(t, "billing", sp.ID)
+	assert.Equal(t, filepath.Join("services", "billing"), sp.Path)
+	assert.Equal(t, "go.mod", sp.MarkerFile)
+	assert.Equal(t, "go", sp.Language)
+	assert.Equal(t, int64(1), sp.Files)
+	assert.Positive(t, sp.Chunks)
+}
+
+// TestRepairFTSEndpointForceRebuilds verifies that force rebuilds the keyword index
+func TestRepairFTSEndpointForceRebuilds(t *testing.T) {
+	handler, cleanup := newTestHandler(t)
+	defer cleanup()
+
+	body, err := json.Marshal(RepairFTSRequest{Force: true})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/index/repair-fts", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.RepairFTS(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response RepairFTSResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "rebuilt", response.Status)
+}
+
+// TestRepairFTSEndpointInvalidJSON verifies that invalid JSON returns 400
+func TestRepairFTSEndpointInvalidJSON(t *testing.T) {
+	handler, cleanup := newTestHandler(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/index/repair-fts", bytes.NewReader([]byte("{invalid")))
+	rr := httptest.NewRecorder()
+
+	handler.RepairFTS(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
 // =============================================================================
 // Config Endpoint Tests
 // =============================================================================
@@ -1182,6 +1318,110 @@ func TestNewSearchServiceAdapterCreatesAdapter(t *testing.T) {
 	assert.NotNil(t, adapter)
 }
 
+// TestSearchServiceAdapterMapsHybridFields verifies that match source, score
+// details and match reasons from the search pipeline reach the API response
+func TestSearchServiceAdapterMapsHybridFields(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	emb := embedder.NewMockEmbedder()
+
+	chunk := &models.Chunk{
+		FilePath:  "/project/auth/login.go",
+		StartLine: 1,
+		EndLine:   10,
+		Level:     models.ChunkLevelMethod,
+		Name:      "HandleLogin",
+		Content:   "func HandleLogin() { validateCredentials() }",
+		Language:  "go",
+	}
+	chunk.SetHashes()
+	fileID, err := database.InsertFile(ctx, chunk.FilePath, "hash", "go", 100, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, database.InsertChunk(ctx, chunk, fileID))
+	vec, err := emb.EmbedSingle(ctx, chunk.Content)
+	require.NoError(t, err)
+	require.NoError(t, database.InsertEmbedding(ctx, chunk.ID, vec))
+	require.NoError(t, database.InsertFTSEntry(ctx, chunk))
+
+	service := search.NewServiceWithOptions(database, emb, search.ServiceOptions{
+		Hybrid:        search.DefaultHybridConfig(),
+		RerankEnabled: true,
+	})
+	adapter := NewSearchServiceAdapter(service)
+
+	resp, err := adapter.Search(ctx, SearchRequest{Query: "validateCredentials", Limit: 5})
+	require.NoError(t, err)
+
+	assert.True(t, resp.HybridEnabled)
+	assert.True(t, resp.RerankEnabled)
+	require.Len(t, resp.Results, 1)
+
+	result := resp.Results[0]
+	assert.Equal(t, "both", result.MatchSource)
+	require.NotNil(t, result.ScoreDetails)
+	assert.Greater(t, result.ScoreDetails.RRFScore, 0.0)
+	assert.NotEmpty(t, result.ScoreDetails.SignalScores)
+	assert.Contains(t, result.MatchReasons, "semantic similarity")
+	assert.Contains(t, result.MatchReasons, "keyword match via BM25")
+
+	// Per-request override disables hybrid search
+	disabled := false
+	resp, err = adapter.Search(ctx, SearchRequest{Query: "validateCredentials", Limit: 5, HybridEnabled: &disabled})
+	require.NoError(t, err)
+	assert.False(t, resp.HybridEnabled)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "vector", resp.Results[0].MatchSource)
+}
+
+func TestSearchServiceAdapterMapsChunkModelFields(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	emb := embedder.NewMockEmbedder()
+
+	subprojectID := "backend"
+	subprojectPath := "backend"
+	chunk := &models.Chunk{
+		FilePath:       "/project/backend/server.go",
+		StartLine:      20,
+		EndLine:        60,
+		Level:          models.ChunkLevelMethod,
+		Name:           "Serve",
+		Signature:      "func (s *Server) Serve(ctx context.Context) error",
+		Content:        "func (s *Server) Serve(ctx context.Context) error { return s.listen(ctx) }",
+		Language:       "go",
+		SubprojectID:   &subprojectID,
+		SubprojectPath: &subprojectPath,
+		ParentChunkID:  "serve-original",
+		ChunkIndex:     1,
+		IsPartial:      true,
+	}
+	chunk.SetHashes()
+	fileID, err := database.InsertFile(ctx, chunk.FilePath, "hash", "go", 100, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, database.InsertChunk(ctx, chunk, fileID))
+	vec, err := emb.EmbedSingle(ctx, chunk.Content)
+	require.NoError(t, err)
+	require.NoError(t, database.InsertEmbedding(ctx, chunk.ID, vec))
+
+	adapter := NewSearchServiceAdapter(search.NewService(database, emb))
+
+	resp, err := adapter.Search(ctx, SearchRequest{Query: "serve server", Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+
+	result := resp.Results[0]
+	assert.Equal(t, "go", result.Language)
+	assert.Equal(t, chunk.Signature, result.Signature)
+	assert.Equal(t, "backend", result.SubprojectID)
+	assert.Equal(t, "serve-original", result.ParentChunkID)
+	assert.Equal(t, 1, result.ChunkIndex)
+	assert.True(t, result.IsPartial)
+}
+
 // TestSearchHandler_SearcherReturnsError verifies error handling when searcher fails
 func TestSearchHandler_SearcherReturnsError(t *testing.T) {
 	// Create a searcher that returns an error
@@ -1430,3 +1670,451 @@ func TestSearchHandler_AllLevels(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Equal(t, allLevels, receivedLevels)
 }
+
+func TestSearchServiceAdapterResolvesScope(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	emb := embedder.NewMockEmbedder()
+
+	billing := &models.Subproject{ID: "billing", Path: "services/billing"}
+	billing.SetTimestamps()
+	require.NoError(t, database.InsertSubproject(ctx, billing))
+
+	for _, path := range []string{"/project/services/billing/invoice.go", "/project/web/invoice.go"} {
+		chunk := &models.Chunk{
+			FilePath:  path,
+			StartLine: 1,
+			EndLine:   5,
+			Level:     models.ChunkLevelMethod,
+			Name:      "invoiceTotal",
+			Content:   "func invoiceTotal() int { return 0 }",
+			Language:  "go",
+		}
+		if path == "/project/services/billing/invoice.go" {
+			chunk.SetSubproject(billing)
+		}
+		chunk.SetHashes()
+		fileID, err := database.InsertFile(ctx, chunk.FilePath, "hash", "go", 100, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, database.InsertChunk(ctx, chunk, fileID))
+		vec, err := emb.EmbedSingle(ctx, chunk.Content)
+		require.NoError(t, err)
+		require.NoError(t, database.InsertEmbedding(ctx, chunk.ID, vec))
+	}
+
+	hybrid := search.DefaultHybridConfig()
+	hybrid.Enabled = false
+	adapter := NewSearchServiceAdapter(search.NewServiceWithOptions(database, emb, search.ServiceOptions{
+		Hybrid:      hybrid,
+		ProjectRoot: "/project",
+	}))
+
+	resp, err := adapter.Search(ctx, SearchRequest{
+		Query: "invoice total",
+		Scope: SearchScopeRequest{Mode: "auto", Value: "/project/services/billing"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "/project/services/billing/invoice.go", resp.Results[0].File)
+	require.NotNil(t, resp.Scope)
+	assert.Equal(t, "subproject", resp.Scope.Mode)
+	require.NotNil(t, resp.Scope.Subproject)
+	assert.Equal(t, "billing", *resp.Scope.Subproject)
+	require.NotNil(t, resp.Scope.ResolvedPath)
+	assert.Equal(t, "services/billing", *resp.Scope.ResolvedPath)
+}
+
+func TestSearchServiceAdapterAppliesFilters(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	emb := embedder.NewMockEmbedder()
+
+	for _, path := range []string{"/project/billing/invoice.go", "/project/billing/invoice_test.go", "/project/web/invoice.ts"} {
+		chunk := &models.Chunk{
+			FilePath:  path,
+			StartLine: 1,
+			EndLine:   5,
+			Level:     models.ChunkLevelMethod,
+			Name:      "invoiceTotal",
+			Content:   "invoiceTotal " + path,
+			Language:  "go",
+		}
+		chunk.SetHashes()
+		fileID, err := database.InsertFile(ctx, chunk.FilePath, "hash", "go", 100, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, database.InsertChunk(ctx, chunk, fileID))
+		vec, err := emb.EmbedSingle(ctx, chunk.Content)
+		require.NoError(t, err)
+		require.NoError(t, database.InsertEmbedding(ctx, chunk.ID, vec))
+	}
+
+	adapter := NewSearchServiceAdapter(search.NewServiceWithOptions(database, emb, search.ServiceOptions{
+		Hybrid:      search.DefaultHybridConfig(),
+		ProjectRoot: "/project",
+	}))
+
+	resp, err := adapter.Search(ctx, SearchRequest{
+		Query:      "invoice total",
+		Languages:  []string{"go"},
+		Extensions: []string{"go"},
+		Include:    []string{"billing/**"},
+		Exclude:    []string{"**/*_test.go"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "/project/billing/invoice.go", resp.Results[0].File)
+}
+
+func TestSearchHandler_InvalidScopeReturns400(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+
+	adapter := NewSearchServiceAdapter(search.NewService(database, embedder.NewMockEmbedder()))
+	handler := NewHandler(indexer, cfg, adapter)
+
+	body, err := json.Marshal(SearchRequest{
+		Query: "invoice total",
+		Scope: SearchScopeRequest{Mode: "subproject", Value: "unknown"},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.Search(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "INVALID_SCOPE")
+}
+
+func TestSearchHandler_InvalidFusionReturns400(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+
+	adapter := NewSearchServiceAdapter(search.NewService(database, embedder.NewMockEmbedder()))
+	handler := NewHandler(indexer, cfg, adapter)
+
+	req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBufferString(`{"query": "invoice total", "fusion": "average"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.Search(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "INVALID_FUSION")
+}
+
+func TestSearchHandler_InvalidTestsReturns400(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+
+	adapter := NewSearchServiceAdapter(search.NewService(database, embedder.NewMockEmbedder()))
+	handler := NewHandler(indexer, cfg, adapter)
+
+	req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBufferString(`{"query": "invoice total", "tests": "sometimes"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.Search(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "INVALID_TESTS")
+}
+
+func TestSearchHandler_InvalidDiversityReturns400(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+
+	adapter := NewSearchServiceAdapter(search.NewService(database, embedder.NewMockEmbedder()))
+	handler := NewHandler(indexer, cfg, adapter)
+
+	for _, body := range []string{
+		`{"query": "invoice total", "diversity": 1.5}`,
+		`{"query": "invoice total", "max_per_file": -1}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Search(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+		assert.Contains(t, rr.Body.String(), "INVALID_DIVERSITY", body)
+	}
+}
+
+func TestSearchHandler_NegativeContextLinesReturns400(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+
+	adapter := NewSearchServiceAdapter(search.NewService(database, embedder.NewMockEmbedder()))
+	handler := NewHandler(indexer, cfg, adapter)
+
+	req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBufferString(`{"query": "invoice total", "context_lines": -1}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.Search(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "INVALID_CONTEXT_LINES")
+}
+
+func TestResultContext_Conversion(t *testing.T) {
+	assert.Nil(t, resultContext(nil))
+
+	rc := resultContext(&search.ResultContext{
+		Before: &search.SourceLines{StartLine: 8, EndLine: 9, Content: "// Validate checks"},
+		Parent: &search.ParentContext{
+			ID: "p1", Name: "Invoice", Level: "class", Signature: "type Invoice struct",
+			Header: &search.SourceLines{StartLine: 3, EndLine: 4, Content: "type Invoice struct {\n\tTotal int"},
+		},
+	})
+
+	require.NotNil(t, rc)
+	assert.Equal(t, &SourceLines{StartLine: 8, EndLine: 9, Content: "// Validate checks"}, rc.Before)
+	assert.Nil(t, rc.After)
+	assert.Nil(t, rc.Imports)
+	require.NotNil(t, rc.Parent)
+	assert.Equal(t, "type Invoice struct", rc.Parent.Signature)
+	assert.Equal(t, 3, rc.Parent.Header.StartLine)
+}
+
+func TestSearchResult_Highlights(t *testing.T) {
+	result := searchResult(search.Result{
+		Chunk: &models.Chunk{ID: "c1", FilePath: "invoice.go", StartLine: 10, EndLine: 12, Level: models.ChunkLevelMethod},
+		Highlights: []search.Highlight{
+			{Line: 10, StartColumn: 6, EndColumn: 13, Term: "invoice"},
+		},
+		Snippet: &search.SourceLines{StartLine: 10, EndLine: 11, Content: "func InvoiceTotal() int {\n\treturn 0"},
+	})
+
+	assert.Equal(t, []Highlight{{Line: 10, StartCol: 6, EndCol: 13, Term: "invoice"}}, result.Highlights)
+	require.NotNil(t, result.Snippet)
+	assert.Equal(t, 10, result.Snippet.StartLine)
+	assert.Equal(t, 11, result.Snippet.EndLine)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"highlights":[{"line":10,"start_col":6,"end_col":13,"term":"invoice"}]`)
+}
+
+func TestSearchHandler_InvalidMinScoreReturns400(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+
+	adapter := NewSearchServiceAdapter(search.NewService(database, embedder.NewMockEmbedder()))
+	handler := NewHandler(indexer, cfg, adapter)
+
+	for _, body := range []string{
+		`{"query": "invoice total", "min_score": -0.1}`,
+		`{"query": "invoice total", "min_relative_score": 1.2}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Search(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+		assert.Contains(t, rr.Body.String(), "INVALID_MIN_SCORE", body)
+	}
+}
+
+func TestSearchHandler_ReportsConfidence(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+
+	adapter := NewSearchServiceAdapter(search.NewService(database, embedder.NewMockEmbedder()))
+	handler := NewHandler(indexer, cfg, adapter)
+
+	req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBufferString(`{"query": "invoice total", "min_relative_score": 0.6}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.Search(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp SearchResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, search.ConfidenceLow, resp.Confidence, "an empty index has no good match")
+}
+
+func TestSearchHandler_CursorPaging(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+	emb := embedder.NewMockEmbedder()
+
+	for i := 0; i < 3; i++ {
+		chunk := &models.Chunk{
+			FilePath:  fmt.Sprintf("/project/billing/invoice%d.go", i),
+			StartLine: 1,
+			EndLine:   5,
+			Level:     models.ChunkLevelMethod,
+			Name:      "invoiceTotal",
+			Content:   fmt.Sprintf("func invoiceTotal%d() int { return %d }", i, i),
+			Language:  "go",
+		}
+		chunk.SetHashes()
+		fileID, err := database.InsertFile(ctx, chunk.FilePath, "hash", "go", 100, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, database.InsertChunk(ctx, chunk, fileID))
+		vec, err := emb.EmbedSingle(ctx, chunk.Content)
+		require.NoError(t, err)
+		require.NoError(t, database.InsertEmbedding(ctx, chunk.ID, vec))
+	}
+
+	handler := NewHandler(indexer, cfg, NewSearchServiceAdapter(search.NewService(database, emb)))
+	post := func(req SearchRequest) *httptest.ResponseRecorder {
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		r := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBuffer(body))
+		r.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		handler.Search(rr, r)
+		return rr
+	}
+
+	rr := post(SearchRequest{Query: "invoice total", Limit: 2})
+	require.Equal(t, http.StatusOK, rr.Code)
+	var first SearchResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &first))
+	require.Len(t, first.Results, 2)
+	require.NotEmpty(t, first.NextCursor)
+
+	rr = post(SearchRequest{Cursor: first.NextCursor})
+	require.Equal(t, http.StatusOK, rr.Code)
+	var second SearchResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &second))
+	require.Len(t, second.Results, 1)
+	assert.Equal(t, "invoice total", second.Query)
+	assert.Empty(t, second.NextCursor)
+	assert.NotContains(t, []string{first.Results[0].ID, first.Results[1].ID}, second.Results[0].ID)
+
+	rr = post(SearchRequest{Cursor: "garbage"})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "INVALID_CURSOR")
+
+	require.NoError(t, database.DeleteFileByPath(ctx, "/project/billing/invoice0.go"))
+	rr = post(SearchRequest{Cursor: first.NextCursor})
+	assert.Equal(t, http.StatusGone, rr.Code)
+	assert.Contains(t, rr.Body.String(), "CURSOR_EXPIRED")
+}
+
+func TestSimilarServiceAdapterExcludesSource(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	emb := embedder.NewMockEmbedder()
+
+	var sourceID string
+	for i, path := range []string{"/project/retry.go", "/project/backoff.go", "/project/web/retry.ts"} {
+		chunk := &models.Chunk{
+			FilePath:  path,
+			StartLine: 1,
+			EndLine:   5,
+			Level:     models.ChunkLevelMethod,
+			Name:      "retry",
+			Content:   "func retry(attempts int) error { return nil }",
+			Language:  "go",
+		}
+		chunk.SetHashes()
+		fileID, err := database.InsertFile(ctx, chunk.FilePath, "hash", "go", 100, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, database.InsertChunk(ctx, chunk, fileID))
+		vec, err := emb.EmbedSingle(ctx, chunk.Content)
+		require.NoError(t, err)
+		require.NoError(t, database.InsertEmbedding(ctx, chunk.ID, vec))
+		if i == 0 {
+			sourceID = chunk.ID
+		}
+	}
+
+	adapter := NewSearchServiceAdapter(search.NewServiceWithOptions(database, emb, search.ServiceOptions{
+		Hybrid:      search.DefaultHybridConfig(),
+		ProjectRoot: "/project",
+	}))
+
+	resp, err := adapter.Similar(ctx, SimilarRequest{File: "retry.go", Line: 3, PathPrefix: "/project/"})
+	require.NoError(t, err)
+
+	assert.Equal(t, sourceID, resp.Source.ID)
+	assert.Equal(t, "/project/retry.go", resp.Source.File)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, 2, resp.TotalResults)
+	for _, r := range resp.Results {
+		assert.NotEqual(t, sourceID, r.ID, "source should be excluded")
+		assert.Equal(t, "vector", r.MatchSource)
+	}
+}
+
+func TestSimilarHandler_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+	indexer := setupTestIndexer(t, tmpDir, cfg, database)
+
+	adapter := NewSearchServiceAdapter(search.NewService(database, embedder.NewMockEmbedder()))
+	handler := NewHandler(indexer, cfg, adapter)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"invalid json", `{`, http.StatusBadRequest, "INVALID_JSON"},
+		{"no source", `{}`, http.StatusBadRequest, "SIMILAR_SOURCE_MISSING"},
+		{"file without line", `{"file": "main.go"}`, http.StatusBadRequest, "SIMILAR_SOURCE_MISSING"},
+		{"unknown chunk", `{"chunk_id": "missing"}`, http.StatusNotFound, "SOURCE_CHUNK_NOT_FOUND"},
+		{"unindexed location", `{"file": "main.go", "line": 3}`, http.StatusNotFound, "SOURCE_CHUNK_NOT_FOUND"},
+		{"invalid tests mode", `{"chunk_id": "missing", "tests": "sometimes"}`, http.StatusBadRequest, "INVALID_TESTS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/similar", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			handler.Similar(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantCode)
+		})
+	}
+}
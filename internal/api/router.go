@@ -31,7 +31,10 @@ func NewRouter(indexer *daemon.Indexer, cfg *config.Config, searcher Searcher) *
 	r.Get("/health", handler.Health)
 	r.Get("/status", handler.Status)
 	r.Post("/search", handler.Search)
+	r.Post("/similar", handler.Similar)
 	r.Post("/reindex", handler.Reindex)
+	r.Post("/index/repair-fts", handler.RepairFTS)
+	r.Get("/subprojects", handler.Subprojects)
 	r.Get("/config", handler.Config)
 
 	return &Router{
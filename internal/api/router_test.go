@@ -116,6 +116,10 @@ func (m *mockRouterSearcher) Search(ctx context.Context, req SearchRequest) (*Se
 	}, nil
 }
 
+func (m *mockRouterSearcher) Similar(ctx context.Context, req SimilarRequest) (*SimilarResponse, error) {
+	return &SimilarResponse{Results: []SearchResult{}, SearchTimeMs: 1}, nil
+}
+
 // =============================================================================
 // Route Registration Tests
 // =============================================================================
@@ -199,6 +203,52 @@ func TestRouterRegistersConfigRoute(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code, "expected /config to return 200")
 }
 
+// TestRouterRegistersRepairFTSRoute verifies that /index/repair-fts route is registered
+func TestRouterRegistersRepairFTSRoute(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/index/repair-fts", nil)
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	assert.NotEqual(t, http.StatusNotFound, rr.Code, "expected /index/repair-fts route to be registered")
+	assert.Equal(t, http.StatusOK, rr.Code, "expected /index/repair-fts to return 200")
+}
+
+// TestRouterRegistersSubprojectsRoute verifies that /subprojects route is registered
+func TestRouterRegistersSubprojectsRoute(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/subprojects", nil)
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	assert.NotEqual(t, http.StatusNotFound, rr.Code, "expected /subprojects route to be registered")
+	assert.Equal(t, http.StatusOK, rr.Code, "expected /subprojects to return 200")
+}
+
+// TestRouterRegistersSimilarRoute verifies that /similar route is registered
+func TestRouterRegistersSimilarRoute(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	body, err := json.Marshal(SimilarRequest{ChunkID: "chunk-1"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/similar", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	assert.NotEqual(t, http.StatusNotFound, rr.Code, "expected /similar route to be registered")
+	assert.Equal(t, http.StatusOK, rr.Code, "expected /similar to return 200 for valid request")
+}
+
 // =============================================================================
 // HTTP Method Tests
 // =============================================================================
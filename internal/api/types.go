@@ -8,19 +8,40 @@ import "time"
 
 // SearchRequest represents a search query request
 type SearchRequest struct {
-	Query         string             `json:"query"`
-	Limit         int                `json:"limit,omitempty"`
-	Levels        []string           `json:"levels,omitempty"`
-	PathPrefix    string             `json:"path_prefix,omitempty"`
-	Scope         SearchScopeRequest `json:"scope,omitempty"`
-	HybridEnabled *bool              `json:"hybrid_enabled,omitempty"` // nil = use config default, true/false = explicit
-	RerankEnabled *bool              `json:"rerank_enabled,omitempty"` // nil = use config default, true/false = explicit
+	Query            string             `json:"query"`
+	Snippet          bool               `json:"snippet,omitempty"` // Query is a code snippet rather than natural language
+	Limit            int                `json:"limit,omitempty"`
+	Levels           []string           `json:"levels,omitempty"`
+	PathPrefix       string             `json:"path_prefix,omitempty"`
+	Languages        []string           `json:"languages,omitempty"`  // Language names or extensions (e.g., "go", "ts")
+	Extensions       []string           `json:"extensions,omitempty"` // File extensions (e.g., ".go" or "go")
+	Include          []string           `json:"include,omitempty"`    // Gitignore-style patterns a file must match
+	Exclude          []string           `json:"exclude,omitempty"`    // Gitignore-style patterns of files to leave out
+	Tests            string             `json:"tests,omitempty"`      // "include", "exclude" or "only" test files; empty = include
+	Scope            SearchScopeRequest `json:"scope,omitempty"`
+	HybridEnabled    *bool              `json:"hybrid_enabled,omitempty"`     // nil = use config default, true/false = explicit
+	RerankEnabled    *bool              `json:"rerank_enabled,omitempty"`     // nil = use config default, true/false = explicit
+	Fusion           string             `json:"fusion,omitempty"`             // "rrf" or "linear"; empty = use config default
+	RRFK             int                `json:"rrf_k,omitempty"`              // RRF constant k; 0 = use config default
+	VectorWeight     *float64           `json:"vector_weight,omitempty"`      // Weight of the vector leg; nil = use config default
+	KeywordWeight    *float64           `json:"keyword_weight,omitempty"`     // Weight of the keyword leg; nil = use config default
+	Diversity        float64            `json:"diversity,omitempty"`          // 0-1 trade of relevance for variety (MMR); 0 = off
+	MaxPerFile       int                `json:"max_per_file,omitempty"`       // Maximum results from any one file per page; 0 = no cap
+	MinScore         *float64           `json:"min_score,omitempty"`          // Drop results less relevant than this; nil = use config default
+	MinRelativeScore *float64           `json:"min_relative_score,omitempty"` // Drop results below this fraction of the top score; nil = use config default
+	Page             int                `json:"page,omitempty"`               // 1-based page of Limit results
+	Cursor           string             `json:"cursor,omitempty"`             // next_cursor of a previous response; replaces the query and filters
+
+	// Context expansion, returned in SearchResult.Context
+	ContextLines   int  `json:"context_lines,omitempty"`   // Lines before and after each result, read from disk (max 50)
+	IncludeParent  bool `json:"include_parent,omitempty"`  // Signature and header of the enclosing class or file
+	IncludeImports bool `json:"include_imports,omitempty"` // Import block of the result's file
 }
 
 // SearchScopeRequest specifies the search scope in the request
 type SearchScopeRequest struct {
 	Mode  string `json:"mode"`            // "all", "path", "subproject", "auto"
-	Value string `json:"value,omitempty"` // path prefix or subproject ID
+	Value string `json:"value,omitempty"` // path prefix, subproject ID, or the caller's working directory for "auto"
 }
 
 // SearchResponse represents the search results response
@@ -32,6 +53,8 @@ type SearchResponse struct {
 	Scope         *SearchScopeResponse `json:"scope,omitempty"`
 	HybridEnabled bool                 `json:"hybrid_enabled"`
 	RerankEnabled bool                 `json:"rerank_enabled"`
+	Confidence    string               `json:"confidence"`            // "high", "medium" or "low": whether the results are likely what was asked for
+	NextCursor    string               `json:"next_cursor,omitempty"` // Pass as cursor to fetch the next page
 }
 
 // SearchScopeResponse provides scope information in the response
@@ -43,29 +66,74 @@ type SearchScopeResponse struct {
 
 // SearchResult represents a single search result
 type SearchResult struct {
-	ID            string        `json:"id"`
-	File          string        `json:"file"`
-	StartLine     int           `json:"start_line"`
-	EndLine       int           `json:"end_line"`
-	Level         string        `json:"level"`
-	Language      string        `json:"language"`
-	Name          string        `json:"name"`
-	Score         float32       `json:"score"`
-	Content       string        `json:"content"`
-	Parent        *ParentInfo   `json:"parent,omitempty"`
-	MatchSource   string        `json:"match_source,omitempty"`   // "vector", "keyword", or "both"
-	ScoreDetails  *ScoreDetails `json:"score_details,omitempty"`  // Detailed score breakdown
-	MatchReasons  []string      `json:"match_reasons,omitempty"`  // Human-readable match reasons
-	MatchedSplits int           `json:"matched_splits,omitempty"` // Number of chunk splits that matched (for boosted results)
+	ID            string         `json:"id"`
+	File          string         `json:"file"`
+	StartLine     int            `json:"start_line"`
+	EndLine       int            `json:"end_line"`
+	Level         string         `json:"level"`
+	Language      string         `json:"language"`
+	Name          string         `json:"name"`
+	Signature     string         `json:"signature,omitempty"`
+	Score         float32        `json:"score"`
+	Content       string         `json:"content"`
+	Parent        *ParentInfo    `json:"parent,omitempty"`
+	SubprojectID  string         `json:"subproject_id,omitempty"`
+	ParentChunkID string         `json:"parent_chunk_id,omitempty"` // Original chunk this split belongs to
+	ChunkIndex    int            `json:"chunk_index,omitempty"`     // Position of this split within the original chunk
+	IsPartial     bool           `json:"is_partial,omitempty"`      // Whether this chunk is a split of a larger chunk
+	MatchSource   string         `json:"match_source,omitempty"`    // "vector", "keyword", or "both"
+	ScoreDetails  *ScoreDetails  `json:"score_details,omitempty"`   // Detailed score breakdown
+	MatchReasons  []string       `json:"match_reasons,omitempty"`   // Human-readable match reasons
+	MatchedSplits int            `json:"matched_splits,omitempty"`  // Number of chunk splits that matched (for boosted results)
+	Highlights    []Highlight    `json:"highlights,omitempty"`      // Spans of content matching the query terms
+	Snippet       *SourceLines   `json:"snippet,omitempty"`         // A few lines around the best match
+	Context       *ResultContext `json:"context,omitempty"`         // Surrounding code, if requested
+}
+
+// Highlight is a span of a result's content that matched the query. Columns
+// are 1-based byte offsets within the line; end_col is one past the match.
+type Highlight struct {
+	Line     int    `json:"line"`
+	StartCol int    `json:"start_col"`
+	EndCol   int    `json:"end_col"`
+	Term     string `json:"term"`
+}
+
+// ResultContext is the code around a search result, kept apart from its content
+type ResultContext struct {
+	Before  *SourceLines   `json:"before,omitempty"`  // Lines just before the result
+	After   *SourceLines   `json:"after,omitempty"`   // Lines just after the result
+	Parent  *ParentContext `json:"parent,omitempty"`  // Enclosing class or file
+	Imports *SourceLines   `json:"imports,omitempty"` // Import block of the file
+}
+
+// SourceLines is a range of lines from a source file
+type SourceLines struct {
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Content   string `json:"content"`
+}
+
+// ParentContext describes the class or file a search result belongs to
+type ParentContext struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Level     string       `json:"level"`
+	Signature string       `json:"signature,omitempty"`
+	Header    *SourceLines `json:"header,omitempty"` // Start of the parent, up to the result
 }
 
 // ScoreDetails contains detailed score breakdown for a search result
 type ScoreDetails struct {
-	VectorScore   float64            `json:"vector_score,omitempty"`
-	KeywordScore  float64            `json:"keyword_score,omitempty"`
-	RRFScore      float64            `json:"rrf_score,omitempty"`
-	RerankerScore float64            `json:"reranker_score,omitempty"`
-	SignalScores  map[string]float64 `json:"signal_scores,omitempty"`
+	VectorScore         float64            `json:"vector_score,omitempty"`
+	KeywordScore        float64            `json:"keyword_score,omitempty"`
+	RRFScore            float64            `json:"rrf_score,omitempty"` // Fused score, by RRF or linear fusion
+	Fusion              string             `json:"fusion,omitempty"`
+	VectorContribution  float64            `json:"vector_contribution,omitempty"`  // Vector leg's share of rrf_score
+	KeywordContribution float64            `json:"keyword_contribution,omitempty"` // Keyword leg's share of rrf_score
+	RerankerScore       float64            `json:"reranker_score,omitempty"`
+	Relevance           float64            `json:"relevance,omitempty"` // Absolute relevance that confidence and min_score go by
+	SignalScores        map[string]float64 `json:"signal_scores,omitempty"`
 }
 
 // ParentInfo provides information about a parent code element
@@ -75,6 +143,32 @@ type ParentInfo struct {
 	Level string `json:"level"`
 }
 
+// SimilarRequest represents a request for chunks similar to an indexed chunk.
+// The source is given either by ChunkID or by File and Line.
+type SimilarRequest struct {
+	ChunkID    string             `json:"chunk_id,omitempty"`
+	File       string             `json:"file,omitempty"` // Absolute or project-relative path
+	Line       int                `json:"line,omitempty"` // Line within File covered by the source chunk
+	Limit      int                `json:"limit,omitempty"`
+	Levels     []string           `json:"levels,omitempty"`
+	PathPrefix string             `json:"path_prefix,omitempty"`
+	Languages  []string           `json:"languages,omitempty"`  // Language names or extensions (e.g., "go", "ts")
+	Extensions []string           `json:"extensions,omitempty"` // File extensions (e.g., ".go" or "go")
+	Include    []string           `json:"include,omitempty"`    // Gitignore-style patterns a file must match
+	Exclude    []string           `json:"exclude,omitempty"`    // Gitignore-style patterns of files to leave out
+	Tests      string             `json:"tests,omitempty"`      // "include", "exclude" or "only" test files; empty = include
+	Scope      SearchScopeRequest `json:"scope,omitempty"`
+}
+
+// SimilarResponse represents the chunks most similar to a source chunk
+type SimilarResponse struct {
+	Source       SearchResult         `json:"source"`
+	Results      []SearchResult       `json:"results"`
+	TotalResults int                  `json:"total_results"`
+	SearchTimeMs int64                `json:"search_time_ms"`
+	Scope        *SearchScopeResponse `json:"scope,omitempty"`
+}
+
 // =============================================================================
 // Status API Types
 // =============================================================================
@@ -100,6 +194,11 @@ type IndexStatus struct {
 	LastIndexedAt  time.Time `json:"last_indexed_at,omitempty"`
 	IndexingActive bool      `json:"indexing_active"`
 	PendingChanges int       `json:"pending_changes"`
+	Reconciling    bool      `json:"reconciling,omitempty"`  // Startup reconciliation in progress
+	Message        string    `json:"message,omitempty"`      // Human-readable index activity, e.g. "reconciling 3 changes"
+	ChunksEmbedded int64     `json:"chunks_embedded"`        // Chunks embedded since the daemon started
+	ChunksReused   int64     `json:"chunks_reused"`          // Unchanged chunks that kept their stored embedding
+	FailedFiles    int64     `json:"failed_files,omitempty"` // Files whose last update failed, waiting to be retried
 
 	// Progress tracking (only populated when indexing is active)
 	Progress *IndexProgress `json:"progress,omitempty"`
@@ -136,6 +235,24 @@ type ReindexResponse struct {
 	Message string `json:"message"`
 }
 
+// RepairFTSRequest represents a request to repair the keyword (FTS) index
+type RepairFTSRequest struct {
+	Force bool `json:"force"` // Rebuild even if no drift is detected
+}
+
+// RepairFTSResponse represents the keyword index repair response
+type RepairFTSResponse struct {
+	Status     string `json:"status"` // "in_sync" or "rebuilt"
+	Message    string `json:"message"`
+	ChunkCount int    `json:"chunk_count"`
+	FTSCount   int    `json:"fts_count"`
+	Missing    int    `json:"missing"`
+	Orphaned   int    `json:"orphaned"`
+	Stale      int    `json:"stale"`
+	Duplicates int    `json:"duplicates"`
+	Entries    int    `json:"entries,omitempty"` // FTS entries after a rebuild
+}
+
 // =============================================================================
 // Subprojects API Types
 // =============================================================================
@@ -153,6 +270,8 @@ type SubprojectInfo struct {
 	Name       string `json:"name,omitempty"`
 	MarkerFile string `json:"marker_file"`
 	Language   string `json:"language,omitempty"`
+	Files      int64  `json:"files"`  // Indexed files in the sub-project
+	Chunks     int64  `json:"chunks"` // Indexed chunks in the sub-project
 }
 
 // =============================================================================
@@ -11,6 +11,7 @@ import (
 
 	"github.com/pommel-dev/pommel/internal/config"
 	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/pommel-dev/pommel/internal/pathutil"
 )
 
 // Chunker interface that all chunkers implement
@@ -29,6 +30,7 @@ type ChunkerRegistry struct {
 	extensionToLang map[string]Language // maps file extensions to languages for O(1) lookup
 	fallback        Chunker
 	splitter        *Splitter
+	testPatterns    map[Language][]pathutil.Pattern
 }
 
 // NewChunkerRegistry creates a new ChunkerRegistry with all supported language chunkers.
@@ -83,6 +85,7 @@ func NewRegistryFromConfig(configDir string) (*ChunkerRegistry, error) {
 		extensionToLang: make(map[string]Language),
 		fallback:        NewFallbackChunker(),
 		splitter:        NewSplitter(DefaultMaxTokens),
+		testPatterns:    make(map[Language][]pathutil.Pattern),
 	}
 
 	if err := reg.loadConfigs(configDir); err != nil {
@@ -146,6 +149,10 @@ func (r *ChunkerRegistry) registerFromConfig(config *LanguageConfig) error {
 		r.extensionToLang[normalizedExt] = lang
 	}
 
+	if len(config.TestPatterns) > 0 {
+		r.testPatterns[lang] = pathutil.ParsePatterns(config.TestPatterns)
+	}
+
 	return nil
 }
 
@@ -174,6 +181,17 @@ func (r *ChunkerRegistry) GetLanguageForExtension(ext string) (Language, bool) {
 	return lang, ok
 }
 
+// IsTestFile reports whether a file holds tests by the conventions of its
+// language, such as Go's _test.go suffix or the src/test tree of JUnit.
+// The path should be relative to the project root.
+func (r *ChunkerRegistry) IsTestFile(path string) bool {
+	lang, ok := r.extensionToLang[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return false
+	}
+	return pathutil.MatchPatterns(r.testPatterns[lang], filepath.ToSlash(path))
+}
+
 // Chunk processes a source file and returns its chunks using the appropriate chunker
 func (r *ChunkerRegistry) Chunk(ctx context.Context, file *models.SourceFile) (*models.ChunkResult, error) {
 	if file == nil {
@@ -485,6 +485,33 @@ func TestChunkerRegistry_FallbackForUnsupportedExtension(t *testing.T) {
 	assert.Equal(t, models.ChunkLevelFile, result.Chunks[0].Level, "Fallback should produce file-level chunk")
 }
 
+func TestChunkerRegistry_IsTestFile(t *testing.T) {
+	reg, err := NewChunkerRegistry()
+	require.NoError(t, err)
+
+	tests := map[string]bool{
+		"internal/api/handler_test.go":                 true,
+		"internal/api/handler.go":                      false,
+		"src/test/java/com/acme/RateLimiterTest.java":  true,
+		"src/test/java/com/acme/Fixtures.java":         true,
+		"src/main/java/com/acme/RateLimiter.java":      false,
+		"app/src/test/kotlin/LimiterTest.kt":           true,
+		"pkg/test_limiter.py":                          true,
+		"tests/helpers.py":                             true,
+		"pkg/limiter.py":                               false,
+		"web/limiter.spec.ts":                          true,
+		"web/limiter.test.js":                          true,
+		"web/__tests__/limiter.tsx":                    true,
+		"web/limiter.ts":                               false,
+		"docs/testing.md":                              false,
+		"test_data.go":                                 false, // Python conventions don't apply to Go
+		"src/test/resources/application.unknownformat": false,
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, reg.IsTestFile(path), path)
+	}
+}
+
 func TestChunkerRegistry_RustChunker(t *testing.T) {
 	reg, err := NewChunkerRegistry()
 	require.NoError(t, err)
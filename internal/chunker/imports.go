@@ -0,0 +1,73 @@
+package chunker
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+)
+
+// ImportBlock is the import section of a source file.
+type ImportBlock struct {
+	StartLine int
+	EndLine   int
+	Content   string
+}
+
+// Imports returns the lines from the file's first top-level import statement
+// to its last, or nil if the file has none or the language has no import
+// node types configured.
+func (c *GenericChunker) Imports(ctx context.Context, content []byte) (*ImportBlock, error) {
+	if len(c.config.Extraction.Imports) == 0 || len(content) == 0 {
+		return nil, nil
+	}
+
+	tree, err := c.parser.Parse(ctx, c.language, content)
+	if err != nil {
+		return nil, err
+	}
+
+	root := tree.RootNode()
+	startLine, endLine := 0, 0
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		node := root.NamedChild(i)
+		if !c.config.IsImportNodeType(node.Type()) {
+			continue
+		}
+		if startLine == 0 {
+			startLine = int(node.StartPoint().Row) + 1
+		}
+		endLine = int(node.EndPoint().Row) + 1
+		// A node ending at column 0 stops at the end of the previous line
+		if node.EndPoint().Column == 0 && endLine > startLine {
+			endLine--
+		}
+	}
+	if startLine == 0 {
+		return nil, nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if endLine > len(lines) {
+		endLine = len(lines)
+	}
+	return &ImportBlock{
+		StartLine: startLine,
+		EndLine:   endLine,
+		Content:   strings.Join(lines[startLine-1:endLine], "\n"),
+	}, nil
+}
+
+// ExtractImports returns the import block of the file at path, parsed with
+// the grammar of the language its extension maps to. It returns nil for
+// languages without a config-driven chunker or import node types.
+func (r *ChunkerRegistry) ExtractImports(ctx context.Context, path string, content []byte) (*ImportBlock, error) {
+	lang, ok := r.extensionToLang[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, nil
+	}
+	chunker, ok := r.chunkers[lang].(*GenericChunker)
+	if !ok {
+		return nil, nil
+	}
+	return chunker.Imports(ctx, content)
+}
@@ -0,0 +1,81 @@
+package chunker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkerRegistry_ExtractImports(t *testing.T) {
+	reg, err := NewChunkerRegistry()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		path      string
+		source    string
+		startLine int
+		endLine   int
+		content   string
+	}{
+		{
+			name:      "go import groups",
+			path:      "main.go",
+			source:    "package main\n\nimport (\n\t\"fmt\"\n)\n\nimport \"os\"\n\nfunc main() {}\n",
+			startLine: 3,
+			endLine:   7,
+			content:   "import (\n\t\"fmt\"\n)\n\nimport \"os\"",
+		},
+		{
+			name:      "python imports",
+			path:      "app.py",
+			source:    "\"\"\"App.\"\"\"\nimport os\nfrom typing import List\n\n\ndef main():\n    pass\n",
+			startLine: 2,
+			endLine:   3,
+			content:   "import os\nfrom typing import List",
+		},
+		{
+			name:      "typescript imports",
+			path:      "app.ts",
+			source:    "import { a } from './a';\nimport type { B } from './b';\n\nexport const c = a;\n",
+			startLine: 1,
+			endLine:   2,
+			content:   "import { a } from './a';\nimport type { B } from './b';",
+		},
+		{
+			name:      "csharp usings",
+			path:      "App.cs",
+			source:    "using System;\nusing System.Linq;\n\nnamespace App {}\n",
+			startLine: 1,
+			endLine:   2,
+			content:   "using System;\nusing System.Linq;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, err := reg.ExtractImports(context.Background(), tt.path, []byte(tt.source))
+			require.NoError(t, err)
+			require.NotNil(t, block)
+			assert.Equal(t, tt.startLine, block.StartLine)
+			assert.Equal(t, tt.endLine, block.EndLine)
+			assert.Equal(t, tt.content, block.Content)
+		})
+	}
+}
+
+func TestChunkerRegistry_ExtractImports_None(t *testing.T) {
+	reg, err := NewChunkerRegistry()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	block, err := reg.ExtractImports(ctx, "main.go", []byte("package main\n\nfunc main() {}\n"))
+	require.NoError(t, err)
+	assert.Nil(t, block, "file without imports")
+
+	block, err = reg.ExtractImports(ctx, "notes.unknownext", []byte("import os\n"))
+	require.NoError(t, err)
+	assert.Nil(t, block, "unsupported language")
+}
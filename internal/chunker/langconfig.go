@@ -22,6 +22,11 @@ type LanguageConfig struct {
 	// Extensions lists the file extensions for this language (e.g., [".go", ".py"])
 	Extensions []string `yaml:"extensions"`
 
+	// TestPatterns lists gitignore-style patterns of files holding tests
+	// (e.g., ["*_test.go"], ["src/test/"]), matched against the path relative
+	// to the project root - optional
+	TestPatterns []string `yaml:"test_patterns"`
+
 	// TreeSitter contains tree-sitter grammar configuration
 	TreeSitter TreeSitterConfig `yaml:"tree_sitter"`
 
@@ -64,6 +69,10 @@ type ExtractionConfig struct {
 	// DocCommentPosition indicates where doc comments appear relative to the node
 	// Valid values: "preceding_siblings", "first_child", "parent_first_child"
 	DocCommentPosition string `yaml:"doc_comment_position"`
+
+	// Imports lists top-level node types that import other code
+	// (e.g., import_declaration, using_directive) - optional
+	Imports []string `yaml:"imports"`
 }
 
 // ParseLanguageConfig parses YAML data into a LanguageConfig struct.
@@ -221,6 +230,16 @@ func (c *LanguageConfig) IsBlockNodeType(nodeType string) bool {
 	return false
 }
 
+// IsImportNodeType returns true if the given node type is an import statement.
+func (c *LanguageConfig) IsImportNodeType(nodeType string) bool {
+	for _, t := range c.Extraction.Imports {
+		if t == nodeType {
+			return true
+		}
+	}
+	return false
+}
+
 // MatchesExtension returns true if the given file extension matches this language.
 // The comparison is case-insensitive.
 func (c *LanguageConfig) MatchesExtension(ext string) bool {
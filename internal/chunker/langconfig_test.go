@@ -51,6 +51,9 @@ display_name: Python
 extensions:
   - .py
   - .pyw
+test_patterns:
+  - "test_*.py"
+  - "tests/"
 tree_sitter:
   grammar: python
 chunk_mappings:
@@ -68,6 +71,9 @@ extraction:
     - string
     - comment
   doc_comment_position: first_child
+  imports:
+    - import_statement
+    - import_from_statement
 `
 
 	config, err := ParseLanguageConfig([]byte(yaml))
@@ -76,6 +82,7 @@ extraction:
 	assert.Equal(t, "python", config.Language)
 	assert.Equal(t, "Python", config.DisplayName)
 	assert.Equal(t, []string{".py", ".pyw"}, config.Extensions)
+	assert.Equal(t, []string{"test_*.py", "tests/"}, config.TestPatterns)
 	assert.Equal(t, "python", config.TreeSitter.Grammar)
 	assert.Equal(t, []string{"class_definition"}, config.ChunkMappings.Class)
 	assert.Equal(t, []string{"function_definition"}, config.ChunkMappings.Method)
@@ -83,6 +90,9 @@ extraction:
 	assert.Equal(t, "name", config.Extraction.NameField)
 	assert.Equal(t, []string{"string", "comment"}, config.Extraction.DocComments)
 	assert.Equal(t, "first_child", config.Extraction.DocCommentPosition)
+	assert.Equal(t, []string{"import_statement", "import_from_statement"}, config.Extraction.Imports)
+	assert.True(t, config.IsImportNodeType("import_from_statement"))
+	assert.False(t, config.IsImportNodeType("function_definition"))
 }
 
 func TestParseLanguageConfig_MinimalConfig(t *testing.T) {
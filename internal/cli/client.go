@@ -12,25 +12,6 @@ import (
 	"github.com/pommel-dev/pommel/internal/daemon"
 )
 
-// daemonSearchResult matches the daemon's actual response format
-type daemonSearchResult struct {
-	ChunkID   string  `json:"chunk_id"`
-	FilePath  string  `json:"file_path"`
-	Content   string  `json:"content"`
-	Level     string  `json:"level"`
-	Score     float64 `json:"score"`
-	StartLine int     `json:"start_line"`
-	EndLine   int     `json:"end_line"`
-}
-
-// daemonSearchResponse matches the daemon's actual response format
-type daemonSearchResponse struct {
-	Results      []daemonSearchResult `json:"results"`
-	Query        string               `json:"query"`
-	Limit        int                  `json:"limit"`
-	SearchTimeMs int64                `json:"search_time_ms"`
-}
-
 // Client provides methods to communicate with the pommeld daemon
 type Client struct {
 	baseURL    string
@@ -126,32 +107,36 @@ func (c *Client) Search(req api.SearchRequest) (*api.SearchResponse, error) {
 		return nil, fmt.Errorf("search request failed: %s", string(bodyBytes))
 	}
 
-	// Parse daemon's response format
-	var daemonResp daemonSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&daemonResp); err != nil {
+	var searchResp api.SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	return &searchResp, nil
+}
 
-	// Convert to API response format
-	results := make([]api.SearchResult, len(daemonResp.Results))
-	for i, r := range daemonResp.Results {
-		results[i] = api.SearchResult{
-			ID:        r.ChunkID,
-			File:      r.FilePath,
-			StartLine: r.StartLine,
-			EndLine:   r.EndLine,
-			Level:     r.Level,
-			Score:     float32(r.Score),
-			Content:   r.Content,
-		}
-	}
-
-	return &api.SearchResponse{
-		Query:        daemonResp.Query,
-		Results:      results,
-		TotalResults: len(results),
-		SearchTimeMs: daemonResp.SearchTimeMs,
-	}, nil
+// Similar finds chunks similar to an indexed chunk
+func (c *Client) Similar(req api.SimilarRequest) (*api.SimilarResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/similar", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("daemon not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("similar request failed: %s", string(bodyBytes))
+	}
+
+	var similarResp api.SimilarResponse
+	if err := json.NewDecoder(resp.Body).Decode(&similarResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &similarResp, nil
 }
 
 // Reindex triggers a full reindex
@@ -187,6 +172,31 @@ func (c *Client) ReindexPath(path string) (*api.ReindexResponse, error) {
 	return &reindexResp, nil
 }
 
+// RepairFTS checks the keyword index for drift and rebuilds it if needed
+func (c *Client) RepairFTS(force bool) (*api.RepairFTSResponse, error) {
+	reqBody, err := json.Marshal(api.RepairFTSRequest{Force: force})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/index/repair-fts", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("daemon not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("repair-fts request failed: %s", string(body))
+	}
+
+	var repairResp api.RepairFTSResponse
+	if err := json.NewDecoder(resp.Body).Decode(&repairResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &repairResp, nil
+}
+
 // Config retrieves the daemon configuration
 func (c *Client) Config() (*config.Config, error) {
 	resp, err := c.httpClient.Get(c.baseURL + "/config")
@@ -232,10 +232,14 @@ func getEmbeddingValue(cfg *config.Config, parts []string) (interface{}, error)
 		return cfg.Embedding.BatchSize, nil
 	case "cache_size":
 		return cfg.Embedding.CacheSize, nil
+	case "concurrency":
+		return cfg.Embedding.Concurrency, nil
+	case "max_batch_tokens":
+		return cfg.Embedding.MaxBatchTokens, nil
 	default:
 		return nil, NewCLIError(
 			fmt.Sprintf("Unknown embedding config key: %s", parts[1]),
-			"Valid embedding keys are: model, ollama_url, batch_size, cache_size")
+			"Valid embedding keys are: model, ollama_url, batch_size, cache_size, concurrency, max_batch_tokens")
 	}
 }
 
@@ -354,10 +358,22 @@ func setEmbeddingValue(cfg *config.Config, parts []string, value string) error {
 			return invalidIntError("embedding.cache_size", value)
 		}
 		cfg.Embedding.CacheSize = cacheSize
+	case "concurrency":
+		concurrency, err := strconv.Atoi(value)
+		if err != nil {
+			return invalidIntError("embedding.concurrency", value)
+		}
+		cfg.Embedding.Concurrency = concurrency
+	case "max_batch_tokens":
+		maxBatchTokens, err := strconv.Atoi(value)
+		if err != nil {
+			return invalidIntError("embedding.max_batch_tokens", value)
+		}
+		cfg.Embedding.MaxBatchTokens = maxBatchTokens
 	default:
 		return NewCLIError(
 			fmt.Sprintf("Unknown embedding config key: %s", parts[1]),
-			"Valid embedding keys are: model, ollama_url, batch_size, cache_size")
+			"Valid embedding keys are: model, ollama_url, batch_size, cache_size, concurrency, max_batch_tokens")
 	}
 	return nil
 }
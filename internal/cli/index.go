@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var indexRepairFTSForce bool
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Inspect and maintain the search index",
+	Long: `Inspect and maintain the search index.
+
+Examples:
+  pm index repair-fts
+  pm index repair-fts --force`,
+}
+
+var indexRepairFTSCmd = &cobra.Command{
+	Use:   "repair-fts",
+	Short: "Detect and repair drift in the keyword search index",
+	Long: `Detect and repair drift in the keyword search index.
+
+Compares the indexed chunks with the full-text (FTS5) keyword index used by
+hybrid search. If chunks are missing from the keyword index, or it contains
+entries for chunks that no longer exist or have changed, the keyword index is
+rebuilt from the chunk index. Embeddings are not regenerated.
+
+Use --force to rebuild the keyword index even if no drift is detected.
+
+Examples:
+  pm index repair-fts
+  pm index repair-fts --force
+  pm index repair-fts --json`,
+	RunE: runIndexRepairFTS,
+}
+
+func init() {
+	rootCmd.AddCommand(indexCmd)
+	indexCmd.AddCommand(indexRepairFTSCmd)
+	indexRepairFTSCmd.Flags().BoolVarP(&indexRepairFTSForce, "force", "f", false, "Rebuild the keyword index even if no drift is detected")
+}
+
+func runIndexRepairFTS(cmd *cobra.Command, args []string) error {
+	client, err := NewClientFromProjectRoot(GetProjectRoot())
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	resp, err := client.RepairFTS(indexRepairFTSForce)
+	if err != nil {
+		return err
+	}
+
+	if IsJSONOutput() {
+		return JSON(resp)
+	}
+
+	Info("Chunks: %d, keyword index entries: %d", resp.ChunkCount, resp.FTSCount)
+	if resp.Missing > 0 || resp.Orphaned > 0 || resp.Stale > 0 || resp.Duplicates > 0 {
+		Info("Drift: %d missing, %d orphaned, %d stale, %d duplicates",
+			resp.Missing, resp.Orphaned, resp.Stale, resp.Duplicates)
+	}
+	Success("%s", resp.Message)
+	return nil
+}
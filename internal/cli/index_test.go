@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pommel-dev/pommel/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Command Registration Tests
+// =============================================================================
+
+func TestIndexCmd_Registered(t *testing.T) {
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == "index" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "index command should be registered with root")
+}
+
+func TestIndexCmd_RepairFTSSubcommand(t *testing.T) {
+	found := false
+	for _, cmd := range indexCmd.Commands() {
+		if cmd.Name() == "repair-fts" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "repair-fts should be a subcommand of index")
+
+	flag := indexRepairFTSCmd.Flags().Lookup("force")
+	require.NotNil(t, flag, "repair-fts should have --force flag")
+	assert.Equal(t, "f", flag.Shorthand)
+}
+
+// =============================================================================
+// Client Tests
+// =============================================================================
+
+func TestClient_RepairFTS(t *testing.T) {
+	var receivedPath, receivedMethod string
+	var receivedReq api.RepairFTSRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		receivedMethod = r.Method
+		json.NewDecoder(r.Body).Decode(&receivedReq)
+
+		response := api.RepairFTSResponse{
+			Status:     "rebuilt",
+			Message:    "Keyword index rebuilt with 3 entries",
+			ChunkCount: 3,
+			FTSCount:   1,
+			Missing:    2,
+			Entries:    3,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{baseURL: server.URL, httpClient: server.Client()}
+	resp, err := client.RepairFTS(true)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/index/repair-fts", receivedPath)
+	assert.Equal(t, http.MethodPost, receivedMethod)
+	assert.True(t, receivedReq.Force, "force should be sent to the daemon")
+	assert.Equal(t, "rebuilt", resp.Status)
+	assert.Equal(t, 2, resp.Missing)
+	assert.Equal(t, 3, resp.Entries)
+}
+
+func TestClient_RepairFTS_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.WriteInternalError(w, api.ErrFTSRepairFailed)
+	}))
+	defer server.Close()
+
+	client := &Client{baseURL: server.URL, httpClient: server.Client()}
+	_, err := client.RepairFTS(false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FTS_REPAIR_FAILED")
+}
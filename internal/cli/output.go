@@ -87,6 +87,16 @@ func (o *OutputFormatter) Table(headers []string, rows [][]string) {
 	w.Flush()
 }
 
+// ColorEnabled reports whether stdout is a terminal that should get colored
+// output. Setting NO_COLOR turns colors off.
+func ColorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 // DefaultOutput is the default output formatter instance
 var DefaultOutput = NewOutputFormatter()
 
@@ -2,6 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +17,7 @@ import (
 
 var (
 	searchLimit      int
+	searchPage       int
 	searchLevels     []string
 	searchPath       string
 	searchAll        bool
@@ -21,6 +26,22 @@ var (
 	searchNoRerank   bool
 	searchVerbose    bool
 	searchMetrics    bool
+	searchStdin      bool
+	searchFromFile   string
+	searchLanguages  []string
+	searchExtensions []string
+	searchInclude    []string
+	searchExclude    []string
+	searchTests      string
+	searchFusion     string
+	searchRRFK       int
+	searchVectorW    float64
+	searchKeywordW   float64
+	searchDiversity  float64
+	searchMaxPerFile int
+	searchContext    int
+	searchMinScore   float64
+	searchMinRel     float64
 )
 
 var searchCmd = &cobra.Command{
@@ -31,30 +52,86 @@ var searchCmd = &cobra.Command{
 Performs a semantic search against the indexed codebase and returns
 matching code chunks ranked by relevance.
 
+When run from inside a sub-project, results are limited to that sub-project
+unless --all, --path or --subproject is given.
+
+--lang, --ext, --include and --exclude narrow the results further. Include
+and exclude patterns use .gitignore syntax and are relative to the project
+root. --tests include|exclude|only keeps or drops test code, going by each
+language's conventions (such as _test.go, test_*.py or src/test/) and the
+test_patterns in the config.
+
+With --stdin or --from-file the query is a code snippet, such as a block
+of code, a failing test or a stack trace, and the search finds code like it.
+Long snippets are split to fit the embedding model, and the keyword search
+uses the identifiers in the snippet.
+
 Examples:
   pm search "authentication middleware"
   pm search "database connection" --limit 5
+  pm search "database connection" --limit 5 --page 2
   pm search "error handling" --level function,method
-  pm search "config parsing" --path internal/config`,
-	Args: cobra.ExactArgs(1),
+  pm search "config parsing" --path internal/config
+  pm search "invoice totals" --subproject billing
+  pm search "retry policy" --all
+  pm search "token refresh" --lang go,ts --exclude '**/*_test.go' --exclude 'vendor/**'
+  pm search "rate limiter" --tests exclude
+  pm search "parse config" --fusion linear --keyword-weight 0.5 --verbose
+  pm search "error handling" --max-per-file 2 --diversity 0.3
+  pm search "retry policy" --context 3
+  pm search "oauth token rotation" --min-relative-score 0.6
+  pbpaste | pm search --stdin
+  pm search --from-file internal/api/client.go:40-72`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runSearch,
 }
 
 func init() {
 	rootCmd.AddCommand(searchCmd)
 	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 10, "Maximum results")
+	searchCmd.Flags().IntVar(&searchPage, "page", 1, "Page of results to show, --limit results per page")
 	searchCmd.Flags().StringSliceVarP(&searchLevels, "level", "l", nil, "Filter by level (file, class, function, method, block)")
 	searchCmd.Flags().StringVar(&searchPath, "path", "", "Filter by path prefix")
 	searchCmd.Flags().BoolVar(&searchAll, "all", false, "Search entire index (no scope filtering)")
 	searchCmd.Flags().StringVarP(&searchSubproject, "subproject", "s", "", "Filter by sub-project ID")
+	searchCmd.Flags().StringSliceVar(&searchLanguages, "lang", nil, "Filter by language name or extension (e.g., go,ts)")
+	searchCmd.Flags().StringSliceVar(&searchExtensions, "ext", nil, "Filter by file extension (e.g., go,proto)")
+	searchCmd.Flags().StringArrayVar(&searchInclude, "include", nil, "Only search files matching a .gitignore-style pattern (repeatable)")
+	searchCmd.Flags().StringArrayVar(&searchExclude, "exclude", nil, "Skip files matching a .gitignore-style pattern (repeatable)")
+	searchCmd.Flags().StringVar(&searchTests, "tests", "", "Test code in results: include, exclude or only (default include)")
 	searchCmd.Flags().BoolVar(&searchNoHybrid, "no-hybrid", false, "Disable hybrid search (vector only)")
 	searchCmd.Flags().BoolVar(&searchNoRerank, "no-rerank", false, "Disable re-ranking stage")
+	searchCmd.Flags().Float64Var(&searchDiversity, "diversity", 0, "Trade relevance for variety across files, from 0 (off) to 1")
+	searchCmd.Flags().IntVar(&searchMaxPerFile, "max-per-file", 0, "Maximum results from any one file per page (0 = no limit)")
+	searchCmd.Flags().Float64Var(&searchMinScore, "min-score", 0, "Drop results less relevant than this, from 0 to 1 (default from config)")
+	searchCmd.Flags().Float64Var(&searchMinRel, "min-relative-score", 0, "Drop results scoring below this fraction of the top score, e.g. 0.6 (default from config)")
+	searchCmd.Flags().StringVar(&searchFusion, "fusion", "", "How to fuse vector and keyword results: rrf or linear (default from config)")
+	searchCmd.Flags().IntVar(&searchRRFK, "rrf-k", 0, "RRF constant k (default from config)")
+	searchCmd.Flags().Float64Var(&searchVectorW, "vector-weight", 0, "Weight of vector results in fusion (default from config)")
+	searchCmd.Flags().Float64Var(&searchKeywordW, "keyword-weight", 0, "Weight of keyword results in fusion (default from config)")
+	searchCmd.Flags().IntVarP(&searchContext, "context", "C", 0, "Show N lines of surrounding code around each result")
 	searchCmd.Flags().BoolVarP(&searchVerbose, "verbose", "v", false, "Show detailed match reasons and score breakdown")
 	searchCmd.Flags().BoolVar(&searchMetrics, "metrics", false, "Show context savings metrics vs grep baseline")
+	searchCmd.Flags().BoolVar(&searchStdin, "stdin", false, "Search for code like the snippet read from stdin")
+	searchCmd.Flags().StringVar(&searchFromFile, "from-file", "", "Search for code like a file or line range (path[:start-end])")
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
-	query := args[0]
+	query, label, snippet, err := searchQuery(cmd, args)
+	if err != nil {
+		return err
+	}
+	if searchPage < 1 {
+		return fmt.Errorf("--page must be at least 1")
+	}
+	if searchContext < 0 {
+		return fmt.Errorf("--context must not be negative")
+	}
+	switch searchTests {
+	case "", "include", "exclude", "only":
+	default:
+		return fmt.Errorf("--tests must be include, exclude or only")
+	}
 
 	// Check provider is configured before connecting to daemon
 	cfg, err := LoadMergedConfig(GetProjectRoot())
@@ -72,9 +149,36 @@ func runSearch(cmd *cobra.Command, args []string) error {
 
 	req := api.SearchRequest{
 		Query:      query,
+		Snippet:    snippet,
 		Limit:      searchLimit,
+		Page:       searchPage,
 		Levels:     searchLevels,
 		PathPrefix: searchPath,
+		Languages:  searchLanguages,
+		Extensions: searchExtensions,
+		Include:    searchInclude,
+		Exclude:    searchExclude,
+		Tests:      searchTests,
+		Fusion:     searchFusion,
+		RRFK:       searchRRFK,
+		Diversity:  searchDiversity,
+		MaxPerFile: searchMaxPerFile,
+
+		ContextLines: searchContext,
+	}
+
+	// Weights of zero are meaningful, so only send the ones that were given
+	if cmd.Flags().Changed("vector-weight") {
+		req.VectorWeight = &searchVectorW
+	}
+	if cmd.Flags().Changed("keyword-weight") {
+		req.KeywordWeight = &searchKeywordW
+	}
+	if cmd.Flags().Changed("min-score") {
+		req.MinScore = &searchMinScore
+	}
+	if cmd.Flags().Changed("min-relative-score") {
+		req.MinRelativeScore = &searchMinRel
 	}
 
 	// Set hybrid enabled flag if explicitly disabled
@@ -89,17 +193,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		req.RerankEnabled = &rerankEnabled
 	}
 
-	// Wire up scope flags
-	if searchAll {
-		req.Scope = api.SearchScopeRequest{
-			Mode: "all",
-		}
-	} else if searchSubproject != "" {
-		req.Scope = api.SearchScopeRequest{
-			Mode:  "subproject",
-			Value: searchSubproject,
-		}
-	}
+	req.Scope = searchScope(searchAll, searchPath, searchSubproject)
 
 	resp, err := client.Search(req)
 	if err != nil {
@@ -111,19 +205,145 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	}
 
 	// Human-readable output
+	if resp.Scope != nil && resp.Scope.Subproject != nil && req.Scope.Mode == "auto" {
+		Info("Searching sub-project %s (use --all to search everything)", *resp.Scope.Subproject)
+	}
 	if len(resp.Results) == 0 {
-		Info("No results found for: %s", query)
+		Info("No results found for: %s", label)
 		return nil
 	}
+	if resp.Confidence == "low" {
+		Warn("No strong match for: %s (results may be unrelated; try different words or grep)", label)
+	}
 
 	// Use verbose formatter if requested
 	if searchVerbose {
-		return formatVerboseOutput(resp, query)
+		if err := formatVerboseOutput(resp, label); err != nil {
+			return err
+		}
+		printNextPageHint(resp)
+		return nil
 	}
 
-	Info("Found %d results for: %s (%.0fms)\n", resp.TotalResults, resp.Query, float64(resp.SearchTimeMs))
+	Info("Found %d results for: %s (%.0fms)\n", resp.TotalResults, label, float64(resp.SearchTimeMs))
 
-	for i, result := range resp.Results {
+	printSearchResults(resp.Results)
+
+	// Show metrics if requested
+	if searchMetrics {
+		showSearchMetrics(resp)
+	}
+
+	printNextPageHint(resp)
+
+	return nil
+}
+
+// printNextPageHint tells the user how to fetch the next page, if there is one.
+func printNextPageHint(resp *api.SearchResponse) {
+	if resp.NextCursor == "" {
+		return
+	}
+	Info("More results available: add --page %d", searchPage+1)
+}
+
+// searchQuery returns the query text from the positional argument or the
+// snippet flags, along with a label to show it by and whether it is a snippet.
+func searchQuery(cmd *cobra.Command, args []string) (query, label string, snippet bool, err error) {
+	sources := len(args)
+	if searchStdin {
+		sources++
+	}
+	if searchFromFile != "" {
+		sources++
+	}
+	if sources != 1 {
+		return "", "", false, fmt.Errorf("provide exactly one of a query, --stdin or --from-file")
+	}
+
+	switch {
+	case searchStdin:
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", "", false, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		query, label = string(data), "snippet from stdin"
+	case searchFromFile != "":
+		query, err = readSnippetFile(searchFromFile)
+		if err != nil {
+			return "", "", false, err
+		}
+		label = searchFromFile
+	default:
+		return args[0], args[0], false, nil
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return "", "", false, fmt.Errorf("snippet is empty: %s", label)
+	}
+	return query, label, true, nil
+}
+
+// lineRangeRegex matches a trailing ":start-end" or ":line" on a file argument
+var lineRangeRegex = regexp.MustCompile(`^(.+):(\d+)(?:-(\d+))?$`)
+
+// readSnippetFile reads a file, or a line range of one given as path:start-end.
+func readSnippetFile(arg string) (string, error) {
+	path, start, end := arg, 0, 0
+	if m := lineRangeRegex.FindStringSubmatch(arg); m != nil {
+		path = m[1]
+		start, _ = strconv.Atoi(m[2])
+		end = start
+		if m[3] != "" {
+			end, _ = strconv.Atoi(m[3])
+		}
+		if start <= 0 || end < start {
+			return "", fmt.Errorf("invalid line range in %q", arg)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if start == 0 {
+		return string(data), nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if start > len(lines) {
+		return "", fmt.Errorf("line %d is past the end of %s (%d lines)", start, path, len(lines))
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(lines[start-1:end], "\n"), nil
+}
+
+// searchScope builds the request scope from the scope flags. Without one the
+// daemon scopes to the sub-project containing the working directory.
+func searchScope(all bool, path, subproject string) api.SearchScopeRequest {
+	switch {
+	case all:
+		return api.SearchScopeRequest{Mode: "all"}
+	case path != "":
+		return api.SearchScopeRequest{Mode: "path", Value: path}
+	case subproject != "":
+		return api.SearchScopeRequest{Mode: "subproject", Value: subproject}
+	}
+	if wd, err := os.Getwd(); err == nil {
+		return api.SearchScopeRequest{Mode: "auto", Value: wd}
+	}
+	return api.SearchScopeRequest{}
+}
+
+// printSearchResults prints results with their best matching lines, or a
+// short content preview if no query term appears in them
+func printSearchResults(results []api.SearchResult) {
+	formatter := output.NewFormatter(output.FormatNormal)
+	formatter.ShowColors = ColorEnabled()
+
+	for i, result := range results {
 		// Format: #1 [score] file:lines - name (level)
 		fmt.Printf("\n#%d [%.3f] %s:%d-%d\n", i+1, result.Score, result.File, result.StartLine, result.EndLine)
 		if result.Name != "" {
@@ -132,28 +352,44 @@ func runSearch(cmd *cobra.Command, args []string) error {
 			fmt.Printf("   (%s)\n", result.Level)
 		}
 
-		// Show truncated content preview
-		content := strings.TrimSpace(result.Content)
-		lines := strings.Split(content, "\n")
-		maxLines := 5
-		if len(lines) > maxLines {
-			for _, line := range lines[:maxLines] {
-				fmt.Printf("   | %s\n", line)
-			}
-			fmt.Printf("   | ... (%d more lines)\n", len(lines)-maxLines)
+		// Surrounding lines are marked with ':' like grep's context lines
+		if result.Context != nil {
+			printContextLines(result.Context.Before)
+		}
+
+		if result.Snippet != nil {
+			fmt.Print(formatter.FormatSnippet(&result, "   "))
 		} else {
-			for _, line := range lines {
-				fmt.Printf("   | %s\n", line)
+			// Show truncated content preview
+			content := strings.TrimSpace(result.Content)
+			lines := strings.Split(content, "\n")
+			maxLines := 5
+			if len(lines) > maxLines {
+				for _, line := range lines[:maxLines] {
+					fmt.Printf("   | %s\n", line)
+				}
+				fmt.Printf("   | ... (%d more lines)\n", len(lines)-maxLines)
+			} else {
+				for _, line := range lines {
+					fmt.Printf("   | %s\n", line)
+				}
 			}
 		}
-	}
 
-	// Show metrics if requested
-	if searchMetrics {
-		showSearchMetrics(resp)
+		if result.Context != nil {
+			printContextLines(result.Context.After)
+		}
 	}
+}
 
-	return nil
+// printContextLines prints lines of surrounding code, if any.
+func printContextLines(lines *api.SourceLines) {
+	if lines == nil {
+		return
+	}
+	for _, line := range strings.Split(lines.Content, "\n") {
+		fmt.Printf("   : %s\n", line)
+	}
 }
 
 // showSearchMetrics displays context savings metrics
@@ -181,6 +417,7 @@ func showSearchMetrics(resp *api.SearchResponse) {
 func formatVerboseOutput(resp *api.SearchResponse, query string) error {
 	formatter := output.NewFormatter(output.FormatVerbose)
 	formatter.Query = query
+	formatter.ShowColors = ColorEnabled()
 
 	// Print summary
 	fmt.Println(formatter.FormatSummary(resp))
@@ -194,6 +431,12 @@ func formatVerboseOutput(resp *api.SearchResponse, query string) error {
 
 		fmt.Print(formatter.FormatResult(&result, i))
 
+		if result.Snippet != nil {
+			fmt.Print(formatter.FormatSnippet(&result, "    "))
+			fmt.Println()
+			continue
+		}
+
 		// Show content preview
 		content := strings.TrimSpace(result.Content)
 		lines := strings.Split(content, "\n")
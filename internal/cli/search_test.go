@@ -7,21 +7,24 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/pommel-dev/pommel/internal/api"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestSearchCmd_RequiresQuery(t *testing.T) {
-	// Test that search command requires exactly one argument (the query)
+	// Test that search command requires a query argument or snippet flag
 	// Use rootCmd.SetArgs to properly invoke the subcommand
 	rootCmd.SetArgs([]string{"search"})
 
 	err := rootCmd.Execute()
 	require.Error(t, err, "should require query argument")
-	assert.Contains(t, err.Error(), "accepts 1 arg")
+	assert.Contains(t, err.Error(), "provide exactly one of a query, --stdin or --from-file")
 }
 
 func TestSearchCmd_SendsRequest(t *testing.T) {
@@ -844,3 +847,130 @@ func TestSearchCmd_MetricsFlagDescription(t *testing.T) {
 	require.NotNil(t, flag)
 	assert.Contains(t, flag.Usage, "savings", "description should mention context savings")
 }
+
+// =============================================================================
+// Snippet Query Tests
+// =============================================================================
+
+func resetSnippetFlags(t *testing.T) {
+	t.Helper()
+	searchStdin, searchFromFile = false, ""
+	t.Cleanup(func() { searchStdin, searchFromFile = false, "" })
+}
+
+func TestSearchCmd_FilterFlagsRegistered(t *testing.T) {
+	for name, typ := range map[string]string{
+		"lang":    "stringSlice",
+		"ext":     "stringSlice",
+		"include": "stringArray",
+		"exclude": "stringArray",
+		"tests":   "string",
+	} {
+		flag := searchCmd.Flags().Lookup(name)
+		require.NotNil(t, flag, "search should have --%s flag", name)
+		// Patterns may contain commas, so they are not split like lists
+		assert.Equal(t, typ, flag.Value.Type(), "--%s", name)
+	}
+}
+
+func TestSearchCmd_RankingFlagsRegistered(t *testing.T) {
+	for name, typ := range map[string]string{
+		"diversity":          "float64",
+		"max-per-file":       "int",
+		"min-score":          "float64",
+		"min-relative-score": "float64",
+		"fusion":             "string",
+		"rrf-k":              "int",
+		"vector-weight":      "float64",
+		"keyword-weight":     "float64",
+	} {
+		flag := searchCmd.Flags().Lookup(name)
+		require.NotNil(t, flag, "search should have --%s flag", name)
+		assert.Equal(t, typ, flag.Value.Type(), "--%s", name)
+	}
+}
+
+func TestSearchCmd_PageFlagRegistered(t *testing.T) {
+	flag := searchCmd.Flags().Lookup("page")
+	require.NotNil(t, flag, "search should have --page flag")
+	assert.Equal(t, "int", flag.Value.Type())
+	assert.Equal(t, "1", flag.DefValue)
+}
+
+func TestSearchCmd_ContextFlagRegistered(t *testing.T) {
+	flag := searchCmd.Flags().Lookup("context")
+	require.NotNil(t, flag, "search should have --context flag")
+	assert.Equal(t, "int", flag.Value.Type())
+	assert.Equal(t, "C", flag.Shorthand)
+	assert.Equal(t, "0", flag.DefValue)
+}
+
+func TestSearchCmd_SnippetFlagsRegistered(t *testing.T) {
+	assert.NotNil(t, searchCmd.Flags().Lookup("stdin"), "search should have --stdin flag")
+	assert.NotNil(t, searchCmd.Flags().Lookup("from-file"), "search should have --from-file flag")
+}
+
+func TestSearchQuery_PositionalArgument(t *testing.T) {
+	resetSnippetFlags(t)
+
+	query, label, snippet, err := searchQuery(searchCmd, []string{"retry policy"})
+	require.NoError(t, err)
+	assert.Equal(t, "retry policy", query)
+	assert.Equal(t, "retry policy", label)
+	assert.False(t, snippet)
+}
+
+func TestSearchQuery_Stdin(t *testing.T) {
+	resetSnippetFlags(t)
+	searchStdin = true
+
+	cmd := &cobra.Command{}
+	cmd.SetIn(bytes.NewBufferString("panic: nil map\nmain.parseHeader(...)\n"))
+
+	query, label, snippet, err := searchQuery(cmd, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "panic: nil map\nmain.parseHeader(...)\n", query)
+	assert.Equal(t, "snippet from stdin", label)
+	assert.True(t, snippet)
+
+	cmd.SetIn(bytes.NewBufferString("  \n"))
+	_, _, _, err = searchQuery(cmd, nil)
+	assert.ErrorContains(t, err, "snippet is empty")
+}
+
+func TestSearchQuery_RequiresExactlyOneSource(t *testing.T) {
+	resetSnippetFlags(t)
+
+	searchStdin = true
+	_, _, _, err := searchQuery(searchCmd, []string{"retry policy"})
+	assert.Error(t, err)
+
+	searchFromFile = "main.go"
+	_, _, _, err = searchQuery(searchCmd, nil)
+	assert.Error(t, err)
+}
+
+func TestReadSnippetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "retry.go")
+	require.NoError(t, os.WriteFile(path, []byte("line1\nline2\nline3\nline4\n"), 0644))
+
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{path, "line1\nline2\nline3\nline4\n"},
+		{path + ":2-3", "line2\nline3"},
+		{path + ":2", "line2"},
+		{path + ":3-100", "line3\nline4\n"},
+	}
+	for _, tt := range tests {
+		got, err := readSnippetFile(tt.arg)
+		require.NoError(t, err, tt.arg)
+		assert.Equal(t, tt.want, got, tt.arg)
+	}
+
+	for _, arg := range []string{path + ":0-2", path + ":3-2", path + ":100", path + ".missing"} {
+		_, err := readSnippetFile(arg)
+		assert.Error(t, err, arg)
+	}
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pommel-dev/pommel/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	similarLimit      int
+	similarLevels     []string
+	similarPath       string
+	similarAll        bool
+	similarSubproject string
+	similarLanguages  []string
+	similarExtensions []string
+	similarInclude    []string
+	similarExclude    []string
+	similarTests      string
+)
+
+var similarCmd = &cobra.Command{
+	Use:   "similar <chunk-id | file:line>",
+	Short: "Find code similar to an indexed chunk",
+	Long: `Find code similar to an existing chunk.
+
+The source is a chunk ID from a search result, or a file and line number,
+in which case the innermost chunk covering that line is used. Its stored
+embedding is compared against the index, so nothing is re-embedded. The
+source and the other parts of a split chunk are left out of the results.
+
+Scope and filter flags work as they do for 'pm search'.
+
+Examples:
+  pm similar internal/daemon/retry.go:71
+  pm similar 3f9a1c0e... --limit 5
+  pm similar api/client.go:120 --level method --all`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSimilar,
+}
+
+func init() {
+	rootCmd.AddCommand(similarCmd)
+	similarCmd.Flags().IntVarP(&similarLimit, "limit", "n", 10, "Maximum results")
+	similarCmd.Flags().StringSliceVarP(&similarLevels, "level", "l", nil, "Filter by level (file, class, function, method, block)")
+	similarCmd.Flags().StringVar(&similarPath, "path", "", "Filter by path prefix")
+	similarCmd.Flags().BoolVar(&similarAll, "all", false, "Search entire index (no scope filtering)")
+	similarCmd.Flags().StringVarP(&similarSubproject, "subproject", "s", "", "Filter by sub-project ID")
+	similarCmd.Flags().StringSliceVar(&similarLanguages, "lang", nil, "Filter by language name or extension (e.g., go,ts)")
+	similarCmd.Flags().StringSliceVar(&similarExtensions, "ext", nil, "Filter by file extension (e.g., go,proto)")
+	similarCmd.Flags().StringArrayVar(&similarInclude, "include", nil, "Only search files matching a .gitignore-style pattern (repeatable)")
+	similarCmd.Flags().StringArrayVar(&similarExclude, "exclude", nil, "Skip files matching a .gitignore-style pattern (repeatable)")
+	similarCmd.Flags().StringVar(&similarTests, "tests", "", "Test code in results: include, exclude or only (default include)")
+}
+
+func runSimilar(cmd *cobra.Command, args []string) error {
+	switch similarTests {
+	case "", "include", "exclude", "only":
+	default:
+		return fmt.Errorf("--tests must be include, exclude or only")
+	}
+
+	req, err := similarSource(args[0])
+	if err != nil {
+		return err
+	}
+	req.Limit = similarLimit
+	req.Levels = similarLevels
+	req.PathPrefix = similarPath
+	req.Languages = similarLanguages
+	req.Extensions = similarExtensions
+	req.Include = similarInclude
+	req.Exclude = similarExclude
+	req.Tests = similarTests
+	req.Scope = searchScope(similarAll, similarPath, similarSubproject)
+
+	client, err := NewClientFromProjectRoot(GetProjectRoot())
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	resp, err := client.Similar(req)
+	if err != nil {
+		return err
+	}
+
+	if IsJSONOutput() {
+		return JSON(resp)
+	}
+
+	source := fmt.Sprintf("%s:%d-%d", resp.Source.File, resp.Source.StartLine, resp.Source.EndLine)
+	if resp.Source.Name != "" {
+		source += " (" + resp.Source.Name + ")"
+	}
+	if len(resp.Results) == 0 {
+		Info("No similar code found for %s", source)
+		return nil
+	}
+
+	Info("Found %d results similar to %s (%.0fms)\n", resp.TotalResults, source, float64(resp.SearchTimeMs))
+	printSearchResults(resp.Results)
+	return nil
+}
+
+// similarSource parses a "file:line" location or a chunk ID into a request.
+// Relative file paths are resolved against the working directory.
+func similarSource(arg string) (api.SimilarRequest, error) {
+	if i := strings.LastIndex(arg, ":"); i > 0 {
+		if line, err := strconv.Atoi(arg[i+1:]); err == nil {
+			if line <= 0 {
+				return api.SimilarRequest{}, fmt.Errorf("invalid line number %d in %q", line, arg)
+			}
+			file, err := filepath.Abs(arg[:i])
+			if err != nil {
+				return api.SimilarRequest{}, fmt.Errorf("failed to resolve %s: %w", arg[:i], err)
+			}
+			return api.SimilarRequest{File: file, Line: line}, nil
+		}
+	}
+	return api.SimilarRequest{ChunkID: arg}, nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pommel-dev/pommel/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarCmd_Registered(t *testing.T) {
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == "similar" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "similar command should be registered with root")
+}
+
+func TestSimilarCmd_FlagsExist(t *testing.T) {
+	for _, name := range []string{"limit", "level", "path", "all", "subproject", "lang", "ext", "include", "exclude", "tests"} {
+		assert.NotNil(t, similarCmd.Flags().Lookup(name), "similar should have --%s flag", name)
+	}
+}
+
+func TestSimilarSource(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	tests := []struct {
+		arg  string
+		want api.SimilarRequest
+	}{
+		{"internal/api/client.go:42", api.SimilarRequest{File: filepath.Join(wd, "internal/api/client.go"), Line: 42}},
+		{"/project/main.go:7", api.SimilarRequest{File: "/project/main.go", Line: 7}},
+		{"3f9a1c0e", api.SimilarRequest{ChunkID: "3f9a1c0e"}},
+		{"weird:name", api.SimilarRequest{ChunkID: "weird:name"}},
+	}
+	for _, tt := range tests {
+		got, err := similarSource(tt.arg)
+		require.NoError(t, err, tt.arg)
+		assert.Equal(t, tt.want, got, tt.arg)
+	}
+
+	_, err = similarSource("main.go:0")
+	assert.Error(t, err)
+}
+
+func TestClientSimilar(t *testing.T) {
+	var received api.SimilarRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/similar", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		json.NewEncoder(w).Encode(api.SimilarResponse{
+			Source:       api.SearchResult{ID: "source", File: "/project/retry.go"},
+			Results:      []api.SearchResult{{ID: "other", File: "/project/backoff.go", Score: 0.9}},
+			TotalResults: 1,
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{baseURL: server.URL, httpClient: server.Client()}
+	resp, err := client.Similar(api.SimilarRequest{File: "/project/retry.go", Line: 3, Limit: 5})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/project/retry.go", received.File)
+	assert.Equal(t, 3, received.Line)
+	assert.Equal(t, 5, received.Limit)
+	assert.Equal(t, "source", resp.Source.ID)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "other", resp.Results[0].ID)
+}
+
+func TestClientSimilar_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.WriteNotFound(w, api.ErrSourceChunkNotFound)
+	}))
+	defer server.Close()
+
+	client := &Client{baseURL: server.URL, httpClient: server.Client()}
+	_, err := client.Similar(api.SimilarRequest{ChunkID: "missing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SOURCE_CHUNK_NOT_FOUND")
+}
@@ -64,10 +64,18 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	if status.Index != nil {
 		fmt.Printf("  Files:    %d\n", status.Index.TotalFiles)
 		fmt.Printf("  Chunks:   %d\n", status.Index.TotalChunks)
+		if status.Index.ChunksEmbedded > 0 || status.Index.ChunksReused > 0 {
+			fmt.Printf("  Embeddings: %d embedded, %d reused since start\n", status.Index.ChunksEmbedded, status.Index.ChunksReused)
+		}
+		if status.Index.FailedFiles > 0 {
+			fmt.Printf("  Failed:   %d files waiting to be retried\n", status.Index.FailedFiles)
+		}
 		if !status.Index.LastIndexedAt.IsZero() {
 			fmt.Printf("  Last indexed: %s\n", status.Index.LastIndexedAt.Format(time.RFC3339))
 		}
-		if status.Index.IndexingActive {
+		if status.Index.Reconciling {
+			fmt.Printf("  Status:   Reconciling %d changes since last run...\n", status.Index.PendingChanges)
+		} else if status.Index.IndexingActive {
 			if status.Index.Progress != nil {
 				// Show detailed progress
 				fmt.Printf("  Status:   Indexing... %.1f%% complete\n", status.Index.Progress.PercentComplete)
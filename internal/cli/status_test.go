@@ -108,6 +108,35 @@ func TestStatusCmd_JSONOutput(t *testing.T) {
 	assert.True(t, response.Index.IndexingActive)
 }
 
+func TestStatusCmd_ShowsReconciliation(t *testing.T) {
+	// Test that startup reconciliation details survive the round trip
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := api.StatusResponse{
+			Daemon: &api.DaemonStatus{Running: true, PID: 4242},
+			Index: &api.IndexStatus{
+				TotalFiles:     40,
+				TotalChunks:    400,
+				IndexingActive: true,
+				PendingChanges: 7,
+				Reconciling:    true,
+				Message:        "reconciling 7 changes",
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	output, err := executeStatusWithOutput(server.URL, true)
+	require.NoError(t, err)
+
+	var response api.StatusResponse
+	require.NoError(t, json.Unmarshal([]byte(output), &response))
+	assert.True(t, response.Index.Reconciling)
+	assert.Equal(t, 7, response.Index.PendingChanges)
+	assert.Equal(t, "reconciling 7 changes", response.Index.Message)
+}
+
 func TestStatusCmd_CommandRegistered(t *testing.T) {
 	// Verify status command is registered with root
 	found := false
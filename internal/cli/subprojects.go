@@ -12,7 +12,8 @@ var subprojectsCmd = &cobra.Command{
 	Short:   "List detected sub-projects",
 	Long: `List all detected sub-projects in the current monorepo.
 
-Shows each sub-project's ID, path, marker file, and primary language.
+Shows each sub-project's ID, path, marker file, primary language, and the
+number of indexed files and chunks.
 
 Examples:
   pm subprojects
@@ -53,7 +54,7 @@ func runSubprojects(cmd *cobra.Command, args []string) error {
 		if lang == "" {
 			lang = "unknown"
 		}
-		fmt.Printf("  %-15s %-30s %s (%s)\n", sp.ID, sp.Path, sp.MarkerFile, lang)
+		fmt.Printf("  %-15s %-30s %s (%s), %d files, %d chunks\n", sp.ID, sp.Path, sp.MarkerFile, lang, sp.Files, sp.Chunks)
 	}
 
 	return nil
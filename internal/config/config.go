@@ -12,6 +12,7 @@ type Config struct {
 	ChunkLevels     []string          `yaml:"chunk_levels" json:"chunk_levels" mapstructure:"chunk_levels"`
 	IncludePatterns []string          `yaml:"include_patterns" json:"include_patterns" mapstructure:"include_patterns"`
 	ExcludePatterns []string          `yaml:"exclude_patterns" json:"exclude_patterns" mapstructure:"exclude_patterns"`
+	TestPatterns    []string          `yaml:"test_patterns" json:"test_patterns,omitempty" mapstructure:"test_patterns"` // Files to flag as tests, on top of each language's conventions
 	Watcher         WatcherConfig     `yaml:"watcher" json:"watcher" mapstructure:"watcher"`
 	Daemon          DaemonConfig      `yaml:"daemon" json:"daemon" mapstructure:"daemon"`
 	Embedding       EmbeddingConfig   `yaml:"embedding" json:"embedding" mapstructure:"embedding"`
@@ -41,6 +42,10 @@ type EmbeddingConfig struct {
 	BatchSize int    `yaml:"batch_size" json:"batch_size" mapstructure:"batch_size"`
 	CacheSize int    `yaml:"cache_size" json:"cache_size" mapstructure:"cache_size"`
 
+	// Indexing throughput settings
+	Concurrency    int `yaml:"concurrency" json:"concurrency" mapstructure:"concurrency"`                // Embedding requests in flight at once
+	MaxBatchTokens int `yaml:"max_batch_tokens" json:"max_batch_tokens" mapstructure:"max_batch_tokens"` // Estimated token limit per batch (0 = no limit)
+
 	// Provider-specific configurations
 	Ollama OllamaProviderConfig `yaml:"ollama" json:"ollama" mapstructure:"ollama"`
 	OpenAI OpenAIProviderConfig `yaml:"openai" json:"openai" mapstructure:"openai"`
@@ -113,10 +118,12 @@ func (e *EmbeddingConfig) DefaultDimensions() int {
 
 // SearchConfig contains search default settings
 type SearchConfig struct {
-	DefaultLimit  int                `yaml:"default_limit" json:"default_limit" mapstructure:"default_limit"`
-	DefaultLevels []string           `yaml:"default_levels" json:"default_levels" mapstructure:"default_levels"`
-	Hybrid        HybridSearchConfig `yaml:"hybrid" json:"hybrid" mapstructure:"hybrid"`
-	Reranker      RerankerConfig     `yaml:"reranker" json:"reranker" mapstructure:"reranker"`
+	DefaultLimit     int                `yaml:"default_limit" json:"default_limit" mapstructure:"default_limit"`
+	DefaultLevels    []string           `yaml:"default_levels" json:"default_levels" mapstructure:"default_levels"`
+	MinScore         float64            `yaml:"min_score" json:"min_score" mapstructure:"min_score"`                            // Drop results less relevant than this (0 = off)
+	MinRelativeScore float64            `yaml:"min_relative_score" json:"min_relative_score" mapstructure:"min_relative_score"` // Drop results below this fraction of the top score (0 = off)
+	Hybrid           HybridSearchConfig `yaml:"hybrid" json:"hybrid" mapstructure:"hybrid"`
+	Reranker         RerankerConfig     `yaml:"reranker" json:"reranker" mapstructure:"reranker"`
 }
 
 // HybridSearchConfig contains hybrid search settings
@@ -125,6 +132,7 @@ type HybridSearchConfig struct {
 	RRFK          int     `yaml:"rrf_k" json:"rrf_k" mapstructure:"rrf_k"`
 	VectorWeight  float64 `yaml:"vector_weight" json:"vector_weight" mapstructure:"vector_weight"`
 	KeywordWeight float64 `yaml:"keyword_weight" json:"keyword_weight" mapstructure:"keyword_weight"`
+	Fusion        string  `yaml:"fusion" json:"fusion" mapstructure:"fusion"` // "rrf" or "linear"
 }
 
 // DefaultHybridSearchConfig returns the default hybrid search configuration
@@ -134,16 +142,45 @@ func DefaultHybridSearchConfig() HybridSearchConfig {
 		RRFK:          60,
 		VectorWeight:  0.7,
 		KeywordWeight: 0.3,
+		Fusion:        "rrf",
 	}
 }
 
 // RerankerConfig contains re-ranker settings
 type RerankerConfig struct {
-	Enabled    bool   `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
-	Model      string `yaml:"model" json:"model,omitempty" mapstructure:"model"`
-	TimeoutMs  int    `yaml:"timeout_ms" json:"timeout_ms" mapstructure:"timeout_ms"`
-	Fallback   string `yaml:"fallback" json:"fallback" mapstructure:"fallback"`
-	Candidates int    `yaml:"candidates" json:"candidates" mapstructure:"candidates"`
+	Enabled    bool          `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
+	Provider   string        `yaml:"provider" json:"provider,omitempty" mapstructure:"provider"` // heuristic, cross-encoder or llm (empty = cross-encoder if a model is set)
+	Model      string        `yaml:"model" json:"model,omitempty" mapstructure:"model"`
+	URL        string        `yaml:"url" json:"url,omitempty" mapstructure:"url"`                   // Rerank or chat completions endpoint (empty = provider default)
+	Prompt     string        `yaml:"prompt" json:"prompt,omitempty" mapstructure:"prompt"`          // Prompt template for the llm provider (empty = built-in)
+	MaxLines   int           `yaml:"max_lines" json:"max_lines,omitempty" mapstructure:"max_lines"` // Content lines per candidate for the llm provider (0 = 20)
+	TimeoutMs  int           `yaml:"timeout_ms" json:"timeout_ms" mapstructure:"timeout_ms"`
+	Fallback   string        `yaml:"fallback" json:"fallback" mapstructure:"fallback"`
+	Candidates int           `yaml:"candidates" json:"candidates" mapstructure:"candidates"`
+	BatchSize  int           `yaml:"batch_size" json:"batch_size" mapstructure:"batch_size"` // Candidates scored per request
+	Signals    SignalsConfig `yaml:"signals" json:"signals" mapstructure:"signals"`
+}
+
+// SignalsConfig tunes the heuristic reranker's signals. Nil weights and empty
+// pattern lists keep the built-in defaults; a weight of 0 turns a signal off.
+type SignalsConfig struct {
+	NameMatch    *float64          `yaml:"name_match,omitempty" json:"name_match,omitempty" mapstructure:"name_match"`
+	ExactPhrase  *float64          `yaml:"exact_phrase,omitempty" json:"exact_phrase,omitempty" mapstructure:"exact_phrase"`
+	PathMatch    *float64          `yaml:"path_match,omitempty" json:"path_match,omitempty" mapstructure:"path_match"`
+	TestPenalty  *float64          `yaml:"test_penalty,omitempty" json:"test_penalty,omitempty" mapstructure:"test_penalty"`
+	MockPenalty  *float64          `yaml:"mock_penalty,omitempty" json:"mock_penalty,omitempty" mapstructure:"mock_penalty"`
+	Recency      *float64          `yaml:"recency,omitempty" json:"recency,omitempty" mapstructure:"recency"`
+	ChunkType    *float64          `yaml:"chunk_type,omitempty" json:"chunk_type,omitempty" mapstructure:"chunk_type"`
+	TestPatterns []string          `yaml:"test_patterns,omitempty" json:"test_patterns,omitempty" mapstructure:"test_patterns"` // Replace the default test file patterns; files flagged as tests at index time are penalized either way
+	MockPatterns []string          `yaml:"mock_patterns,omitempty" json:"mock_patterns,omitempty" mapstructure:"mock_patterns"` // Replace the default mock file patterns
+	PathBoosts   []PathBoostConfig `yaml:"path_boosts,omitempty" json:"path_boosts,omitempty" mapstructure:"path_boosts"`
+}
+
+// PathBoostConfig adds Boost to the reranker score of results in files
+// matching a gitignore-style Pattern. Negative boosts demote.
+type PathBoostConfig struct {
+	Pattern string  `yaml:"pattern" json:"pattern" mapstructure:"pattern"`
+	Boost   float64 `yaml:"boost" json:"boost" mapstructure:"boost"`
 }
 
 // DefaultRerankerConfig returns the default re-ranker configuration
@@ -154,7 +191,26 @@ func DefaultRerankerConfig() RerankerConfig {
 		TimeoutMs:  2000, // 2 seconds
 		Fallback:   "heuristic",
 		Candidates: 20,
+		BatchSize:  16,
+	}
+}
+
+// UsesModel reports whether a model-based reranker is configured, as opposed
+// to the heuristic reranker alone.
+func (r *RerankerConfig) UsesModel() bool {
+	return r.GetProvider() != "heuristic"
+}
+
+// GetProvider returns the reranker provider. Without an explicit provider, a
+// model other than "heuristic" means a cross-encoder.
+func (r *RerankerConfig) GetProvider() string {
+	if r.Provider != "" {
+		return r.Provider
+	}
+	if r.Model != "" && r.Model != "heuristic" {
+		return "cross-encoder"
 	}
+	return "heuristic"
 }
 
 // SubprojectsConfig contains sub-project detection settings
@@ -328,6 +328,8 @@ include_patterns:
   - "**/*.go"
 exclude_patterns:
   - "**/vendor/**"
+test_patterns:
+  - "testdata/"
 watcher:
   debounce_ms: 1000
   max_file_size: 2097152
@@ -355,6 +357,7 @@ search:
 	assert.Equal(t, []string{"file", "method"}, cfg.ChunkLevels)
 	assert.Equal(t, []string{"**/*.go"}, cfg.IncludePatterns)
 	assert.Equal(t, []string{"**/vendor/**"}, cfg.ExcludePatterns)
+	assert.Equal(t, []string{"testdata/"}, cfg.TestPatterns)
 	assert.Equal(t, 1000, cfg.Watcher.DebounceMs)
 	assert.Equal(t, int64(2097152), cfg.Watcher.MaxFileSize)
 	assert.Equal(t, "0.0.0.0", cfg.Daemon.Host)
@@ -622,6 +625,38 @@ func TestValidate_InvalidEmbedding(t *testing.T) {
 		}
 		assert.True(t, found)
 	})
+
+	t.Run("negative concurrency", func(t *testing.T) {
+		cfg := Default()
+		cfg.Embedding.Concurrency = -1
+
+		errors := Validate(cfg)
+		assert.True(t, errors.HasErrors())
+		found := false
+		for _, e := range errors {
+			if e.Field == "embedding.concurrency" {
+				found = true
+				break
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("negative max batch tokens", func(t *testing.T) {
+		cfg := Default()
+		cfg.Embedding.MaxBatchTokens = -1
+
+		errors := Validate(cfg)
+		assert.True(t, errors.HasErrors())
+		found := false
+		for _, e := range errors {
+			if e.Field == "embedding.max_batch_tokens" {
+				found = true
+				break
+			}
+		}
+		assert.True(t, found)
+	})
 }
 
 func TestValidate_InvalidSearch(t *testing.T) {
@@ -658,6 +693,86 @@ func TestValidate_InvalidSearch(t *testing.T) {
 	})
 }
 
+func TestValidate_InvalidHybrid(t *testing.T) {
+	cfg := Default()
+	cfg.Search.Hybrid.Fusion = "average"
+	cfg.Search.Hybrid.KeywordWeight = -0.5
+
+	fields := make([]string, 0)
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{"search.hybrid.fusion", "search.hybrid.keyword_weight"}, fields)
+
+	cfg.Search.Hybrid.Fusion = "linear"
+	cfg.Search.Hybrid.KeywordWeight = 0.3
+	assert.False(t, Validate(cfg).HasErrors())
+}
+
+func TestValidate_ScoreCutoffs(t *testing.T) {
+	cfg := Default()
+	cfg.Search.MinScore = -0.5
+	cfg.Search.MinRelativeScore = 1.5
+
+	fields := make([]string, 0)
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{"search.min_score", "search.min_relative_score"}, fields)
+
+	cfg.Search.MinScore = 0.3
+	cfg.Search.MinRelativeScore = 0.6
+	assert.False(t, Validate(cfg).HasErrors())
+}
+
+func TestValidate_Reranker(t *testing.T) {
+	cfg := Default()
+	cfg.Search.Reranker.Fallback = "retry"
+	cfg.Search.Reranker.TimeoutMs = -1
+	cfg.Search.Reranker.BatchSize = -1
+	cfg.Search.Reranker.MaxLines = -1
+	cfg.Search.Reranker.Provider = "gpt"
+	cfg.Search.Reranker.Prompt = "{{.Query"
+
+	fields := make([]string, 0)
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{"search.reranker.fallback", "search.reranker.timeout_ms", "search.reranker.batch_size", "search.reranker.max_lines", "search.reranker.provider", "search.reranker.prompt"}, fields)
+
+	cfg.Search.Reranker.Fallback = "none"
+	cfg.Search.Reranker.TimeoutMs = 500
+	cfg.Search.Reranker.BatchSize = 8
+	cfg.Search.Reranker.MaxLines = 40
+	cfg.Search.Reranker.Provider = "llm"
+	cfg.Search.Reranker.Prompt = "Grade for {{.Query}}: {{.Candidates}}"
+	assert.False(t, Validate(cfg).HasErrors())
+	cfg.Search.Reranker.Provider = "cross-encoder"
+	fields = fields[:0]
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"search.reranker.url"}, fields, "cross-encoder needs a url")
+
+	cfg.Search.Reranker.URL = "http://localhost:8080/v1/rerank"
+	assert.False(t, Validate(cfg).HasErrors())
+}
+
+func TestRerankerConfig_UsesModel(t *testing.T) {
+	cfg := DefaultRerankerConfig()
+	assert.False(t, cfg.UsesModel())
+	cfg.Model = "heuristic"
+	assert.False(t, cfg.UsesModel())
+	cfg.Model = "bge-reranker-v2-m3"
+	assert.True(t, cfg.UsesModel())
+	assert.Equal(t, "cross-encoder", cfg.GetProvider())
+
+	cfg.Provider = "llm"
+	assert.Equal(t, "llm", cfg.GetProvider())
+	cfg.Provider = "heuristic"
+	assert.False(t, cfg.UsesModel())
+}
+
 func TestValidate_MultipleErrors(t *testing.T) {
 	cfg := Default()
 	cfg.Version = 0
@@ -735,6 +850,7 @@ func TestDefaultHybridSearchConfig(t *testing.T) {
 	assert.Equal(t, 60, cfg.RRFK, "default RRFK should be 60")
 	assert.Equal(t, 0.7, cfg.VectorWeight, "default vector weight should be 0.7")
 	assert.Equal(t, 0.3, cfg.KeywordWeight, "default keyword weight should be 0.3")
+	assert.Equal(t, "rrf", cfg.Fusion, "default fusion should be rrf")
 }
 
 func TestHybridConfig_DefaultsEnabled(t *testing.T) {
@@ -864,6 +980,9 @@ search:
     timeout_ms: 3000
     fallback: "none"
     candidates: 30
+    url: "http://localhost:8080/rerank"
+    batch_size: 8
+    max_lines: 40
 `
 	configPath := filepath.Join(pommelDir, "config.yaml")
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
@@ -877,6 +996,70 @@ search:
 	assert.Equal(t, 3000, cfg.Search.Reranker.TimeoutMs)
 	assert.Equal(t, "none", cfg.Search.Reranker.Fallback)
 	assert.Equal(t, 30, cfg.Search.Reranker.Candidates)
+	assert.Equal(t, "http://localhost:8080/rerank", cfg.Search.Reranker.URL)
+	assert.Equal(t, 8, cfg.Search.Reranker.BatchSize)
+	assert.Equal(t, 40, cfg.Search.Reranker.MaxLines)
+}
+
+func TestRerankerConfig_LoadSignalsFromYAML(t *testing.T) {
+	tmpDir := t.TempDir()
+	pommelDir := filepath.Join(tmpDir, ".pommel")
+	require.NoError(t, os.MkdirAll(pommelDir, 0755))
+
+	configContent := `
+version: 1
+chunk_levels:
+  - method
+include_patterns:
+  - "**/*.go"
+search:
+  reranker:
+    signals:
+      name_match: 0.3
+      test_penalty: 0
+      test_patterns:
+        - "*Test.kt"
+        - "src/test/"
+      path_boosts:
+        - pattern: "legacy/**"
+          boost: -0.2
+        - pattern: "core/"
+          boost: 0.1
+`
+	configPath := filepath.Join(pommelDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	loader := NewLoader(tmpDir)
+	cfg, err := loader.Load()
+	require.NoError(t, err)
+
+	signals := cfg.Search.Reranker.Signals
+	require.NotNil(t, signals.NameMatch)
+	assert.Equal(t, 0.3, *signals.NameMatch)
+	require.NotNil(t, signals.TestPenalty)
+	assert.Equal(t, 0.0, *signals.TestPenalty)
+	assert.Nil(t, signals.Recency)
+	assert.Equal(t, []string{"*Test.kt", "src/test/"}, signals.TestPatterns)
+	assert.Empty(t, signals.MockPatterns)
+	assert.Equal(t, []PathBoostConfig{{Pattern: "legacy/**", Boost: -0.2}, {Pattern: "core/", Boost: 0.1}}, signals.PathBoosts)
+}
+
+func TestValidate_RerankerSignals(t *testing.T) {
+	negative := -0.1
+	cfg := Default()
+	cfg.Search.Reranker.Signals.Recency = &negative
+	cfg.Search.Reranker.Signals.PathBoosts = []PathBoostConfig{{Pattern: "core/", Boost: 0.1}, {Pattern: " ", Boost: -0.5}}
+
+	fields := make([]string, 0)
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{"search.reranker.signals.recency", "search.reranker.signals.path_boosts[1].pattern"}, fields)
+
+	zero := 0.0
+	cfg.Search.Reranker.Signals.Recency = &zero
+	cfg.Search.Reranker.Signals.PathBoosts = cfg.Search.Reranker.Signals.PathBoosts[:1]
+	assert.False(t, Validate(cfg).HasErrors())
 }
 
 func TestRerankerConfig_DisabledInConfig(t *testing.T) {
@@ -40,11 +40,13 @@ func Default() *Config {
 			LogLevel: "info",
 		},
 		Embedding: EmbeddingConfig{
-			Provider:  "ollama",
-			Model:     "unclemusclez/jina-embeddings-v2-base-code", // Legacy: kept for backwards compatibility
-			OllamaURL: "http://localhost:11434",                    // Legacy: kept for backwards compatibility
-			BatchSize: 32,
-			CacheSize: 1000,
+			Provider:       "ollama",
+			Model:          "unclemusclez/jina-embeddings-v2-base-code", // Legacy: kept for backwards compatibility
+			OllamaURL:      "http://localhost:11434",                    // Legacy: kept for backwards compatibility
+			BatchSize:      32,
+			CacheSize:      1000,
+			Concurrency:    2,
+			MaxBatchTokens: 16384,
 			Ollama: OllamaProviderConfig{
 				URL:   "http://localhost:11434",
 				Model: "unclemusclez/jina-embeddings-v2-base-code",
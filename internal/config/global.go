@@ -130,6 +130,9 @@ func MergeConfigs(global, project *Config) *Config {
 		if len(project.ExcludePatterns) > 0 {
 			result.ExcludePatterns = project.ExcludePatterns
 		}
+		if len(project.TestPatterns) > 0 {
+			result.TestPatterns = project.TestPatterns
+		}
 
 		// Merge watcher settings
 		if project.Watcher.DebounceMs != 0 {
@@ -166,6 +169,12 @@ func MergeConfigs(global, project *Config) *Config {
 		if project.Embedding.CacheSize != 0 {
 			result.Embedding.CacheSize = project.Embedding.CacheSize
 		}
+		if project.Embedding.Concurrency != 0 {
+			result.Embedding.Concurrency = project.Embedding.Concurrency
+		}
+		if project.Embedding.MaxBatchTokens != 0 {
+			result.Embedding.MaxBatchTokens = project.Embedding.MaxBatchTokens
+		}
 
 		// Merge Ollama provider settings
 		if project.Embedding.Ollama.URL != "" {
@@ -198,6 +207,12 @@ func MergeConfigs(global, project *Config) *Config {
 		if len(project.Search.DefaultLevels) > 0 {
 			result.Search.DefaultLevels = project.Search.DefaultLevels
 		}
+		if project.Search.MinScore != 0 {
+			result.Search.MinScore = project.Search.MinScore
+		}
+		if project.Search.MinRelativeScore != 0 {
+			result.Search.MinRelativeScore = project.Search.MinRelativeScore
+		}
 	}
 
 	return result
@@ -254,6 +254,25 @@ func TestMergeConfigs_ProjectOverridesGlobal(t *testing.T) {
 	assert.Equal(t, "pa-project", merged.Embedding.Voyage.APIKey)
 }
 
+func TestMergeConfigs_ProjectScoreCutoffs(t *testing.T) {
+	global := &Config{Search: SearchConfig{MinScore: 0.2, MinRelativeScore: 0.5}}
+	project := &Config{Search: SearchConfig{MinRelativeScore: 0.6}}
+
+	merged := MergeConfigs(global, project)
+	assert.Equal(t, 0.2, merged.Search.MinScore)
+	assert.Equal(t, 0.6, merged.Search.MinRelativeScore)
+}
+
+func TestMergeConfigs_ProjectTestPatterns(t *testing.T) {
+	global := &Config{TestPatterns: []string{"fixtures/"}}
+
+	merged := MergeConfigs(global, &Config{})
+	assert.Equal(t, []string{"fixtures/"}, merged.TestPatterns)
+
+	merged = MergeConfigs(global, &Config{TestPatterns: []string{"e2e/"}})
+	assert.Equal(t, []string{"e2e/"}, merged.TestPatterns)
+}
+
 func TestMergeConfigs_GlobalOnly(t *testing.T) {
 	global := &Config{
 		Embedding: EmbeddingConfig{
@@ -93,6 +93,7 @@ func (l *Loader) Save(cfg *Config) error {
 	l.v.Set("chunk_levels", cfg.ChunkLevels)
 	l.v.Set("include_patterns", cfg.IncludePatterns)
 	l.v.Set("exclude_patterns", cfg.ExcludePatterns)
+	l.v.Set("test_patterns", cfg.TestPatterns)
 	l.v.Set("watcher", cfg.Watcher)
 	l.v.Set("daemon", cfg.Daemon)
 	l.v.Set("embedding", cfg.Embedding)
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"text/template"
 )
 
 // ValidationError represents a configuration validation error
@@ -48,6 +49,13 @@ var validChunkLevels = map[string]bool{
 	"line":    true,
 }
 
+// validRerankerProviders defines the allowed reranker provider values
+var validRerankerProviders = map[string]bool{
+	"heuristic":     true,
+	"cross-encoder": true,
+	"llm":           true,
+}
+
 // validLogLevels defines the allowed log level values
 var validLogLevels = map[string]bool{
 	"debug": true,
@@ -147,6 +155,18 @@ func Validate(cfg *Config) ValidationErrors {
 			Message: "must be non-negative",
 		})
 	}
+	if cfg.Embedding.Concurrency < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "embedding.concurrency",
+			Message: "must be non-negative",
+		})
+	}
+	if cfg.Embedding.MaxBatchTokens < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "embedding.max_batch_tokens",
+			Message: "must be non-negative",
+		})
+	}
 
 	// Search validation
 	if cfg.Search.DefaultLimit < 1 {
@@ -163,6 +183,117 @@ func Validate(cfg *Config) ValidationErrors {
 			})
 		}
 	}
+	if cfg.Search.MinScore < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "search.min_score",
+			Message: "must be non-negative",
+		})
+	}
+	if cfg.Search.MinRelativeScore < 0 || cfg.Search.MinRelativeScore > 1 {
+		errors = append(errors, ValidationError{
+			Field:   "search.min_relative_score",
+			Message: "must be between 0 and 1",
+		})
+	}
+	if provider := cfg.Search.Reranker.Provider; provider != "" && !validRerankerProviders[provider] {
+		errors = append(errors, ValidationError{
+			Field:   "search.reranker.provider",
+			Message: fmt.Sprintf("unknown provider '%s'; valid values are: heuristic, cross-encoder, llm", provider),
+		})
+	}
+	if cfg.Search.Reranker.GetProvider() == "cross-encoder" && strings.TrimSpace(cfg.Search.Reranker.URL) == "" {
+		errors = append(errors, ValidationError{
+			Field:   "search.reranker.url",
+			Message: "is required for the cross-encoder provider; set it to your rerank server's endpoint",
+		})
+	}
+	if cfg.Search.Reranker.Prompt != "" {
+		if _, err := template.New("prompt").Parse(cfg.Search.Reranker.Prompt); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "search.reranker.prompt",
+				Message: fmt.Sprintf("invalid template: %v", err),
+			})
+		}
+	}
+	if fallback := cfg.Search.Reranker.Fallback; fallback != "" && fallback != "heuristic" && fallback != "none" {
+		errors = append(errors, ValidationError{
+			Field:   "search.reranker.fallback",
+			Message: fmt.Sprintf("invalid fallback '%s'; valid values are: heuristic, none", fallback),
+		})
+	}
+	if cfg.Search.Reranker.TimeoutMs < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "search.reranker.timeout_ms",
+			Message: "must be non-negative",
+		})
+	}
+	if cfg.Search.Reranker.BatchSize < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "search.reranker.batch_size",
+			Message: "must be non-negative",
+		})
+	}
+	if cfg.Search.Reranker.MaxLines < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "search.reranker.max_lines",
+			Message: "must be non-negative",
+		})
+	}
+	errors = append(errors, validateSignals(cfg.Search.Reranker.Signals)...)
+	if fusion := cfg.Search.Hybrid.Fusion; fusion != "" && fusion != "rrf" && fusion != "linear" {
+		errors = append(errors, ValidationError{
+			Field:   "search.hybrid.fusion",
+			Message: fmt.Sprintf("invalid fusion method '%s'; valid values are: rrf, linear", fusion),
+		})
+	}
+	if cfg.Search.Hybrid.VectorWeight < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "search.hybrid.vector_weight",
+			Message: "must be non-negative",
+		})
+	}
+	if cfg.Search.Hybrid.KeywordWeight < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "search.hybrid.keyword_weight",
+			Message: "must be non-negative",
+		})
+	}
+
+	return errors
+}
+
+// validateSignals validates the heuristic reranker's signal weights and path boosts
+func validateSignals(signals SignalsConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	weights := []struct {
+		field string
+		value *float64
+	}{
+		{"name_match", signals.NameMatch},
+		{"exact_phrase", signals.ExactPhrase},
+		{"path_match", signals.PathMatch},
+		{"test_penalty", signals.TestPenalty},
+		{"mock_penalty", signals.MockPenalty},
+		{"recency", signals.Recency},
+		{"chunk_type", signals.ChunkType},
+	}
+	for _, w := range weights {
+		if w.value != nil && *w.value < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "search.reranker.signals." + w.field,
+				Message: "must be non-negative",
+			})
+		}
+	}
+	for i, boost := range signals.PathBoosts {
+		if strings.TrimSpace(boost.Pattern) == "" {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("search.reranker.signals.path_boosts[%d].pattern", i),
+				Message: "must not be empty",
+			})
+		}
+	}
 
 	return errors
 }
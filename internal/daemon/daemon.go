@@ -2,49 +2,21 @@ package daemon
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
-	"strings"
+	"path/filepath"
 	"time"
 
 	"github.com/pommel-dev/pommel/internal/config"
 	"github.com/pommel-dev/pommel/internal/db"
 	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/rerank"
 	"github.com/pommel-dev/pommel/internal/search"
 )
 
-// SearchRequest represents a search query request
-type SearchRequest struct {
-	Query      string   `json:"query"`
-	Limit      int      `json:"limit,omitempty"`
-	Levels     []string `json:"levels,omitempty"`
-	PathPrefix string   `json:"path_prefix,omitempty"`
-}
-
-// SearchResponse represents the search results response
-type SearchResponse struct {
-	Results      []SearchResult `json:"results"`
-	Query        string         `json:"query"`
-	Limit        int            `json:"limit"`
-	SearchTimeMs int64          `json:"search_time_ms"`
-}
-
-// SearchResult represents a single search result
-type SearchResult struct {
-	ChunkID   string  `json:"chunk_id"`
-	FilePath  string  `json:"file_path"`
-	Content   string  `json:"content"`
-	Level     string  `json:"level"`
-	Score     float64 `json:"score"`
-	StartLine int     `json:"start_line,omitempty"`
-	EndLine   int     `json:"end_line,omitempty"`
-}
-
 // Daemon orchestrates the Pommel daemon, coordinating file watching,
 // indexing, and API services.
 type Daemon struct {
@@ -58,6 +30,7 @@ type Daemon struct {
 	server        *http.Server
 	state         *StateManager
 	searchService *search.Service
+	handler       http.Handler
 }
 
 // DaemonError represents a daemon-specific error with helpful context.
@@ -214,8 +187,14 @@ func New(projectRoot string, cfg *config.Config, logger *slog.Logger) (*Daemon,
 	// Create state manager
 	state := NewStateManager(projectRoot)
 
-	// Create search service
-	searchSvc := search.NewService(database, cachedEmb)
+	// Create search service with hybrid search and re-ranking from config
+	if warning := rerankerWarning(cfg); warning != "" {
+		logger.Warn(warning)
+	}
+	searchOpts := searchServiceOptions(projectRoot, cfg)
+	searchOpts.LanguageForExtension = indexer.LanguageForExtension
+	searchOpts.Imports = indexer.Imports
+	searchSvc := search.NewServiceWithOptions(database, cachedEmb, searchOpts)
 
 	return &Daemon{
 		projectRoot:   projectRoot,
@@ -230,8 +209,17 @@ func New(projectRoot string, cfg *config.Config, logger *slog.Logger) (*Daemon,
 	}, nil
 }
 
-// Run starts the daemon and blocks until shutdown.
+// Run starts the daemon and blocks until shutdown. The API handler must have
+// been set with SetHandler.
 func (d *Daemon) Run(ctx context.Context) error {
+	if d.handler == nil {
+		return &DaemonError{
+			Code:       "HANDLER_MISSING",
+			Message:    "No API handler set",
+			Suggestion: "Call SetHandler with the API router before Run",
+		}
+	}
+
 	// Check if already running
 	if running, pid := d.state.IsRunning(); running {
 		return &DaemonError{
@@ -271,13 +259,6 @@ func (d *Daemon) Run(ctx context.Context) error {
 	}
 
 	// Start API server
-	mux := http.NewServeMux()
-	mux.HandleFunc("/health", d.handleHealth)
-	mux.HandleFunc("/status", d.handleStatus)
-	mux.HandleFunc("/search", d.handleSearch)
-	mux.HandleFunc("/reindex", d.handleReindex)
-	mux.HandleFunc("/config", d.handleConfig)
-
 	// Determine the port to use (config override or hash-based)
 	port, err := DeterminePort(d.projectRoot, d.config)
 	if err != nil {
@@ -287,7 +268,7 @@ func (d *Daemon) Run(ctx context.Context) error {
 
 	d.server = &http.Server{
 		Addr:    addr,
-		Handler: mux,
+		Handler: d.handler,
 	}
 
 	serverErrCh := make(chan error, 1)
@@ -302,8 +283,11 @@ func (d *Daemon) Run(ctx context.Context) error {
 	// Process file events in goroutine
 	go d.processFileEvents(runCtx)
 
-	// Do initial index if database empty
-	go d.initialIndexIfEmpty(runCtx)
+	// Bring the index up to date with changes made while stopped
+	go d.reconcileIndex(runCtx)
+
+	// Retry files whose index update failed
+	go d.retryFailedFiles(runCtx)
 
 	// Wait for shutdown signal or context cancel
 	select {
@@ -326,129 +310,6 @@ func (d *Daemon) Run(ctx context.Context) error {
 	return d.shutdown()
 }
 
-// HTTP Handlers
-
-func (d *Daemon) handleHealth(w http.ResponseWriter, r *http.Request) {
-	// Get the actual port the server is listening on
-	port := 0
-	if d.server != nil && d.server.Addr != "" {
-		// Parse port from address
-		parts := strings.Split(d.server.Addr, ":")
-		if len(parts) >= 2 {
-			if p, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
-				port = p
-			}
-		}
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":       "healthy",
-		"project_root": d.projectRoot,
-		"port":         port,
-		"timestamp":    time.Now(),
-	})
-}
-
-func (d *Daemon) handleStatus(w http.ResponseWriter, r *http.Request) {
-	stats := d.indexer.Stats()
-
-	// Build index status
-	indexStatus := map[string]interface{}{
-		"total_files":     stats.TotalFiles,
-		"total_chunks":    stats.TotalChunks,
-		"indexing_active": stats.IndexingActive,
-		"pending_changes": stats.PendingFiles,
-	}
-
-	// Add progress information if indexing is active
-	if stats.IndexingActive && stats.FilesToProcess > 0 {
-		percentComplete := float64(stats.FilesProcessed) / float64(stats.FilesToProcess) * 100
-
-		// Calculate ETA
-		var etaSeconds float64
-		if stats.FilesProcessed > 0 && !stats.IndexingStarted.IsZero() {
-			elapsed := time.Since(stats.IndexingStarted).Seconds()
-			rate := float64(stats.FilesProcessed) / elapsed
-			if rate > 0 {
-				remaining := stats.FilesToProcess - stats.FilesProcessed
-				etaSeconds = float64(remaining) / rate
-			}
-		}
-
-		indexStatus["progress"] = map[string]interface{}{
-			"files_to_process": stats.FilesToProcess,
-			"files_processed":  stats.FilesProcessed,
-			"percent_complete": percentComplete,
-			"indexing_started": stats.IndexingStarted,
-			"eta_seconds":      etaSeconds,
-		}
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"daemon": map[string]interface{}{
-			"running": true,
-			"pid":     os.Getpid(),
-		},
-		"index": indexStatus,
-	})
-}
-
-func (d *Daemon) handleSearch(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	var req SearchRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-		return
-	}
-
-	resp, err := d.Search(r.Context(), req)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
-}
-
-func (d *Daemon) handleReindex(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	go func() {
-		ctx := context.Background()
-		if err := d.indexer.ReindexAll(ctx); err != nil {
-			d.logger.Error("background reindex failed", "error", err)
-		}
-	}()
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "started",
-		"message": "Reindexing started in background",
-	})
-}
-
-func (d *Daemon) handleConfig(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"config": d.config,
-	})
-}
-
 // processFileEvents handles file events from the watcher
 func (d *Daemon) processFileEvents(ctx context.Context) {
 	for {
@@ -467,6 +328,11 @@ func (d *Daemon) processFileEvents(ctx context.Context) {
 func (d *Daemon) handleFileEvent(ctx context.Context, event FileEvent) {
 	d.logger.Debug("processing file event", "path", event.Path, "op", event.Op)
 
+	// Adding or removing a marker file changes the sub-project layout
+	if event.Op != OpModify && d.watcher.IsMarkerFile(filepath.Base(event.Path)) {
+		d.syncSubprojects(ctx)
+	}
+
 	switch event.Op {
 	case OpCreate, OpModify:
 		if err := d.indexer.IndexFile(ctx, event.Path); err != nil {
@@ -479,23 +345,100 @@ func (d *Daemon) handleFileEvent(ctx context.Context, event FileEvent) {
 	}
 }
 
-// initialIndexIfEmpty runs initial indexing if the database is empty
-func (d *Daemon) initialIndexIfEmpty(ctx context.Context) {
+// reconcileIndex brings the index up to date on startup. An empty database
+// gets a full index; otherwise the startup scanner finds files added,
+// modified or deleted while the daemon was stopped and only those are indexed.
+// An index whose embeddings were made with a different document prefix is
+// also fully re-indexed. Sub-projects are synced first so that chunks are
+// tagged as they are indexed, and indexed files are re-flagged as tests in
+// case the test patterns changed.
+func (d *Daemon) reconcileIndex(ctx context.Context) {
+	d.syncSubprojects(ctx)
+	if err := d.indexer.SyncTestFiles(ctx); err != nil && ctx.Err() == nil {
+		d.logger.Warn("failed to flag test files", "error", err)
+	}
+
 	fileCount, err := d.db.FileCount(ctx)
 	if err != nil {
 		d.logger.Warn("failed to get file count", "error", err)
 		return
 	}
 
+	scanTime := time.Now()
+
 	if fileCount == 0 {
 		d.logger.Info("database empty, running initial index")
 		if err := d.indexer.ReindexAll(ctx); err != nil {
 			d.logger.Warn("initial indexing failed", "error", err)
-		} else {
-			d.logger.Info("initial indexing complete")
+			return
 		}
+		d.logger.Info("initial indexing complete")
+		d.saveIndexState(scanTime, true)
+		return
+	}
+
+	changed, err := d.indexer.EmbeddingSchemeChanged(ctx)
+	if err != nil {
+		d.logger.Warn("failed to check embedding scheme", "error", err)
+	}
+	if changed {
+		d.logger.Info("embedding scheme changed, re-indexing all files")
+		if err := d.indexer.ReindexAll(ctx); err != nil {
+			d.logger.Warn("re-indexing failed", "error", err)
+			return
+		}
+		d.logger.Info("re-indexing complete")
+		d.saveIndexState(scanTime, true)
+		return
+	}
+
+	ignorer, err := NewIgnorer(d.projectRoot, d.config.ExcludePatterns)
+	if err != nil {
+		d.logger.Warn("failed to create ignorer for startup scan", "error", err)
+		return
+	}
+
+	result, err := NewStartupScanner(d.projectRoot, d.config, d.db, ignorer).Scan(ctx)
+	if err != nil {
+		d.logger.Warn("startup scan failed", "error", err)
+		return
+	}
+
+	if result.TotalChanges() == 0 {
+		d.logger.Info("index is up to date", "files", fileCount)
 	} else {
-		d.logger.Info("database has data, skipping initial index", "files", fileCount)
+		d.logger.Info("reconciling changes since last run",
+			"added", len(result.Added),
+			"modified", len(result.Modified),
+			"deleted", len(result.Deleted))
+		if err := d.indexer.Reconcile(ctx, result); err != nil {
+			d.logger.Warn("startup reconciliation failed", "error", err)
+			return
+		}
+		d.logger.Info("startup reconciliation complete", "changes", result.TotalChanges())
+	}
+
+	d.saveIndexState(scanTime, false)
+}
+
+// saveIndexState records a completed startup scan in the daemon state file.
+func (d *Daemon) saveIndexState(scanTime time.Time, fullIndex bool) {
+	state, err := d.state.LoadState()
+	if err != nil {
+		d.logger.Warn("failed to load daemon state", "error", err)
+		state = &DaemonState{Version: 1}
+	}
+
+	stats := d.indexer.Stats()
+	state.Index.LastScan = scanTime
+	if fullIndex {
+		state.Index.LastFullIndex = scanTime
+	}
+	state.Index.TotalFiles = int(stats.TotalFiles)
+	state.Index.TotalChunks = int(stats.TotalChunks)
+
+	if err := d.state.SaveState(state); err != nil {
+		d.logger.Warn("failed to save daemon state", "error", err)
 	}
 }
 
@@ -551,96 +494,134 @@ func (d *Daemon) cleanup() {
 	}
 }
 
-// Search performs a semantic search and returns matching chunks
-func (d *Daemon) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
-	startTime := time.Now()
+// SearchService returns the daemon's search service.
+// This is used to create adapters for the api.Searcher interface.
+func (d *Daemon) SearchService() *search.Service {
+	return d.searchService
+}
 
-	// Set defaults
-	limit := req.Limit
-	if limit <= 0 {
-		limit = d.config.Search.DefaultLimit
-	}
+// Indexer returns the daemon's indexer.
+func (d *Daemon) Indexer() *Indexer {
+	return d.indexer
+}
 
-	// Get query embedding
-	queryEmbedding, err := d.embedder.EmbedSingle(ctx, req.Query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to embed query: %w", err)
-	}
+// SetHandler sets the HTTP handler used to serve the API.
+// It must be called before Run.
+func (d *Daemon) SetHandler(handler http.Handler) {
+	d.handler = handler
+}
 
-	// Search for similar chunks
-	vectorResults, err := d.db.SearchSimilar(ctx, queryEmbedding, limit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search: %w", err)
+// searchServiceOptions builds the search pipeline options from the search config.
+func searchServiceOptions(projectRoot string, cfg *config.Config) search.ServiceOptions {
+	hybrid := cfg.Search.Hybrid
+	reranker := cfg.Search.Reranker
+
+	return search.ServiceOptions{
+		Hybrid: search.HybridConfig{
+			Enabled:       hybrid.Enabled,
+			RRFK:          hybrid.RRFK,
+			VectorWeight:  hybrid.VectorWeight,
+			KeywordWeight: hybrid.KeywordWeight,
+			Fusion:        hybrid.Fusion,
+		},
+		RerankEnabled:    reranker.Enabled,
+		Reranker:         newReranker(cfg),
+		RerankCandidates: reranker.Candidates,
+		ProjectRoot:      projectRoot,
+		MinScore:         cfg.Search.MinScore,
+		MinRelativeScore: cfg.Search.MinRelativeScore,
 	}
+}
 
-	// Get chunk details
-	chunkIDs := make([]string, len(vectorResults))
-	distanceMap := make(map[string]float32)
-	for i, vr := range vectorResults {
-		chunkIDs[i] = vr.ChunkID
-		distanceMap[vr.ChunkID] = vr.Distance
+// rerankerWarning describes a reranker configuration that can't work and is
+// replaced by the heuristic reranker, or returns "" if there is none.
+func rerankerWarning(cfg *config.Config) string {
+	reranker := cfg.Search.Reranker
+	if reranker.Enabled && reranker.GetProvider() == "cross-encoder" && reranker.URL == "" {
+		return "cross-encoder reranker has no search.reranker.url; using heuristic re-ranking"
 	}
+	return ""
+}
 
-	chunks, err := d.db.GetChunksByIDs(ctx, chunkIDs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get chunks: %w", err)
-	}
-
-	// Build response
-	results := make([]SearchResult, 0, len(chunks))
-	for _, chunk := range chunks {
-		// Filter by levels if specified
-		if len(req.Levels) > 0 {
-			match := false
-			for _, level := range req.Levels {
-				if string(chunk.Level) == level {
-					match = true
-					break
-				}
-			}
-			if !match {
-				continue
-			}
-		}
-
-		// Filter by path prefix if specified
-		if req.PathPrefix != "" {
-			if len(chunk.FilePath) < len(req.PathPrefix) || chunk.FilePath[:len(req.PathPrefix)] != req.PathPrefix {
-				continue
-			}
-		}
-
-		// Convert distance to score (lower distance = higher score)
-		// Distance is typically between 0 and 2 for cosine distance
-		distance := distanceMap[chunk.ID]
-		score := 1.0 - float64(distance)/2.0
-		if score < 0 {
-			score = 0
+// newReranker builds the configured reranker. A model-based reranker is
+// wrapped to fall back to the heuristic reranker when it fails or times out,
+// unless the fallback is "none".
+func newReranker(cfg *config.Config) rerank.Reranker {
+	reranker := cfg.Search.Reranker
+	timeout := time.Duration(reranker.TimeoutMs) * time.Millisecond
+	heuristic := rerank.NewHeuristicRerankerWithConfig(signalConfig(reranker.Signals))
+
+	var primary rerank.Reranker
+	switch reranker.GetProvider() {
+	case "cross-encoder":
+		crossEncoder, err := rerank.NewCrossEncoderReranker(rerank.CrossEncoderConfig{
+			URL:       reranker.URL,
+			Model:     reranker.Model,
+			BatchSize: reranker.BatchSize,
+			Timeout:   timeout,
+		})
+		if err != nil {
+			// There is no standard rerank endpoint to guess; see rerankerWarning
+			return heuristic
 		}
-
-		results = append(results, SearchResult{
-			ChunkID:   chunk.ID,
-			FilePath:  chunk.FilePath,
-			Content:   chunk.Content,
-			Level:     string(chunk.Level),
-			Score:     score,
-			StartLine: chunk.StartLine,
-			EndLine:   chunk.EndLine,
+		primary = crossEncoder
+	case "llm":
+		llm, err := rerank.NewLLMReranker(rerank.LLMConfig{
+			URL:           reranker.URL,
+			Model:         reranker.Model,
+			Prompt:        reranker.Prompt,
+			MaxLines:      reranker.MaxLines,
+			MaxCandidates: reranker.Candidates,
+			Timeout:       timeout,
 		})
+		if err != nil {
+			// Validation rejects bad templates, so this only guards direct callers
+			return heuristic
+		}
+		primary = llm
+	default:
+		return heuristic
 	}
 
-	return &SearchResponse{
-		Results:      results,
-		Query:        req.Query,
-		Limit:        limit,
-		SearchTimeMs: time.Since(startTime).Milliseconds(),
-	}, nil
+	if reranker.Fallback == "none" {
+		return primary
+	}
+	if timeout <= 0 {
+		timeout = time.Duration(config.DefaultRerankerConfig().TimeoutMs) * time.Millisecond
+	}
+	return rerank.NewFallbackReranker(primary, heuristic, timeout)
 }
 
-// SearchService returns the daemon's search service.
-// This is used to create adapters for the api.Searcher interface.
-func (d *Daemon) SearchService() *search.Service {
-	return d.searchService
+// signalConfig applies the configured heuristic signal settings over the
+// reranker's defaults.
+func signalConfig(signals config.SignalsConfig) rerank.SignalConfig {
+	cfg := rerank.DefaultSignalConfig()
+	for _, w := range []struct {
+		value  *float64
+		target *float64
+	}{
+		{signals.NameMatch, &cfg.NameMatch},
+		{signals.ExactPhrase, &cfg.ExactPhrase},
+		{signals.PathMatch, &cfg.PathMatch},
+		{signals.TestPenalty, &cfg.TestPenalty},
+		{signals.MockPenalty, &cfg.MockPenalty},
+		{signals.Recency, &cfg.Recency},
+		{signals.ChunkType, &cfg.ChunkType},
+	} {
+		if w.value != nil {
+			*w.target = *w.value
+		}
+	}
+	if len(signals.TestPatterns) > 0 {
+		cfg.TestPatterns = signals.TestPatterns
+	}
+	if len(signals.MockPatterns) > 0 {
+		cfg.MockPatterns = signals.MockPatterns
+	}
+	for _, boost := range signals.PathBoosts {
+		cfg.PathBoosts = append(cfg.PathBoosts, rerank.PathBoost{Pattern: boost.Pattern, Boost: boost.Boost})
+	}
+	return cfg
 }
 
 // Close releases all resources held by the daemon.
@@ -1,19 +1,21 @@
 package daemon
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/pommel-dev/pommel/internal/config"
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/rerank"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -50,7 +52,30 @@ func skipIfNoOllama(t *testing.T) {
 	}
 }
 
-// Note: SearchRequest, SearchResponse, and SearchResult are now defined in daemon.go
+// useMockEmbedder swaps the daemon's indexer for one backed by the mock
+// embedder so indexing works without Ollama. Must be called before Run.
+func useMockEmbedder(t *testing.T, d *Daemon) {
+	t.Helper()
+	indexer, err := NewIndexer(d.projectRoot, d.config, d.db, embedder.NewMockEmbedder(), d.logger)
+	require.NoError(t, err)
+	d.indexer = indexer
+}
+
+// newTestDaemon creates a daemon serving a stub API handler, standing in for
+// the api router that cmd/pommeld sets.
+func newTestDaemon(projectRoot string, cfg *config.Config, logger *slog.Logger) (*Daemon, error) {
+	d, err := New(projectRoot, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status": "healthy"}`))
+	})
+	d.SetHandler(mux)
+	return d, nil
+}
 
 // =============================================================================
 // Daemon Creation Tests
@@ -134,7 +159,7 @@ func TestRun_StartsAllComponents(t *testing.T) {
 	cfg := daemonTestConfig()
 	logger := daemonTestLogger()
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
@@ -157,13 +182,25 @@ func TestRun_StartsAllComponents(t *testing.T) {
 		"Run should complete without unexpected error, got: %v", err)
 }
 
+func TestRun_FailsWithoutHandler(t *testing.T) {
+	daemon, err := New(t.TempDir(), daemonTestConfig(), daemonTestLogger())
+	require.NoError(t, err)
+	defer daemon.Close()
+
+	err = daemon.Run(context.Background())
+
+	var daemonErr *DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, "HANDLER_MISSING", daemonErr.Code)
+}
+
 func TestRun_WritesPIDFile(t *testing.T) {
 	// Arrange
 	projectRoot := t.TempDir()
 	cfg := daemonTestConfig()
 	logger := daemonTestLogger()
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
@@ -195,7 +232,7 @@ func TestRun_ChecksForAlreadyRunningDaemon(t *testing.T) {
 	logger := daemonTestLogger()
 
 	// Create first daemon and start it
-	daemon1, err := New(projectRoot, cfg, logger)
+	daemon1, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
 	ctx1, cancel1 := context.WithCancel(context.Background())
@@ -208,7 +245,7 @@ func TestRun_ChecksForAlreadyRunningDaemon(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// Create second daemon
-	daemon2, err := New(projectRoot, cfg, logger)
+	daemon2, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
 	ctx2, cancel2 := context.WithTimeout(context.Background(), 100*time.Millisecond)
@@ -234,7 +271,7 @@ func TestRun_GracefulShutdownOnContextCancel(t *testing.T) {
 	cfg := daemonTestConfig()
 	logger := daemonTestLogger()
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -277,7 +314,7 @@ func TestRun_GracefulShutdownOnSIGTERM(t *testing.T) {
 	cfg := daemonTestConfig()
 	logger := daemonTestLogger()
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
 	ctx := context.Background()
@@ -321,7 +358,7 @@ func TestFileCreate_TriggersIndexing(t *testing.T) {
 	cfg.IncludePatterns = []string{"**/*.go"}
 	logger := daemonTestLogger()
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -365,8 +402,9 @@ func TestFileModify_TriggersReindexing(t *testing.T) {
 	err := os.WriteFile(testFile, []byte("package main\n\nfunc hello() {}"), 0644)
 	require.NoError(t, err)
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
+	useMockEmbedder(t, daemon)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -410,8 +448,9 @@ func TestFileDelete_TriggersRemovalFromIndex(t *testing.T) {
 	err := os.WriteFile(testFile, []byte("package main\n\nfunc hello() {}"), 0644)
 	require.NoError(t, err)
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
+	useMockEmbedder(t, daemon)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -464,8 +503,9 @@ func TestInitialIndex_RunsWhenDatabaseEmpty(t *testing.T) {
 	err = os.WriteFile(testFile2, []byte("package main\n\nfunc helper() {}"), 0644)
 	require.NoError(t, err)
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
+	useMockEmbedder(t, daemon)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
@@ -502,8 +542,9 @@ func TestInitialIndex_SkippedWhenDatabaseHasData(t *testing.T) {
 	require.NoError(t, err)
 
 	// First daemon run to populate database
-	daemon1, err := New(projectRoot, cfg, logger)
+	daemon1, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
+	useMockEmbedder(t, daemon1)
 
 	ctx1, cancel1 := context.WithTimeout(context.Background(), 1*time.Second)
 	errCh1 := make(chan error, 1)
@@ -520,8 +561,9 @@ func TestInitialIndex_SkippedWhenDatabaseHasData(t *testing.T) {
 	stats1 := daemon1.indexer.Stats()
 
 	// Create second daemon - should skip initial indexing
-	daemon2, err := New(projectRoot, cfg, logger)
+	daemon2, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
+	useMockEmbedder(t, daemon2)
 
 	ctx2, cancel2 := context.WithTimeout(context.Background(), 500*time.Millisecond)
 	defer cancel2()
@@ -550,6 +592,59 @@ func TestInitialIndex_SkippedWhenDatabaseHasData(t *testing.T) {
 	<-errCh2
 }
 
+func TestReconcileIndex_IndexesChangesMadeWhileStopped(t *testing.T) {
+	// Arrange
+	projectRoot := t.TempDir()
+	cfg := daemonTestConfig()
+	cfg.IncludePatterns = []string{"**/*.go"}
+	logger := daemonTestLogger()
+
+	keep := filepath.Join(projectRoot, "keep.go")
+	require.NoError(t, os.WriteFile(keep, []byte("package main\n\nfunc keep() {}"), 0644))
+	gone := filepath.Join(projectRoot, "gone.go")
+	require.NoError(t, os.WriteFile(gone, []byte("package main\n\nfunc gone() {}"), 0644))
+
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
+	require.NoError(t, err)
+	defer daemon.Close()
+
+	useMockEmbedder(t, daemon)
+
+	ctx := context.Background()
+
+	// Act - first boot runs a full index
+	daemon.reconcileIndex(ctx)
+
+	state, err := daemon.state.LoadState()
+	require.NoError(t, err)
+	assert.False(t, state.Index.LastFullIndex.IsZero())
+	assert.Equal(t, state.Index.LastFullIndex, state.Index.LastScan)
+	assert.Equal(t, 2, state.Index.TotalFiles)
+	firstScan := state.Index.LastScan
+
+	// Simulate edits made while the daemon was stopped
+	require.NoError(t, os.Remove(gone))
+	added := filepath.Join(projectRoot, "added.go")
+	require.NoError(t, os.WriteFile(added, []byte("package main\n\nfunc added() {}"), 0644))
+
+	daemon.reconcileIndex(ctx)
+
+	// Assert - only the delta was applied
+	files, err := daemon.db.ListFiles(ctx)
+	require.NoError(t, err)
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	assert.ElementsMatch(t, []string{keep, added}, paths)
+
+	state, err = daemon.state.LoadState()
+	require.NoError(t, err)
+	assert.True(t, state.Index.LastScan.After(firstScan), "LastScan should advance on every boot")
+	assert.True(t, state.Index.LastFullIndex.Equal(firstScan), "LastFullIndex should only change on a full index")
+	assert.Equal(t, 2, state.Index.TotalFiles)
+}
+
 // =============================================================================
 // API Server Tests
 // =============================================================================
@@ -561,7 +656,7 @@ func TestAPIServer_StartsOnConfiguredPort(t *testing.T) {
 	cfg.Daemon.Port = intPtr(17421) // Use a specific test port
 	logger := daemonTestLogger()
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -596,7 +691,7 @@ func TestAPIServer_HealthEndpointAccessible(t *testing.T) {
 	cfg.Daemon.Port = intPtr(17422) // Use a specific test port
 	logger := daemonTestLogger()
 
-	daemon, err := New(projectRoot, cfg, logger)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -626,36 +721,21 @@ func TestAPIServer_HealthEndpointAccessible(t *testing.T) {
 	<-errCh
 }
 
-// =============================================================================
-// Search Tests
-// =============================================================================
-
-func TestDaemon_Search(t *testing.T) {
-	skipIfNoOllama(t)
+func TestAPIServer_UsesCustomHandler(t *testing.T) {
 	// Arrange
 	projectRoot := t.TempDir()
 	cfg := daemonTestConfig()
-	cfg.IncludePatterns = []string{"**/*.go"}
+	cfg.Daemon.Port = intPtr(17431) // Use a specific test port
 	logger := daemonTestLogger()
 
-	// Create a file with searchable content
-	testFile := filepath.Join(projectRoot, "main.go")
-	err := os.WriteFile(testFile, []byte(`package main
-
-// calculateSum adds two numbers together
-func calculateSum(a, b int) int {
-	return a + b
-}
-
-// calculateDifference subtracts two numbers
-func calculateDifference(a, b int) int {
-	return a - b
-}
-`), 0644)
+	daemon, err := newTestDaemon(projectRoot, cfg, logger)
 	require.NoError(t, err)
 
-	daemon, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	daemon.SetHandler(mux)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -665,36 +745,40 @@ func calculateDifference(a, b int) int {
 		errCh <- daemon.Run(ctx)
 	}()
 
-	// Wait for indexing
-	time.Sleep(500 * time.Millisecond)
+	// Wait for server to start
+	time.Sleep(200 * time.Millisecond)
 
 	// Act
-	req := SearchRequest{
-		Query: "add two numbers",
-		Limit: 5,
-	}
-	resp, err := daemon.Search(ctx, req)
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get("http://127.0.0.1:17431/health")
 
 	// Assert
-	require.NoError(t, err)
-	require.NotNil(t, resp)
-	assert.NotEmpty(t, resp.Results, "Search should return results")
-
-	// The calculateSum function should be in results
-	found := false
-	for _, result := range resp.Results {
-		if result.FilePath == testFile {
-			found = true
-			break
-		}
-	}
-	assert.True(t, found, "Search results should include the test file")
+	require.NoError(t, err, "Custom handler should be served")
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
 
 	// Cleanup
 	cancel()
 	<-errCh
 }
 
+func TestDaemon_Indexer(t *testing.T) {
+	// Arrange
+	projectRoot := t.TempDir()
+	cfg := daemonTestConfig()
+	logger := daemonTestLogger()
+
+	daemon, err := New(projectRoot, cfg, logger)
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.NotNil(t, daemon.Indexer(), "Indexer should return a non-nil indexer")
+
+	// Cleanup - required on Windows to release file handles
+	require.NoError(t, daemon.Close())
+}
+
 // =============================================================================
 // DaemonError Tests
 // =============================================================================
@@ -846,218 +930,6 @@ func TestNew_UsesDefaultCacheSize_WhenNegativeProvided(t *testing.T) {
 	require.NoError(t, daemon.Close())
 }
 
-// =============================================================================
-// Search Error Cases
-// =============================================================================
-
-func TestDaemon_Search_WithLevelFilter(t *testing.T) {
-	skipIfNoOllama(t)
-	// Arrange
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.IncludePatterns = []string{"**/*.go"}
-	logger := daemonTestLogger()
-
-	// Create a test file
-	testFile := filepath.Join(projectRoot, "main.go")
-	err := os.WriteFile(testFile, []byte(`package main
-
-func main() {}
-`), 0644)
-	require.NoError(t, err)
-
-	daemon, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- daemon.Run(ctx)
-	}()
-
-	// Wait for indexing
-	time.Sleep(500 * time.Millisecond)
-
-	// Act - search with level filter
-	req := SearchRequest{
-		Query:  "main function",
-		Limit:  5,
-		Levels: []string{"method"},
-	}
-	resp, err := daemon.Search(ctx, req)
-
-	// Assert
-	require.NoError(t, err)
-	require.NotNil(t, resp)
-	// Results should all be of the method level
-	for _, result := range resp.Results {
-		assert.Equal(t, "method", result.Level)
-	}
-
-	// Cleanup
-	cancel()
-	<-errCh
-}
-
-func TestDaemon_Search_WithPathPrefix(t *testing.T) {
-	skipIfNoOllama(t)
-	// Arrange
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.IncludePatterns = []string{"**/*.go"}
-	logger := daemonTestLogger()
-
-	// Create subdirectory
-	subDir := filepath.Join(projectRoot, "pkg", "handler")
-	require.NoError(t, os.MkdirAll(subDir, 0755))
-
-	// Create files in different locations
-	err := os.WriteFile(filepath.Join(projectRoot, "main.go"), []byte(`package main
-
-func main() {}
-`), 0644)
-	require.NoError(t, err)
-
-	err = os.WriteFile(filepath.Join(subDir, "handler.go"), []byte(`package handler
-
-func Handler() {}
-`), 0644)
-	require.NoError(t, err)
-
-	daemon, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- daemon.Run(ctx)
-	}()
-
-	// Wait for indexing
-	time.Sleep(500 * time.Millisecond)
-
-	// Act - search with path prefix
-	req := SearchRequest{
-		Query:      "function",
-		Limit:      10,
-		PathPrefix: "pkg/",
-	}
-	resp, err := daemon.Search(ctx, req)
-
-	// Assert
-	require.NoError(t, err)
-	require.NotNil(t, resp)
-	// All results should be from pkg/
-	for _, result := range resp.Results {
-		assert.Contains(t, result.FilePath, "pkg/")
-	}
-
-	// Cleanup
-	cancel()
-	<-errCh
-}
-
-func TestDaemon_Search_ScoreCalculation(t *testing.T) {
-	skipIfNoOllama(t)
-	// Arrange
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.IncludePatterns = []string{"**/*.go"}
-	logger := daemonTestLogger()
-
-	testFile := filepath.Join(projectRoot, "main.go")
-	err := os.WriteFile(testFile, []byte(`package main
-
-func main() {}
-`), 0644)
-	require.NoError(t, err)
-
-	daemon, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- daemon.Run(ctx)
-	}()
-
-	// Wait for indexing
-	time.Sleep(500 * time.Millisecond)
-
-	// Act
-	req := SearchRequest{
-		Query: "main function",
-		Limit: 5,
-	}
-	resp, err := daemon.Search(ctx, req)
-
-	// Assert
-	require.NoError(t, err)
-	require.NotNil(t, resp)
-	// Scores should be between 0 and 1
-	for _, result := range resp.Results {
-		assert.GreaterOrEqual(t, result.Score, 0.0)
-		assert.LessOrEqual(t, result.Score, 1.0)
-	}
-
-	// Cleanup
-	cancel()
-	<-errCh
-}
-
-func TestDaemon_Search_DefaultLimit(t *testing.T) {
-	skipIfNoOllama(t)
-	// Arrange
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.IncludePatterns = []string{"**/*.go"}
-	cfg.Search.DefaultLimit = 5 // Set a specific default limit
-	logger := daemonTestLogger()
-
-	testFile := filepath.Join(projectRoot, "main.go")
-	err := os.WriteFile(testFile, []byte(`package main
-
-func main() {}
-`), 0644)
-	require.NoError(t, err)
-
-	daemon, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- daemon.Run(ctx)
-	}()
-
-	// Wait for indexing
-	time.Sleep(500 * time.Millisecond)
-
-	// Act - search without specifying limit
-	req := SearchRequest{
-		Query: "main",
-		Limit: 0, // Zero means use default
-	}
-	resp, err := daemon.Search(ctx, req)
-
-	// Assert
-	require.NoError(t, err)
-	require.NotNil(t, resp)
-	assert.Equal(t, 5, resp.Limit, "Should use configured default limit")
-
-	// Cleanup
-	cancel()
-	<-errCh
-}
-
 // =============================================================================
 // SearchService Tests
 // =============================================================================
@@ -1081,293 +953,73 @@ func TestDaemon_SearchService(t *testing.T) {
 	require.NoError(t, daemon.Close())
 }
 
-// =============================================================================
-// HTTP Handler Tests (Internal)
-// =============================================================================
-
-func TestDaemon_HandleHealth_ReturnsOK(t *testing.T) {
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.Daemon.Port = intPtr(17423)
-	logger := daemonTestLogger()
-
-	d, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- d.Run(ctx)
-	}()
-
-	time.Sleep(200 * time.Millisecond)
-
-	// Make HTTP request to health endpoint
-	client := &http.Client{Timeout: time.Second}
-	resp, err := client.Get("http://127.0.0.1:17423/health")
-	require.NoError(t, err)
-	defer resp.Body.Close()
-
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-	// Verify response contains required fields
-	var health map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&health)
-	require.NoError(t, err)
-
-	assert.Equal(t, "healthy", health["status"])
-	assert.Equal(t, projectRoot, health["project_root"])
-	assert.Equal(t, float64(17423), health["port"])
-	assert.Contains(t, health, "timestamp")
-
-	cancel()
-	<-errCh
-}
-
-func TestDaemon_HandleStatus_ReturnsStatus(t *testing.T) {
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.Daemon.Port = intPtr(17424)
-	logger := daemonTestLogger()
-
-	d, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- d.Run(ctx)
-	}()
-
-	time.Sleep(200 * time.Millisecond)
-
-	// Make HTTP request to status endpoint
-	client := &http.Client{Timeout: time.Second}
-	resp, err := client.Get("http://127.0.0.1:17424/status")
-	require.NoError(t, err)
-	defer resp.Body.Close()
-
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-	// Verify JSON response structure
-	var result map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&result)
-	require.NoError(t, err)
-
-	_, hasDaemon := result["daemon"]
-	_, hasIndex := result["index"]
-	assert.True(t, hasDaemon, "Response should have daemon key")
-	assert.True(t, hasIndex, "Response should have index key")
-
-	cancel()
-	<-errCh
-}
-
-func TestDaemon_HandleSearch_MethodNotAllowed(t *testing.T) {
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.Daemon.Port = intPtr(17425)
-	logger := daemonTestLogger()
-
-	d, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- d.Run(ctx)
-	}()
-
-	time.Sleep(200 * time.Millisecond)
-
-	// GET should not be allowed for search
-	client := &http.Client{Timeout: time.Second}
-	resp, err := client.Get("http://127.0.0.1:17425/search")
-	require.NoError(t, err)
-	defer resp.Body.Close()
-
-	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
-
-	cancel()
-	<-errCh
-}
-
-func TestDaemon_HandleSearch_InvalidJSON(t *testing.T) {
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.Daemon.Port = intPtr(17426)
-	logger := daemonTestLogger()
-
-	d, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- d.Run(ctx)
-	}()
-
-	time.Sleep(200 * time.Millisecond)
-
-	// Send invalid JSON
-	client := &http.Client{Timeout: time.Second}
-	resp, err := client.Post("http://127.0.0.1:17426/search", "application/json", bytes.NewBufferString("{invalid}"))
-	require.NoError(t, err)
-	defer resp.Body.Close()
-
-	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
-
-	cancel()
-	<-errCh
-}
-
-func TestDaemon_HandleSearch_ValidRequest(t *testing.T) {
-	skipIfNoOllama(t)
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.IncludePatterns = []string{"**/*.go"}
-	cfg.Daemon.Port = intPtr(17427)
-	logger := daemonTestLogger()
-
-	// Create a test file
-	testFile := filepath.Join(projectRoot, "main.go")
-	err := os.WriteFile(testFile, []byte(`package main
-
-func main() {}
-`), 0644)
-	require.NoError(t, err)
-
-	d, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- d.Run(ctx)
-	}()
-
-	time.Sleep(500 * time.Millisecond) // Wait for indexing
-
-	// Send valid search request
-	reqBody := `{"query": "main function", "limit": 5}`
-	client := &http.Client{Timeout: time.Second}
-	resp, err := client.Post("http://127.0.0.1:17427/search", "application/json", bytes.NewBufferString(reqBody))
-	require.NoError(t, err)
-	defer resp.Body.Close()
-
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-	cancel()
-	<-errCh
-}
+func TestNewReranker(t *testing.T) {
+	cfg := config.Default()
+	assert.Equal(t, "heuristic", newReranker(cfg).Name(), "no model means heuristic only")
 
-func TestDaemon_HandleReindex_MethodNotAllowed(t *testing.T) {
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.Daemon.Port = intPtr(17428)
-	logger := daemonTestLogger()
+	cfg.Search.Reranker.Model = "heuristic"
+	assert.Equal(t, "heuristic", newReranker(cfg).Name())
 
-	d, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	cfg.Search.Reranker.Model = "bge-reranker-v2-m3"
+	assert.Equal(t, "heuristic", newReranker(cfg).Name(), "a cross-encoder without a url can't be reached")
+	assert.NotEmpty(t, rerankerWarning(cfg))
 
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- d.Run(ctx)
-	}()
+	cfg.Search.Reranker.URL = "http://localhost:8080/v1/rerank"
+	assert.Equal(t, "cross-encoder->heuristic", newReranker(cfg).Name())
+	assert.Empty(t, rerankerWarning(cfg))
 
-	time.Sleep(200 * time.Millisecond)
+	cfg.Search.Reranker.Fallback = "none"
+	assert.Equal(t, "cross-encoder", newReranker(cfg).Name())
 
-	// GET should not be allowed for reindex
-	client := &http.Client{Timeout: time.Second}
-	resp, err := client.Get("http://127.0.0.1:17428/reindex")
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	cfg.Search.Reranker.Provider = "llm"
+	assert.Equal(t, "llm", newReranker(cfg).Name())
 
-	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	cfg.Search.Reranker.Fallback = "heuristic"
+	assert.Equal(t, "llm->heuristic", newReranker(cfg).Name())
 
-	cancel()
-	<-errCh
+	cfg.Search.Reranker.Provider = "heuristic"
+	assert.Equal(t, "heuristic", newReranker(cfg).Name(), "an explicit provider wins over the model")
 }
 
-func TestDaemon_HandleReindex_ValidRequest(t *testing.T) {
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.Daemon.Port = intPtr(17429)
-	logger := daemonTestLogger()
-
-	d, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+func TestNewReranker_LLMMaxLines(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		body = string(raw)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "[3]"}}]}`))
+	}))
+	defer server.Close()
 
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- d.Run(ctx)
-	}()
-
-	time.Sleep(200 * time.Millisecond)
+	cfg := config.Default()
+	cfg.Search.Reranker.Provider = "llm"
+	cfg.Search.Reranker.URL = server.URL
+	cfg.Search.Reranker.Fallback = "none"
+	cfg.Search.Reranker.MaxLines = 1
 
-	// Send valid reindex request
-	client := &http.Client{Timeout: time.Second}
-	resp, err := client.Post("http://127.0.0.1:17429/reindex", "application/json", nil)
+	_, err := newReranker(cfg).Rerank(context.Background(), "retry", []rerank.Candidate{
+		{ChunkID: "a", FilePath: "retry.go", Content: "first line\nsecond line", BaseScore: 0.5},
+	})
 	require.NoError(t, err)
-	defer resp.Body.Close()
-
-	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
-
-	cancel()
-	<-errCh
+	assert.True(t, strings.Contains(body, "first line"))
+	assert.False(t, strings.Contains(body, "second line"), "only max_lines lines should be sent")
 }
 
-func TestDaemon_HandleConfig_ReturnsConfig(t *testing.T) {
-	projectRoot := t.TempDir()
-	cfg := daemonTestConfig()
-	cfg.Daemon.Port = intPtr(17430)
-	logger := daemonTestLogger()
-
-	d, err := New(projectRoot, cfg, logger)
-	require.NoError(t, err)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- d.Run(ctx)
-	}()
-
-	time.Sleep(200 * time.Millisecond)
-
-	// Get config
-	client := &http.Client{Timeout: time.Second}
-	resp, err := client.Get("http://127.0.0.1:17430/config")
-	require.NoError(t, err)
-	defer resp.Body.Close()
-
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-	// Verify JSON response structure
-	var result map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&result)
-	require.NoError(t, err)
-
-	_, hasConfig := result["config"]
-	assert.True(t, hasConfig, "Response should have config key")
-
-	cancel()
-	<-errCh
+func TestSignalConfig(t *testing.T) {
+	defaults := rerank.DefaultSignalConfig()
+	assert.Equal(t, defaults, signalConfig(config.SignalsConfig{}), "unset signals keep the defaults")
+
+	zero, weight := 0.0, 0.4
+	cfg := signalConfig(config.SignalsConfig{
+		TestPenalty:  &zero,
+		NameMatch:    &weight,
+		MockPatterns: []string{"fakes/"},
+		PathBoosts:   []config.PathBoostConfig{{Pattern: "legacy/**", Boost: -0.2}},
+	})
+	assert.Equal(t, 0.0, cfg.TestPenalty)
+	assert.Equal(t, 0.4, cfg.NameMatch)
+	assert.Equal(t, defaults.Recency, cfg.Recency)
+	assert.Equal(t, defaults.TestPatterns, cfg.TestPatterns)
+	assert.Equal(t, []string{"fakes/"}, cfg.MockPatterns)
+	assert.Equal(t, []rerank.PathBoost{{Pattern: "legacy/**", Boost: -0.2}}, cfg.PathBoosts)
 }
@@ -5,20 +5,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/pommel-dev/pommel/internal/pathutil"
 )
 
 // Ignorer handles file and directory ignore patterns
 type Ignorer struct {
 	projectRoot string
-	patterns    []pattern
-}
-
-// pattern represents a single ignore pattern
-type pattern struct {
-	original string
-	negation bool
-	dirOnly  bool
-	pattern  string
+	patterns    []pathutil.Pattern
 }
 
 // NewIgnorer creates a new Ignorer with the given project root and config patterns
@@ -34,7 +28,7 @@ func NewIgnorer(projectRoot string, configPatterns []string) (*Ignorer, error) {
 
 	i := &Ignorer{
 		projectRoot: projectRoot,
-		patterns:    make([]pattern, 0),
+		patterns:    make([]pathutil.Pattern, 0),
 	}
 
 	// Always add .pommel directory as ignored
@@ -85,24 +79,7 @@ func (i *Ignorer) loadPatternsFromFile(path string) error {
 
 // addPattern adds a pattern to the ignorer
 func (i *Ignorer) addPattern(p string) {
-	pat := pattern{
-		original: p,
-	}
-
-	// Check for negation
-	if strings.HasPrefix(p, "!") {
-		pat.negation = true
-		p = p[1:]
-	}
-
-	// Check for directory-only pattern
-	if strings.HasSuffix(p, "/") {
-		pat.dirOnly = true
-		p = strings.TrimSuffix(p, "/")
-	}
-
-	pat.pattern = p
-	i.patterns = append(i.patterns, pat)
+	i.patterns = append(i.patterns, pathutil.ParsePattern(p))
 }
 
 // ShouldIgnore returns true if the given path should be ignored
@@ -110,21 +87,7 @@ func (i *Ignorer) ShouldIgnore(path string) bool {
 	// Normalize the path to be relative to project root
 	relPath := i.normalizePath(path)
 
-	// Track whether the path is currently ignored
-	ignored := false
-
-	// Process patterns in order
-	for _, pat := range i.patterns {
-		if i.matchesPattern(relPath, pat) {
-			if pat.negation {
-				ignored = false
-			} else {
-				ignored = true
-			}
-		}
-	}
-
-	return ignored
+	return pathutil.MatchPatterns(i.patterns, relPath)
 }
 
 // normalizePath converts a path to be relative to project root
@@ -142,182 +105,3 @@ func (i *Ignorer) normalizePath(path string) string {
 
 	return relPath
 }
-
-// matchesPattern checks if a path matches a pattern
-func (i *Ignorer) matchesPattern(path string, pat pattern) bool {
-	// Get the pattern string
-	p := pat.pattern
-
-	// Handle ** patterns
-	if strings.Contains(p, "**") {
-		return i.matchDoubleStarPattern(path, p, pat.dirOnly)
-	}
-
-	// Handle directory patterns
-	if pat.dirOnly {
-		return i.matchDirectoryPattern(path, p)
-	}
-
-	// Handle simple glob patterns (*.log)
-	if strings.Contains(p, "*") && !strings.Contains(p, "/") {
-		// Check against basename of all path components and the file itself
-		return i.matchGlobPattern(path, p)
-	}
-
-	// Handle path patterns (contains /)
-	if strings.Contains(p, "/") {
-		return i.matchPathPattern(path, p)
-	}
-
-	// Exact match against filename
-	return i.matchExactPattern(path, p)
-}
-
-// matchDoubleStarPattern matches patterns containing **
-func (i *Ignorer) matchDoubleStarPattern(path, pattern string, dirOnly bool) bool {
-	// Normalize separators for cross-platform compatibility
-	// This ensures patterns with forward slashes match paths with backslashes (Windows)
-	// We explicitly replace backslashes because filepath.ToSlash only converts the
-	// OS-native separator, which doesn't help when testing with backslash paths on Unix
-	normalizedPath := strings.ReplaceAll(path, "\\", "/")
-	normalizedPattern := strings.ReplaceAll(pattern, "\\", "/")
-
-	// Trim trailing slashes to avoid matching directories without content
-	// e.g., ".venv/" should not match "**/.venv/**" (the pattern requires content inside)
-	normalizedPath = strings.TrimSuffix(normalizedPath, "/")
-
-	// Handle **/*.ext pattern - match at any depth
-	if strings.HasPrefix(normalizedPattern, "**/") {
-		subPattern := normalizedPattern[3:] // Remove **/
-
-		// Check against basename
-		base := filepath.Base(normalizedPath)
-		matched, _ := filepath.Match(subPattern, base)
-		if matched {
-			return true
-		}
-
-		// Handle patterns like **/.venv/** (directory with trailing **)
-		// These need special handling to match paths inside the directory
-		if strings.HasSuffix(subPattern, "/**") {
-			dirName := subPattern[:len(subPattern)-3] // Remove trailing /**
-
-			// Check if any path component matches the directory name
-			parts := strings.Split(normalizedPath, "/")
-			for idx, part := range parts {
-				if part == dirName {
-					// Check if there are actual (non-empty) components after this one
-					hasContentAfter := false
-					for j := idx + 1; j < len(parts); j++ {
-						if parts[j] != "" {
-							hasContentAfter = true
-							break
-						}
-					}
-					if hasContentAfter {
-						return true
-					}
-				}
-			}
-		}
-
-		// Also check against all path components
-		parts := strings.Split(normalizedPath, "/")
-		for idx := 0; idx < len(parts); idx++ {
-			subPath := strings.Join(parts[idx:], "/")
-			matched, _ := filepath.Match(subPattern, subPath)
-			if matched {
-				return true
-			}
-		}
-	}
-
-	// Handle patterns that end with ** but don't start with **
-	// e.g., ".venv/**" should match ".venv/anything"
-	if strings.HasSuffix(normalizedPattern, "/**") && !strings.HasPrefix(normalizedPattern, "**/") {
-		prefix := normalizedPattern[:len(normalizedPattern)-3] // Remove /**
-		if strings.HasPrefix(normalizedPath, prefix+"/") || normalizedPath == prefix {
-			return true
-		}
-	}
-
-	return false
-}
-
-// matchDirectoryPattern matches directory patterns (ending with /)
-func (i *Ignorer) matchDirectoryPattern(path, pattern string) bool {
-	// Normalize separators
-	normalizedPath := filepath.ToSlash(path)
-	normalizedPattern := filepath.ToSlash(pattern)
-
-	// If pattern contains /, it's a multi-component directory pattern
-	if strings.Contains(normalizedPattern, "/") {
-		// Check if path starts with the pattern or contains it as a directory segment
-		if strings.HasPrefix(normalizedPath, normalizedPattern+"/") ||
-			normalizedPath == normalizedPattern ||
-			strings.Contains(normalizedPath, "/"+normalizedPattern+"/") ||
-			strings.HasSuffix(normalizedPath, "/"+normalizedPattern) {
-			return true
-		}
-		// Also check if path starts with the pattern (for content inside)
-		if strings.HasPrefix(normalizedPath, normalizedPattern) {
-			return true
-		}
-		return false
-	}
-
-	// Single component directory pattern - check if it appears as a path component
-	parts := strings.Split(normalizedPath, "/")
-	for _, part := range parts {
-		if part == pattern {
-			return true
-		}
-	}
-
-	return false
-}
-
-// matchGlobPattern matches simple glob patterns like *.log
-func (i *Ignorer) matchGlobPattern(path, pattern string) bool {
-	// Check against basename
-	base := filepath.Base(path)
-	matched, _ := filepath.Match(pattern, base)
-	if matched {
-		return true
-	}
-
-	// Also check each path component
-	parts := strings.Split(filepath.ToSlash(path), "/")
-	for _, part := range parts {
-		matched, _ := filepath.Match(pattern, part)
-		if matched {
-			return true
-		}
-	}
-
-	return false
-}
-
-// matchPathPattern matches patterns containing /
-func (i *Ignorer) matchPathPattern(path, pattern string) bool {
-	// Normalize separators
-	normalizedPath := filepath.ToSlash(path)
-	normalizedPattern := filepath.ToSlash(pattern)
-
-	// Check if path starts with or contains the pattern
-	if strings.HasPrefix(normalizedPath, normalizedPattern) {
-		return true
-	}
-	if strings.Contains(normalizedPath, "/"+normalizedPattern) {
-		return true
-	}
-
-	return false
-}
-
-// matchExactPattern matches exact filename patterns
-func (i *Ignorer) matchExactPattern(path, pattern string) bool {
-	// Check against basename
-	base := filepath.Base(path)
-	return base == pattern
-}
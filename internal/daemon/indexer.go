@@ -18,6 +18,7 @@ import (
 	"github.com/pommel-dev/pommel/internal/db"
 	"github.com/pommel-dev/pommel/internal/embedder"
 	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/pommel-dev/pommel/internal/pathutil"
 )
 
 // IndexStats contains statistics about the indexer state
@@ -27,11 +28,18 @@ type IndexStats struct {
 	LastIndexedAt  time.Time
 	PendingFiles   int64
 	IndexingActive bool
+	Reconciling    bool // Startup reconciliation in progress
 
 	// Progress tracking for ongoing indexing operations
 	FilesToProcess  int64     // Total files discovered during scan
 	FilesProcessed  int64     // Files completed so far
 	IndexingStarted time.Time // When current indexing operation began
+
+	// Embedding reuse since the daemon started
+	ChunksEmbedded int64 // Chunks sent to the embedder
+	ChunksReused   int64 // Chunks whose unchanged content kept its stored embedding
+
+	FailedFiles int64 // Files whose last update failed and are waiting to be retried
 }
 
 // Indexer manages the indexing of source files
@@ -45,6 +53,21 @@ type Indexer struct {
 	stats       IndexStats
 	statsMu     sync.RWMutex
 	indexing    atomic.Bool
+
+	chunksEmbedded atomic.Int64
+	chunksReused   atomic.Int64
+
+	// staleEmbeddings is set while stored embeddings were made with a
+	// different document prefix, so none of them may be reused.
+	staleEmbeddings atomic.Bool
+
+	failed   map[string]*FailedFile // Files waiting to be retried, by path
+	failedMu sync.Mutex
+
+	subprojects   []*models.Subproject // Known sub-projects, refreshed by SyncSubprojects
+	subprojectsMu sync.RWMutex
+
+	testPatterns []pathutil.Pattern // Configured test file patterns
 }
 
 // NewIndexer creates a new Indexer instance
@@ -55,13 +78,15 @@ func NewIndexer(projectRoot string, cfg *config.Config, database *db.DB, emb emb
 	}
 
 	indexer := &Indexer{
-		projectRoot: projectRoot,
-		config:      cfg,
-		db:          database,
-		embedder:    emb,
-		chunker:     registry,
-		logger:      logger,
-		stats:       IndexStats{},
+		projectRoot:  projectRoot,
+		config:       cfg,
+		db:           database,
+		embedder:     emb,
+		chunker:      registry,
+		logger:       logger,
+		stats:        IndexStats{},
+		failed:       make(map[string]*FailedFile),
+		testPatterns: pathutil.ParsePatterns(cfg.TestPatterns),
 	}
 
 	// Load initial counts from database (without updating LastIndexedAt)
@@ -71,6 +96,19 @@ func NewIndexer(projectRoot string, cfg *config.Config, database *db.DB, emb emb
 	return indexer, nil
 }
 
+// LanguageForExtension returns the language indexed for files with the given
+// extension (e.g., ".ts").
+func (i *Indexer) LanguageForExtension(ext string) (string, bool) {
+	lang, ok := i.chunker.GetLanguageForExtension(ext)
+	return string(lang), ok
+}
+
+// Imports returns the import block of a source file, parsed with the
+// tree-sitter grammar of its language, or nil if it has none.
+func (i *Indexer) Imports(ctx context.Context, path string, content []byte) (*chunker.ImportBlock, error) {
+	return i.chunker.ExtractImports(ctx, path, content)
+}
+
 // IndexFile indexes a single file
 func (i *Indexer) IndexFile(ctx context.Context, path string) error {
 	// Check context early
@@ -84,6 +122,20 @@ func (i *Indexer) IndexFile(ctx context.Context, path string) error {
 	i.indexing.Store(true)
 	defer i.indexing.Store(false)
 
+	if err := i.indexFile(ctx, path); err != nil {
+		i.recordFailure(path, err)
+		return err
+	}
+	i.clearFailure(path)
+
+	// Update stats
+	i.updateStats(ctx)
+
+	return nil
+}
+
+// indexFile indexes a single file without touching the indexing flag or stats
+func (i *Indexer) indexFile(ctx context.Context, path string) error {
 	// Check if file matches patterns
 	relPath, err := filepath.Rel(i.projectRoot, path)
 	if err != nil {
@@ -100,28 +152,86 @@ func (i *Indexer) IndexFile(ctx context.Context, path string) error {
 		return fmt.Errorf("failed to stat file: %w", err)
 	}
 
+	file, err := i.prepareFile(ctx, path, info)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return nil
+	}
+
+	// Check context before embedding
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	// Only embed chunks whose content changed since the file was last indexed
+	embeddings, missing := i.reuseEmbeddings(ctx, file)
+	if len(missing) > 0 {
+		texts := make([]string, len(missing))
+		for idx, chunkIdx := range missing {
+			texts[idx] = file.chunks[chunkIdx].Content
+		}
+
+		embedded, err := i.embedder.EmbedDocuments(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to generate embeddings: %w", err)
+		}
+		if len(embedded) != len(texts) {
+			return fmt.Errorf("embedding count mismatch: got %d, expected %d", len(embedded), len(texts))
+		}
+		for idx, chunkIdx := range missing {
+			embeddings[chunkIdx] = embedded[idx]
+		}
+	}
+
+	if err := i.storeFile(ctx, file, embeddings); err != nil {
+		return err
+	}
+
+	i.recordChunkCounts(file.path, len(file.chunks)-len(missing), len(missing))
+	return nil
+}
+
+// preparedFile is a source file that has been read and chunked and is
+// ready to be embedded and stored.
+type preparedFile struct {
+	path        string
+	contentHash string
+	language    string
+	size        int64
+	modTime     time.Time
+	isTest      bool
+	chunks      []*models.Chunk
+}
+
+// prepareFile reads and chunks a file.
+// Returns nil without an error if the file should be skipped.
+func (i *Indexer) prepareFile(ctx context.Context, path string, info os.FileInfo) (*preparedFile, error) {
 	// Check file size limit
 	if i.config.Watcher.MaxFileSize > 0 && info.Size() > i.config.Watcher.MaxFileSize {
 		i.logger.Debug("skipping file - exceeds max size", "path", path, "size", info.Size(), "maxSize", i.config.Watcher.MaxFileSize)
-		return nil
+		return nil, nil
 	}
 
 	// Read file content (with retry for locked files on Windows)
 	content, err := ReadFileWithRetry(path, 3)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Skip empty files
 	if len(content) == 0 {
 		i.logger.Debug("skipping file - empty", "path", path)
-		return nil
+		return nil, nil
 	}
 
 	// Check context again before chunking
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return nil, ctx.Err()
 	default:
 	}
 
@@ -139,7 +249,7 @@ func (i *Indexer) IndexFile(ctx context.Context, path string) error {
 	// Chunk the file
 	result, err := i.chunker.Chunk(ctx, sourceFile)
 	if err != nil {
-		return fmt.Errorf("failed to chunk file: %w", err)
+		return nil, fmt.Errorf("failed to chunk file: %w", err)
 	}
 
 	// Handle chunking errors (but continue with available chunks)
@@ -150,66 +260,89 @@ func (i *Indexer) IndexFile(ctx context.Context, path string) error {
 	// Skip if no chunks
 	if len(result.Chunks) == 0 {
 		i.logger.Debug("skipping file - no chunks", "path", path)
-		return nil
-	}
-
-	// Delete existing chunks and embeddings for this file
-	if err := i.deleteFileData(ctx, path); err != nil {
-		return fmt.Errorf("failed to delete existing data: %w", err)
-	}
-
-	// Insert file record
-	fileID, err := i.db.InsertFile(ctx, path, contentHash, result.File.Language, info.Size(), info.ModTime())
-	if err != nil {
-		return fmt.Errorf("failed to insert file: %w", err)
-	}
-
-	// Check context before processing chunks
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
+		return nil, nil
 	}
 
-	// Prepare chunks for insertion and embedding
-	chunkIDs := make([]string, len(result.Chunks))
-	chunkContents := make([]string, len(result.Chunks))
-
-	for idx, chunk := range result.Chunks {
-		// Set hashes if not already set
+	// Set hashes if not already set, and tag chunks with their sub-project
+	sp := i.subprojectFor(path)
+	for _, chunk := range result.Chunks {
 		if chunk.ID == "" {
 			chunk.SetHashes()
 		}
+		chunk.SetSubproject(sp)
+	}
+
+	return &preparedFile{
+		path:        path,
+		contentHash: contentHash,
+		language:    result.File.Language,
+		size:        info.Size(),
+		modTime:     info.ModTime(),
+		isTest:      i.IsTestFile(path),
+		chunks:      result.Chunks,
+	}, nil
+}
 
-		// Insert chunk
-		if err := i.db.InsertChunk(ctx, chunk, fileID); err != nil {
-			return fmt.Errorf("failed to insert chunk: %w", err)
+// reuseEmbeddings looks up the embeddings stored for the file's previous
+// version and carries them over to chunks with identical content, wherever
+// those chunks moved. It returns the embeddings (nil where missing) and the
+// indexes of chunks that still need embedding.
+func (i *Indexer) reuseEmbeddings(ctx context.Context, file *preparedFile) ([][]float32, []int) {
+	embeddings := make([][]float32, len(file.chunks))
+	if i.staleEmbeddings.Load() {
+		missing := make([]int, len(file.chunks))
+		for idx := range missing {
+			missing[idx] = idx
 		}
-
-		chunkIDs[idx] = chunk.ID
-		chunkContents[idx] = chunk.Content
+		return embeddings, missing
 	}
 
-	// Check context before embedding
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-	}
-
-	// Generate embeddings
-	embeddings, err := i.embedder.Embed(ctx, chunkContents)
+	existing, err := i.db.GetFileEmbeddingsByContentHash(ctx, file.path)
 	if err != nil {
-		return fmt.Errorf("failed to generate embeddings: %w", err)
+		i.logger.Warn("failed to load existing embeddings, embedding all chunks", "path", file.path, "error", err)
+		existing = nil
 	}
 
-	// Insert embeddings
-	if err := i.db.InsertEmbeddings(ctx, chunkIDs, embeddings); err != nil {
-		return fmt.Errorf("failed to insert embeddings: %w", err)
+	missing := make([]int, 0, len(file.chunks))
+	for idx, chunk := range file.chunks {
+		if embedding, ok := existing[chunk.ContentHash]; ok {
+			embeddings[idx] = embedding
+			continue
+		}
+		missing = append(missing, idx)
 	}
 
-	// Update stats
-	i.updateStats(ctx)
+	return embeddings, missing
+}
+
+// recordChunkCounts records how many of a file's chunks were reused and embedded
+func (i *Indexer) recordChunkCounts(path string, reused, embedded int) {
+	i.chunksReused.Add(int64(reused))
+	i.chunksEmbedded.Add(int64(embedded))
+	i.logger.Debug("indexed file", "path", path, "reused", reused, "embedded", embedded)
+}
+
+// storeFile replaces any indexed data for a file with its prepared chunks
+// and their embeddings in a single transaction, so a failure leaves the
+// previous version of the file searchable.
+func (i *Indexer) storeFile(ctx context.Context, file *preparedFile, embeddings [][]float32) error {
+	if len(embeddings) != len(file.chunks) {
+		return fmt.Errorf("embedding count mismatch: got %d, expected %d", len(embeddings), len(file.chunks))
+	}
+
+	_, err := i.db.ReplaceFile(ctx, &db.FileUpdate{
+		Path:        file.path,
+		ContentHash: file.contentHash,
+		Language:    file.language,
+		Size:        file.size,
+		ModifiedAt:  file.modTime,
+		IsTest:      file.isTest,
+		Chunks:      file.chunks,
+		Embeddings:  embeddings,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store file: %w", err)
+	}
 
 	return nil
 }
@@ -219,40 +352,25 @@ func (i *Indexer) DeleteFile(ctx context.Context, path string) error {
 	if err := i.deleteFileData(ctx, path); err != nil {
 		return err
 	}
+	i.clearFailure(path)
 	// Update stats after deletion
 	i.updateStats(ctx)
 	return nil
 }
 
-// deleteFileData removes all data associated with a file path
+// deleteFileData removes a file with its chunks and embeddings in a single transaction
 func (i *Indexer) deleteFileData(ctx context.Context, path string) error {
-	// Get chunk IDs for this file
-	chunkIDs, err := i.db.GetChunkIDsByFile(ctx, path)
-	if err != nil {
-		return fmt.Errorf("failed to get chunk IDs: %w", err)
-	}
-
-	// Delete embeddings
-	if len(chunkIDs) > 0 {
-		if err := i.db.DeleteEmbeddingsByChunkIDs(ctx, chunkIDs); err != nil {
-			return fmt.Errorf("failed to delete embeddings: %w", err)
-		}
-	}
-
-	// Delete chunks
-	if err := i.db.DeleteChunksByFile(ctx, path); err != nil {
-		return fmt.Errorf("failed to delete chunks: %w", err)
-	}
-
-	// Delete file record
 	if err := i.db.DeleteFileByPath(ctx, path); err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
-
 	return nil
 }
 
-// ReindexAll clears all data and re-indexes all matching files
+// ReindexAll re-indexes all matching files and removes files that no longer
+// match. Each file's data is replaced only once its new embeddings are ready,
+// so a failing embedder leaves the existing index in place. Stored data is
+// cleared up front only when the embedding scheme or provider changed, since
+// old and new embeddings can't be mixed.
 func (i *Indexer) ReindexAll(ctx context.Context) error {
 	// Check context early
 	select {
@@ -265,14 +383,19 @@ func (i *Indexer) ReindexAll(ctx context.Context) error {
 	i.indexing.Store(true)
 	defer i.indexing.Store(false)
 
-	// Clear all data
-	if err := i.db.ClearAll(ctx); err != nil {
-		return fmt.Errorf("failed to clear database: %w", err)
+	changed, err := i.EmbeddingSchemeChanged(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check embedding scheme: %w", err)
+	}
+	if changed {
+		if err := i.db.ClearAll(ctx); err != nil {
+			return fmt.Errorf("failed to clear database: %w", err)
+		}
 	}
 
 	// Phase 1: Discovery - count files to process
 	var filesToProcess []string
-	err := filepath.Walk(i.projectRoot, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(i.projectRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			i.logger.Warn("error accessing path", "path", path, "error", err)
 			return nil // Continue walking
@@ -323,31 +446,27 @@ func (i *Indexer) ReindexAll(ctx context.Context) error {
 	}
 	i.statsMu.Unlock()
 
-	// Phase 2: Index each file with progress tracking
-	for _, path := range filesToProcess {
-		// Check context
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+	// Phase 2: Index files through the concurrent pipeline with progress tracking
+	if err := i.newIndexPipeline(i.incrementProcessed).run(ctx, filesToProcess); err != nil {
+		return err
+	}
 
-		// Get file info
-		info, err := os.Stat(path)
-		if err != nil {
-			i.logger.Warn("failed to stat file", "path", path, "error", err)
-			// Still increment processed count
-			i.incrementProcessed()
-			continue
-		}
+	if err := i.removeStaleFiles(ctx, filesToProcess); err != nil {
+		return err
+	}
 
-		// Index the file
-		if err := i.indexFileInternal(ctx, path, info); err != nil {
-			i.logger.Warn("failed to index file", "path", path, "error", err)
-		}
+	// Every stored embedding now uses the embedder's scheme
+	if err := i.db.SetDocumentPrefix(ctx, embedder.DocumentPrefix(i.embedder)); err != nil {
+		return fmt.Errorf("failed to record document prefix: %w", err)
+	}
+	if err := i.db.SetProviderInfo(ctx, i.providerInfo()); err != nil {
+		return fmt.Errorf("failed to record embedding provider: %w", err)
+	}
+	i.staleEmbeddings.Store(false)
 
-		// Update progress
-		i.incrementProcessed()
+	// Keyword entries left behind by earlier indexes have no file to replace them
+	if _, err := i.RepairFTS(ctx, false); err != nil {
+		i.logger.Warn("failed to repair FTS index", "error", err)
 	}
 
 	// Get final counts from database
@@ -376,91 +495,176 @@ func (i *Indexer) ReindexAll(ctx context.Context) error {
 	return nil
 }
 
-// incrementProcessed safely increments the files processed counter
-func (i *Indexer) incrementProcessed() {
-	i.statsMu.Lock()
-	i.stats.FilesProcessed++
-	i.statsMu.Unlock()
-}
-
-// indexFileInternal is the internal implementation of IndexFile for use during ReindexAll
-func (i *Indexer) indexFileInternal(ctx context.Context, path string, info os.FileInfo) error {
-	// Check file size limit
-	if i.config.Watcher.MaxFileSize > 0 && info.Size() > i.config.Watcher.MaxFileSize {
-		i.logger.Debug("skipping file - exceeds max size", "path", path, "size", info.Size())
-		return nil
+// removeStaleFiles deletes indexed files that are not among the given paths,
+// such as files removed from disk or no longer matching the include patterns.
+func (i *Indexer) removeStaleFiles(ctx context.Context, paths []string) error {
+	current := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		current[path] = struct{}{}
 	}
 
-	// Read file content (with retry for locked files on Windows)
-	content, err := ReadFileWithRetry(path, 3)
+	indexed, err := i.db.ListFiles(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return fmt.Errorf("failed to list indexed files: %w", err)
 	}
-
-	// Skip empty files
-	if len(content) == 0 {
-		return nil
+	for _, file := range indexed {
+		if _, ok := current[file.Path]; ok {
+			continue
+		}
+		if err := i.deleteFileData(ctx, file.Path); err != nil {
+			return err
+		}
+		i.clearFailure(file.Path)
 	}
+	return nil
+}
 
-	// Generate content hash
-	hash := sha256.Sum256(content)
-	contentHash := hex.EncodeToString(hash[:])
-
-	// Create source file for chunking
-	sourceFile := &models.SourceFile{
-		Path:         path,
-		Content:      content,
-		LastModified: info.ModTime(),
+// providerInfo describes the provider and model the embedder uses.
+func (i *Indexer) providerInfo() db.ProviderInfo {
+	provider := i.config.Embedding.Provider
+	if provider == "" {
+		provider = "ollama"
+	}
+	return db.ProviderInfo{
+		Provider:   provider,
+		Model:      i.embedder.ModelName(),
+		Dimensions: i.db.Dimensions(),
 	}
+}
 
-	// Chunk the file
-	result, err := i.chunker.Chunk(ctx, sourceFile)
+// EmbeddingSchemeChanged reports whether the stored embeddings were made by a
+// different provider or model, or with a different document prefix than the
+// embedder uses, as when a model gained task prefixes after the index was
+// built. Such embeddings don't match new queries well, so the index needs a
+// full re-index; until then no stored embedding is reused.
+func (i *Indexer) EmbeddingSchemeChanged(ctx context.Context) (bool, error) {
+	info := i.providerInfo()
+	changed, err := i.db.ProviderChanged(ctx, info.Provider, info.Model)
 	if err != nil {
-		return fmt.Errorf("failed to chunk file: %w", err)
+		return false, err
 	}
+	if !changed {
+		stored, err := i.db.GetDocumentPrefix(ctx)
+		if err != nil {
+			return false, err
+		}
+		changed = stored != embedder.DocumentPrefix(i.embedder)
+	}
+	if changed {
+		i.staleEmbeddings.Store(true)
+	}
+	return changed, nil
+}
 
-	// Skip if no chunks
-	if len(result.Chunks) == 0 {
+// Reconcile applies the changes found by a startup scan to the index.
+// Deleted files are removed and added or modified files are re-indexed;
+// PendingFiles counts down as changes are processed. Failures on individual
+// files are logged and do not stop reconciliation.
+func (i *Indexer) Reconcile(ctx context.Context, changes *ScanResult) error {
+	total := changes.TotalChanges()
+	if total == 0 {
 		return nil
 	}
 
-	// Insert file record
-	fileID, err := i.db.InsertFile(ctx, path, contentHash, result.File.Language, info.Size(), info.ModTime())
-	if err != nil {
-		return fmt.Errorf("failed to insert file: %w", err)
-	}
+	// Set indexing active
+	i.indexing.Store(true)
+	defer i.indexing.Store(false)
 
-	// Prepare chunks for insertion and embedding
-	chunkIDs := make([]string, len(result.Chunks))
-	chunkContents := make([]string, len(result.Chunks))
+	i.statsMu.Lock()
+	i.stats.Reconciling = true
+	i.stats.PendingFiles = int64(total)
+	i.stats.FilesToProcess = int64(total)
+	i.stats.FilesProcessed = 0
+	i.stats.IndexingStarted = time.Now()
+	i.statsMu.Unlock()
 
-	for idx, chunk := range result.Chunks {
-		// Set hashes if not already set
-		if chunk.ID == "" {
-			chunk.SetHashes()
+	defer func() {
+		i.statsMu.Lock()
+		i.stats.Reconciling = false
+		i.stats.PendingFiles = 0
+		i.stats.FilesToProcess = 0 // Reset progress fields
+		i.stats.FilesProcessed = 0
+		i.stats.IndexingStarted = time.Time{}
+		i.statsMu.Unlock()
+	}()
+
+	// Deleted paths are reported as stored in the database
+	for _, path := range changes.Deleted {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-
-		// Insert chunk
-		if err := i.db.InsertChunk(ctx, chunk, fileID); err != nil {
-			return fmt.Errorf("failed to insert chunk: %w", err)
+		if err := i.deleteFileData(ctx, path); err != nil {
+			i.logger.Warn("failed to remove deleted file from index", "path", path, "error", err)
 		}
+		i.clearFailure(path)
+		i.completePending()
+	}
 
-		chunkIDs[idx] = chunk.ID
-		chunkContents[idx] = chunk.Content
+	// Added and modified paths are relative to the project root
+	changed := make([]string, 0, len(changes.Added)+len(changes.Modified))
+	for _, paths := range [][]string{changes.Added, changes.Modified} {
+		for _, relPath := range paths {
+			changed = append(changed, filepath.Join(i.projectRoot, relPath))
+		}
+	}
+	if err := i.newIndexPipeline(i.completePending).run(ctx, changed); err != nil {
+		return err
 	}
 
-	// Generate embeddings
-	embeddings, err := i.embedder.Embed(ctx, chunkContents)
+	i.updateStats(ctx)
+
+	return nil
+}
+
+// completePending records one processed reconciliation change
+func (i *Indexer) completePending() {
+	i.statsMu.Lock()
+	i.stats.FilesProcessed++
+	i.stats.PendingFiles--
+	i.statsMu.Unlock()
+}
+
+// FTSRepairResult describes the outcome of a keyword index repair.
+type FTSRepairResult struct {
+	Drift   *db.FTSDriftReport // Drift detected before the repair
+	Rebuilt bool               // Whether the FTS index was rebuilt
+	Entries int                // Number of FTS entries after a rebuild
+}
+
+// RepairFTS checks the FTS keyword index against the chunks table and rebuilds
+// it when they have drifted apart. With force, the index is rebuilt regardless.
+func (i *Indexer) RepairFTS(ctx context.Context, force bool) (*FTSRepairResult, error) {
+	drift, err := i.db.CheckFTSDrift(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to generate embeddings: %w", err)
+		return nil, fmt.Errorf("failed to check FTS drift: %w", err)
 	}
 
-	// Insert embeddings
-	if err := i.db.InsertEmbeddings(ctx, chunkIDs, embeddings); err != nil {
-		return fmt.Errorf("failed to insert embeddings: %w", err)
+	result := &FTSRepairResult{Drift: drift}
+	if !force && !drift.HasDrift() {
+		return result, nil
 	}
 
-	return nil
+	i.logger.Info("rebuilding FTS index",
+		"missing", drift.Missing,
+		"orphaned", drift.Orphaned,
+		"stale", drift.Stale,
+		"duplicates", drift.Duplicates)
+
+	entries, err := i.db.PopulateFTSFromChunks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild FTS index: %w", err)
+	}
+
+	result.Rebuilt = true
+	result.Entries = entries
+	return result, nil
+}
+
+// incrementProcessed safely increments the files processed counter
+func (i *Indexer) incrementProcessed() {
+	i.statsMu.Lock()
+	i.stats.FilesProcessed++
+	i.statsMu.Unlock()
 }
 
 // Stats returns the current indexer statistics
@@ -470,6 +674,12 @@ func (i *Indexer) Stats() IndexStats {
 
 	stats := i.stats
 	stats.IndexingActive = i.indexing.Load()
+	stats.ChunksEmbedded = i.chunksEmbedded.Load()
+	stats.ChunksReused = i.chunksReused.Load()
+
+	i.failedMu.Lock()
+	stats.FailedFiles = int64(len(i.failed))
+	i.failedMu.Unlock()
 	return stats
 }
 
@@ -510,6 +720,34 @@ func (i *Indexer) updateStats(ctx context.Context) {
 	i.statsMu.Unlock()
 }
 
+// IsTestFile reports whether a file holds tests, by the conventions of its
+// language or the configured test patterns. The path may be absolute or
+// relative to the project root.
+func (i *Indexer) IsTestFile(path string) bool {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(i.projectRoot, path); err == nil {
+			path = rel
+		}
+	}
+	path = filepath.ToSlash(path)
+	return i.chunker.IsTestFile(path) || pathutil.MatchPatterns(i.testPatterns, path)
+}
+
+// SyncTestFiles re-flags every indexed file as a test file or not, so that
+// changed test patterns, and files indexed before files were flagged, take
+// effect without re-indexing.
+func (i *Indexer) SyncTestFiles(ctx context.Context) error {
+	files, err := i.db.ListFiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	flags := make(map[string]bool, len(files))
+	for _, file := range files {
+		flags[file.Path] = i.IsTestFile(file.Path)
+	}
+	return i.db.SetTestFiles(ctx, flags)
+}
+
 // MatchesPatterns checks if a file path matches the include patterns and doesn't match exclude patterns
 func (i *Indexer) MatchesPatterns(path string) bool {
 	// Normalize path separators
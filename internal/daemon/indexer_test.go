@@ -88,6 +88,22 @@ func TestNewIndexerCreatesSuccessfully(t *testing.T) {
 	require.NotNil(t, indexer)
 }
 
+func TestIndexerLanguageForExtension(t *testing.T) {
+	tmpDir := t.TempDir()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, testConfig(), database, embedder.NewMockEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	lang, ok := indexer.LanguageForExtension(".ts")
+	assert.True(t, ok)
+	assert.Equal(t, "typescript", lang)
+
+	_, ok = indexer.LanguageForExtension(".unknown")
+	assert.False(t, ok)
+}
+
 // TestNewIndexerWithValidDependencies verifies that NewIndexer works with valid dependencies
 func TestNewIndexerWithValidDependencies(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -453,8 +469,8 @@ func two() {}
 	assert.Greater(t, stats.TotalFiles, int64(1), "expected multiple files to be indexed")
 }
 
-// TestReindexAllClearsExistingDataFirst verifies that ReindexAll clears existing data
-func TestReindexAllClearsExistingDataFirst(t *testing.T) {
+// TestReindexAllRemovesStaleFiles verifies that ReindexAll drops files no longer on disk
+func TestReindexAllRemovesStaleFiles(t *testing.T) {
 	tmpDir := t.TempDir()
 	cfg := testConfig()
 	database := setupTestDB(t, tmpDir)
@@ -493,10 +509,13 @@ func replacement() {}
 	err = indexer.ReindexAll(ctx)
 	require.NoError(t, err)
 
-	// The old file's data should be cleared, only new file should exist
+	// The old file's data should be removed, only new file should exist
 	stats := indexer.Stats()
 	// We should only have the replacement file
 	assert.Equal(t, int64(1), stats.TotalFiles, "expected only one file after reindex")
+	chunks, err := database.GetChunkIDsByFile(ctx, testFile)
+	require.NoError(t, err)
+	assert.Empty(t, chunks, "removed file's chunks should be deleted")
 }
 
 // TestReindexAllUpdatesTotalFileCount verifies that ReindexAll updates total file count
@@ -1204,3 +1223,276 @@ func TestDeleteFile_WithNoChunks(t *testing.T) {
 	// Should not error when deleting non-existent data
 	require.NoError(t, err)
 }
+
+// =============================================================================
+// FTS Sync Tests
+// =============================================================================
+
+// TestIndexFileKeepsFTSInSync verifies that indexing, re-indexing and deleting
+// a file are mirrored into the keyword index
+func TestIndexFileKeepsFTSInSync(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, cfg, database, embedder.NewMockEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	testFile := createTestFile(t, tmpDir, "auth.go", "package main\n\nfunc authenticateUser() {}\n")
+
+	require.NoError(t, indexer.IndexFile(ctx, testFile))
+	results, err := database.FTSSearch(ctx, "authenticateUser", 10)
+	require.NoError(t, err)
+	assert.NotEmpty(t, results, "indexed chunks should be keyword searchable")
+
+	// Modify the file and re-index
+	createTestFile(t, tmpDir, "auth.go", "package main\n\nfunc authorizeRequest() {}\n")
+	require.NoError(t, indexer.IndexFile(ctx, testFile))
+
+	results, err = database.FTSSearch(ctx, "authenticateUser", 10)
+	require.NoError(t, err)
+	assert.Empty(t, results, "old content should be removed from the keyword index")
+
+	drift, err := database.CheckFTSDrift(ctx)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift(), "keyword index should match chunks: %+v", drift)
+
+	// Delete the file
+	require.NoError(t, indexer.DeleteFile(ctx, testFile))
+	results, err = database.FTSSearch(ctx, "authorizeRequest", 10)
+	require.NoError(t, err)
+	assert.Empty(t, results, "deleted chunks should be removed from the keyword index")
+}
+
+// TestReindexAllRebuildsFTS verifies that a full reindex leaves no stale keyword entries
+func TestReindexAllRebuildsFTS(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, cfg, database, embedder.NewMockEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	createTestFile(t, tmpDir, "main.go", "package main\n\nfunc main() {}\n")
+
+	// Stale entry left over from an earlier index
+	_, err = database.Exec(ctx, `INSERT INTO chunks_fts (chunk_id, content, name, file_path) VALUES ('old', 'leftover', '', '/old.go')`)
+	require.NoError(t, err)
+
+	require.NoError(t, indexer.ReindexAll(ctx))
+
+	drift, err := database.CheckFTSDrift(ctx)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift(), "keyword index should match chunks: %+v", drift)
+	assert.Greater(t, drift.FTSCount, 0)
+}
+
+// TestRepairFTS verifies drift detection and rebuilding of the keyword index
+func TestRepairFTS(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, cfg, database, embedder.NewMockEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	testFile := createTestFile(t, tmpDir, "main.go", "package main\n\nfunc main() {}\n")
+	require.NoError(t, indexer.IndexFile(ctx, testFile))
+
+	// In sync: nothing to rebuild
+	result, err := indexer.RepairFTS(ctx, false)
+	require.NoError(t, err)
+	assert.False(t, result.Rebuilt)
+	assert.False(t, result.Drift.HasDrift())
+
+	// Simulate drift
+	require.NoError(t, database.ClearFTS(ctx))
+
+	result, err = indexer.RepairFTS(ctx, false)
+	require.NoError(t, err)
+	assert.True(t, result.Rebuilt)
+	assert.Greater(t, result.Drift.Missing, 0)
+	assert.Equal(t, result.Drift.ChunkCount, result.Entries)
+
+	// Force rebuilds even when in sync
+	result, err = indexer.RepairFTS(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, result.Rebuilt)
+	assert.False(t, result.Drift.HasDrift())
+}
+
+// =============================================================================
+// Reconciliation Tests
+// =============================================================================
+
+// blockingEmbedder wraps an embedder and waits for release before embedding
+type blockingEmbedder struct {
+	embedder.Embedder
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-b.release
+	return b.Embedder.EmbedDocuments(ctx, texts)
+}
+
+// TestReconcileAppliesScanResult verifies that only the scanned changes are applied
+func TestReconcileAppliesScanResult(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, cfg, database, embedder.NewMockEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	keep := createTestFile(t, tmpDir, "keep.go", "package main\n\nfunc keep() {}\n")
+	gone := createTestFile(t, tmpDir, "gone.go", "package main\n\nfunc gone() {}\n")
+	require.NoError(t, indexer.IndexFile(ctx, keep))
+	require.NoError(t, indexer.IndexFile(ctx, gone))
+
+	createTestFile(t, tmpDir, "added.go", "package main\n\nfunc added() {}\n")
+
+	err = indexer.Reconcile(ctx, &ScanResult{
+		Added:   []string{"added.go"},
+		Deleted: []string{gone},
+	})
+	require.NoError(t, err)
+
+	files, err := database.ListFiles(ctx)
+	require.NoError(t, err)
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	assert.ElementsMatch(t, []string{keep, filepath.Join(tmpDir, "added.go")}, paths)
+
+	stats := indexer.Stats()
+	assert.Equal(t, int64(2), stats.TotalFiles)
+	assert.False(t, stats.Reconciling)
+	assert.False(t, stats.IndexingActive)
+	assert.Zero(t, stats.PendingFiles)
+}
+
+// TestReconcileReportsPendingChanges verifies stats while reconciliation is running
+func TestReconcileReportsPendingChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := &blockingEmbedder{
+		Embedder: embedder.NewMockEmbedder(),
+		started:  make(chan struct{}, 1),
+		release:  make(chan struct{}),
+	}
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	createTestFile(t, tmpDir, "a.go", "package main\n\nfunc a() {}\n")
+	createTestFile(t, tmpDir, "b.go", "package main\n\nfunc b() {}\n")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- indexer.Reconcile(context.Background(), &ScanResult{Added: []string{"a.go", "b.go"}})
+	}()
+
+	<-emb.started
+	stats := indexer.Stats()
+	assert.True(t, stats.Reconciling)
+	assert.True(t, stats.IndexingActive)
+	assert.Equal(t, int64(2), stats.PendingFiles)
+	assert.Equal(t, int64(2), stats.FilesToProcess)
+
+	close(emb.release)
+	require.NoError(t, <-done)
+	assert.False(t, indexer.Stats().Reconciling)
+}
+
+// TestReconcileNoChanges verifies that an empty scan result is a no-op
+func TestReconcileNoChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, testConfig(), database, embedder.NewMockEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, indexer.Reconcile(context.Background(), &ScanResult{}))
+	assert.True(t, indexer.Stats().LastIndexedAt.IsZero())
+}
+
+// fileIsTest reports the test flag stored on the first chunk of a file
+func fileIsTest(t *testing.T, database *db.DB, path string) bool {
+	t.Helper()
+	ctx := context.Background()
+	ids, err := database.GetChunkIDsByFile(ctx, path)
+	require.NoError(t, err)
+	require.NotEmpty(t, ids)
+	chunk, err := database.GetChunkByID(ctx, ids[0])
+	require.NoError(t, err)
+	return chunk.IsTest
+}
+
+// TestIndexFileFlagsTestFiles verifies that language conventions and configured
+// patterns mark files as tests at index time
+func TestIndexFileFlagsTestFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.TestPatterns = []string{"fixtures/"}
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, cfg, database, embedder.NewMockEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	source := createTestFile(t, tmpDir, "retry.go", "package main\n\nfunc retry() {}\n")
+	goTest := createTestFile(t, tmpDir, "retry_test.go", "package main\n\nfunc TestRetry() {}\n")
+	pyTest := createTestFile(t, tmpDir, "test_retry.py", "def test_retry():\n    pass\n")
+	fixture := createNestedFile(t, tmpDir, "fixtures/sample.go", "package fixtures\n\nfunc sample() {}\n")
+	for _, path := range []string{source, goTest, pyTest, fixture} {
+		require.NoError(t, indexer.IndexFile(ctx, path))
+	}
+
+	assert.False(t, fileIsTest(t, database, source))
+	assert.True(t, fileIsTest(t, database, goTest))
+	assert.True(t, fileIsTest(t, database, pyTest))
+	assert.True(t, fileIsTest(t, database, fixture))
+}
+
+// TestSyncTestFilesAppliesNewPatterns verifies that already indexed files are
+// re-flagged when the test patterns change
+func TestSyncTestFilesAppliesNewPatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, testConfig(), database, embedder.NewMockEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	fixture := createNestedFile(t, tmpDir, "fixtures/sample.go", "package fixtures\n\nfunc sample() {}\n")
+	require.NoError(t, indexer.IndexFile(ctx, fixture))
+	assert.False(t, fileIsTest(t, database, fixture))
+
+	cfg := testConfig()
+	cfg.TestPatterns = []string{"fixtures/"}
+	indexer, err = NewIndexer(tmpDir, cfg, database, embedder.NewMockEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, indexer.SyncTestFiles(ctx))
+	assert.True(t, fileIsTest(t, database, fixture))
+}
@@ -0,0 +1,306 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pommel-dev/pommel/internal/embedder"
+)
+
+const (
+	// defaultEmbedConcurrency is the number of embedding requests in flight
+	// when embedding.concurrency is not set.
+	defaultEmbedConcurrency = 2
+
+	// defaultPipelineBatchSize is used when embedding.batch_size is not set.
+	defaultPipelineBatchSize = 32
+)
+
+// indexPipeline indexes many files concurrently in stages:
+//
+//  1. reader workers read and chunk files in parallel
+//  2. a batcher packs chunks from many files into embedding batches,
+//     bounded by the batch size and token limit
+//  3. embed workers embed batches with bounded concurrency
+//  4. a single writer stores each file once all of its chunks are embedded
+//
+// Every stage stops when the context is cancelled.
+type indexPipeline struct {
+	indexer        *Indexer
+	readers        int
+	batchSize      int
+	maxBatchTokens int
+	concurrency    int
+	onFileDone     func() // Called once per input path, however it finished
+}
+
+// newIndexPipeline creates a pipeline using the indexer's embedding config.
+func (i *Indexer) newIndexPipeline(onFileDone func()) *indexPipeline {
+	p := &indexPipeline{
+		indexer:        i,
+		readers:        runtime.GOMAXPROCS(0),
+		batchSize:      i.config.Embedding.BatchSize,
+		maxBatchTokens: i.config.Embedding.MaxBatchTokens,
+		concurrency:    i.config.Embedding.Concurrency,
+		onFileDone:     onFileDone,
+	}
+	if p.batchSize <= 0 {
+		p.batchSize = defaultPipelineBatchSize
+	}
+	if p.concurrency <= 0 {
+		p.concurrency = defaultEmbedConcurrency
+	}
+	if p.onFileDone == nil {
+		p.onFileDone = func() {}
+	}
+	return p
+}
+
+// pipelineFile tracks a prepared file while its chunks are being embedded.
+type pipelineFile struct {
+	*preparedFile
+	path       string // Input path, set even if the file couldn't be prepared
+	embeddings [][]float32
+	missing    []int        // Indexes of chunks that need embedding
+	remaining  atomic.Int32 // Chunks still waiting for an embedding
+	failed     atomic.Bool
+	err        error // Set before the file reaches the writer
+}
+
+// chunkRef identifies one chunk of a file in the pipeline.
+type chunkRef struct {
+	file  *pipelineFile
+	index int
+}
+
+// run indexes the given absolute paths. Failures on individual files are
+// logged and do not stop the pipeline; only cancellation returns an error.
+func (p *indexPipeline) run(ctx context.Context, paths []string) error {
+	if len(paths) == 0 {
+		return ctx.Err()
+	}
+
+	pathCh := make(chan string)
+	preparedCh := make(chan *pipelineFile)
+	batchCh := make(chan []chunkRef)
+	doneCh := make(chan *pipelineFile)
+
+	// Feed paths to the readers
+	go func() {
+		defer close(pathCh)
+		for _, path := range paths {
+			select {
+			case pathCh <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Stage 1: read and chunk files in parallel
+	var readersWG sync.WaitGroup
+	for n := 0; n < p.readers; n++ {
+		readersWG.Add(1)
+		go func() {
+			defer readersWG.Done()
+			for path := range pathCh {
+				file := p.prepare(ctx, path)
+				out := preparedCh
+				if file.err != nil || file.preparedFile == nil || len(file.missing) == 0 {
+					// Nothing to embed; report straight to the writer
+					out = doneCh
+				}
+				select {
+				case out <- file:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		readersWG.Wait()
+		close(preparedCh)
+	}()
+
+	// Stage 2: pack chunks from many files into batches
+	go func() {
+		defer close(batchCh)
+		p.batch(ctx, preparedCh, batchCh)
+	}()
+
+	// Stage 3: embed batches with bounded concurrency
+	var embedWG sync.WaitGroup
+	for n := 0; n < p.concurrency; n++ {
+		embedWG.Add(1)
+		go func() {
+			defer embedWG.Done()
+			for batch := range batchCh {
+				p.embed(ctx, batch, doneCh)
+			}
+		}()
+	}
+
+	// The readers finish before the batcher closes batchCh, so once the
+	// embed workers are done nothing else sends to doneCh
+	go func() {
+		readersWG.Wait()
+		embedWG.Wait()
+		close(doneCh)
+	}()
+
+	// Stage 4: store completed files from a single writer
+	var reused, embedded int
+	for file := range doneCh {
+		if p.write(ctx, file) {
+			reused += len(file.chunks) - len(file.missing)
+			embedded += len(file.missing)
+		}
+		p.onFileDone()
+	}
+
+	p.indexer.logger.Info("indexed files", "files", len(paths), "chunks_reused", reused, "chunks_embedded", embedded)
+
+	// Files dropped on cancellation are never reported; the caller
+	// resets progress when the context is done
+	return ctx.Err()
+}
+
+// prepare reads and chunks a single file for the pipeline.
+func (p *indexPipeline) prepare(ctx context.Context, path string) *pipelineFile {
+	i := p.indexer
+	file := &pipelineFile{path: path}
+
+	relPath, err := filepath.Rel(i.projectRoot, path)
+	if err != nil {
+		relPath = path
+	}
+	if !i.MatchesPatterns(relPath) {
+		i.logger.Debug("skipping file - does not match patterns", "path", path)
+		return file
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		file.err = fmt.Errorf("failed to stat file %s: %w", path, err)
+		return file
+	}
+
+	prepared, err := i.prepareFile(ctx, path, info)
+	if err != nil {
+		file.err = fmt.Errorf("failed to prepare file %s: %w", path, err)
+		return file
+	}
+	if prepared == nil {
+		return file
+	}
+
+	file.preparedFile = prepared
+	file.embeddings, file.missing = i.reuseEmbeddings(ctx, prepared)
+	file.remaining.Store(int32(len(file.missing)))
+	return file
+}
+
+// batch packs chunks from prepared files into batches of at most batchSize
+// chunks and maxBatchTokens estimated tokens. A chunk larger than the token
+// limit is sent in a batch of its own.
+func (p *indexPipeline) batch(ctx context.Context, in <-chan *pipelineFile, out chan<- []chunkRef) {
+	var batch []chunkRef
+	tokens := 0
+
+	flush := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+		select {
+		case out <- batch:
+		case <-ctx.Done():
+			return false
+		}
+		batch = nil
+		tokens = 0
+		return true
+	}
+
+	for file := range in {
+		for _, idx := range file.missing {
+			chunk := file.chunks[idx]
+			chunkTokens := embedder.EstimateTokens(chunk.Content)
+			overTokens := p.maxBatchTokens > 0 && tokens+chunkTokens > p.maxBatchTokens
+			if len(batch) >= p.batchSize || (overTokens && len(batch) > 0) {
+				if !flush() {
+					return
+				}
+			}
+			batch = append(batch, chunkRef{file: file, index: idx})
+			tokens += chunkTokens
+		}
+	}
+
+	flush()
+}
+
+// embed embeds one batch and forwards files whose chunks are all embedded.
+func (p *indexPipeline) embed(ctx context.Context, batch []chunkRef, out chan<- *pipelineFile) {
+	texts := make([]string, len(batch))
+	for idx, ref := range batch {
+		texts[idx] = ref.file.chunks[ref.index].Content
+	}
+
+	embeddings, err := p.indexer.embedder.EmbedDocuments(ctx, texts)
+	if err == nil && len(embeddings) != len(texts) {
+		err = fmt.Errorf("embedding count mismatch: got %d, expected %d", len(embeddings), len(texts))
+	}
+
+	for idx, ref := range batch {
+		file := ref.file
+		if err != nil {
+			if file.failed.CompareAndSwap(false, true) {
+				file.err = fmt.Errorf("failed to generate embeddings for %s: %w", file.path, err)
+			}
+		} else {
+			file.embeddings[ref.index] = embeddings[idx]
+		}
+
+		if file.remaining.Add(-1) == 0 {
+			select {
+			case out <- file:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// write stores a completed file, logging and recording for retry files that
+// failed along the way. Returns true if the file was stored.
+func (p *indexPipeline) write(ctx context.Context, file *pipelineFile) bool {
+	i := p.indexer
+	if ctx.Err() != nil {
+		return false
+	}
+	if file.err != nil {
+		i.logger.Warn("failed to index file", "error", file.err)
+		i.recordFailure(file.path, file.err)
+		return false
+	}
+	if file.preparedFile == nil {
+		i.clearFailure(file.path)
+		return false
+	}
+
+	if err := i.storeFile(ctx, file.preparedFile, file.embeddings); err != nil {
+		i.logger.Warn("failed to index file", "path", file.path, "error", err)
+		i.recordFailure(file.path, err)
+		return false
+	}
+	i.clearFailure(file.path)
+
+	i.recordChunkCounts(file.path, len(file.chunks)-len(file.missing), len(file.missing))
+	return true
+}
@@ -0,0 +1,451 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEmbedder wraps the mock embedder and records each Embed call
+type recordingEmbedder struct {
+	embedder.Embedder
+	delay    time.Duration
+	failOn   string // Fail any batch containing this text
+	mu       sync.Mutex
+	batches  [][]string
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+}
+
+func newRecordingEmbedder() *recordingEmbedder {
+	return &recordingEmbedder{Embedder: embedder.NewMockEmbedder()}
+}
+
+func (r *recordingEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	current := r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+	for {
+		seen := r.maxSeen.Load()
+		if current <= seen || r.maxSeen.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+
+	r.mu.Lock()
+	r.batches = append(r.batches, append([]string(nil), texts...))
+	r.mu.Unlock()
+
+	if r.delay > 0 {
+		select {
+		case <-time.After(r.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for _, text := range texts {
+		if r.failOn != "" && strings.Contains(text, r.failOn) {
+			return nil, errors.New("embedding failed")
+		}
+	}
+	return r.Embedder.EmbedDocuments(ctx, texts)
+}
+
+func (r *recordingEmbedder) batchSizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, len(r.batches))
+	for idx, batch := range r.batches {
+		sizes[idx] = len(batch)
+	}
+	return sizes
+}
+
+// createPipelineFiles creates n single-function Go files
+func createPipelineFiles(t *testing.T, dir string, n int) {
+	for idx := 0; idx < n; idx++ {
+		createTestFile(t, dir, fmt.Sprintf("file%02d.go", idx),
+			fmt.Sprintf("package main\n\nfunc handler%02d() {\n\treturn\n}\n", idx))
+	}
+}
+
+func TestPipelineBatchesChunksAcrossFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Embedding.BatchSize = 4
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	createPipelineFiles(t, tmpDir, 10)
+
+	ctx := context.Background()
+	require.NoError(t, indexer.ReindexAll(ctx))
+
+	chunkCount, err := database.ChunkCount(ctx)
+	require.NoError(t, err)
+
+	// Chunks from many files share requests instead of one request per file
+	sizes := emb.batchSizes()
+	total := 0
+	for _, size := range sizes {
+		assert.LessOrEqual(t, size, 4)
+		total += size
+	}
+	assert.Equal(t, int(chunkCount), total)
+	assert.Equal(t, (total+3)/4, len(sizes), "batches should be packed full: %v", sizes)
+
+	stats := indexer.Stats()
+	assert.Equal(t, int64(10), stats.TotalFiles)
+	assert.Equal(t, chunkCount, stats.TotalChunks)
+	assert.Zero(t, stats.FilesProcessed)
+	assert.Zero(t, stats.FilesToProcess)
+}
+
+func TestPipelineRespectsTokenLimit(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Embedding.BatchSize = 100
+	cfg.Embedding.MaxBatchTokens = 20
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	createPipelineFiles(t, tmpDir, 6)
+	require.NoError(t, indexer.ReindexAll(context.Background()))
+
+	emb.mu.Lock()
+	defer emb.mu.Unlock()
+	require.Greater(t, len(emb.batches), 1)
+	for _, batch := range emb.batches {
+		if len(batch) == 1 {
+			continue // An oversized chunk is sent on its own
+		}
+		tokens := 0
+		for _, text := range batch {
+			tokens += embedder.EstimateTokens(text)
+		}
+		assert.LessOrEqual(t, tokens, 20)
+	}
+}
+
+func TestPipelineBoundsEmbeddingConcurrency(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Embedding.BatchSize = 1
+	cfg.Embedding.Concurrency = 3
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	emb.delay = 20 * time.Millisecond
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	createPipelineFiles(t, tmpDir, 12)
+	require.NoError(t, indexer.ReindexAll(context.Background()))
+
+	assert.LessOrEqual(t, emb.maxSeen.Load(), int32(3))
+	assert.Greater(t, emb.maxSeen.Load(), int32(1), "batches should be embedded concurrently")
+}
+
+func TestPipelineSkipsFilesInFailedBatches(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Embedding.BatchSize = 1
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	emb.failOn = "handler03"
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	createPipelineFiles(t, tmpDir, 5)
+	require.NoError(t, indexer.ReindexAll(context.Background()))
+
+	// The failed file is not stored half-indexed; the others are unaffected
+	ctx := context.Background()
+	files, err := database.ListFiles(ctx)
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+	for _, f := range files {
+		assert.NotContains(t, f.Path, "file03.go")
+	}
+}
+
+func TestPipelineCancelsCleanly(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Embedding.BatchSize = 1
+	cfg.Embedding.Concurrency = 2
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	emb.delay = 50 * time.Millisecond
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	createPipelineFiles(t, tmpDir, 40)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- indexer.ReindexAll(ctx)
+	}()
+
+	time.Sleep(80 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("ReindexAll did not return after cancellation")
+	}
+
+	// No embedding requests continue after ReindexAll returns
+	assert.Zero(t, emb.inFlight.Load())
+	assert.False(t, indexer.Stats().IndexingActive)
+	assert.Less(t, len(emb.batchSizes()), 40)
+}
+
+func TestIndexFileReusesEmbeddingsForUnchangedChunks(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	path := createTestFile(t, tmpDir, "service.go", `package main
+
+func keep() {
+	return
+}
+
+func change() {
+	return
+}
+`)
+	require.NoError(t, indexer.IndexFile(ctx, path))
+	first := indexer.Stats()
+	assert.Zero(t, first.ChunksReused)
+	require.Greater(t, first.ChunksEmbedded, int64(0))
+
+	before, err := database.GetFileEmbeddingsByContentHash(ctx, path)
+	require.NoError(t, err)
+
+	// Edit one function and move the unchanged one down
+	createTestFile(t, tmpDir, "service.go", `package main
+
+// change now does something
+func change() {
+	println("changed")
+}
+
+func keep() {
+	return
+}
+`)
+	emb.mu.Lock()
+	emb.batches = nil
+	emb.mu.Unlock()
+	require.NoError(t, indexer.IndexFile(ctx, path))
+
+	// The unchanged function kept its embedding; only new content was embedded
+	emb.mu.Lock()
+	var embeddedTexts []string
+	for _, batch := range emb.batches {
+		embeddedTexts = append(embeddedTexts, batch...)
+	}
+	emb.mu.Unlock()
+	for _, text := range embeddedTexts {
+		assert.NotEqual(t, "func keep() {\n\treturn\n}", text)
+	}
+
+	second := indexer.Stats()
+	assert.Greater(t, second.ChunksReused, int64(0))
+	assert.Equal(t, first.ChunksEmbedded+int64(len(embeddedTexts)), second.ChunksEmbedded)
+
+	after, err := database.GetFileEmbeddingsByContentHash(ctx, path)
+	require.NoError(t, err)
+	reusedCount := 0
+	for hash, vector := range after {
+		if old, ok := before[hash]; ok {
+			assert.Equal(t, old, vector)
+			reusedCount++
+		}
+	}
+	assert.Equal(t, int(second.ChunksReused), reusedCount)
+}
+
+func TestReconcileReusesEmbeddingsForUnchangedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	createPipelineFiles(t, tmpDir, 3)
+	ctx := context.Background()
+	require.NoError(t, indexer.ReindexAll(ctx))
+	embeddedBefore := indexer.Stats().ChunksEmbedded
+	callsBefore := len(emb.batchSizes())
+
+	// Touched but unchanged files need no embedding requests
+	err = indexer.Reconcile(ctx, &ScanResult{Modified: []string{"file00.go", "file01.go"}})
+	require.NoError(t, err)
+
+	stats := indexer.Stats()
+	assert.Equal(t, embeddedBefore, stats.ChunksEmbedded)
+	assert.Greater(t, stats.ChunksReused, int64(0))
+	assert.Equal(t, callsBefore, len(emb.batchSizes()))
+}
+
+// prefixedEmbedder reports a document prefix, as Jina v4 does
+type prefixedEmbedder struct {
+	*recordingEmbedder
+}
+
+func (e prefixedEmbedder) DocumentPrefix() string {
+	return "Passage: "
+}
+
+func TestEmbeddingSchemeChangeSkipsReuseUntilReindex(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	// Build the index without a prefix, as before prefixes were added
+	ctx := context.Background()
+	createPipelineFiles(t, tmpDir, 2)
+	old, err := NewIndexer(tmpDir, cfg, database, newRecordingEmbedder(), testLogger())
+	require.NoError(t, err)
+	require.NoError(t, old.ReindexAll(ctx))
+
+	indexer, err := NewIndexer(tmpDir, cfg, database, prefixedEmbedder{newRecordingEmbedder()}, testLogger())
+	require.NoError(t, err)
+	changed, err := indexer.EmbeddingSchemeChanged(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// Unchanged content must not keep its unprefixed embedding
+	require.NoError(t, indexer.Reconcile(ctx, &ScanResult{Modified: []string{"file00.go"}}))
+	stats := indexer.Stats()
+	assert.Zero(t, stats.ChunksReused)
+	assert.Greater(t, stats.ChunksEmbedded, int64(0))
+
+	require.NoError(t, indexer.ReindexAll(ctx))
+	prefix, err := database.GetDocumentPrefix(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Passage: ", prefix)
+
+	changed, err = indexer.EmbeddingSchemeChanged(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	require.NoError(t, indexer.Reconcile(ctx, &ScanResult{Modified: []string{"file00.go"}}))
+	assert.Greater(t, indexer.Stats().ChunksReused, int64(0))
+}
+
+// renamedEmbedder reports a different model than the embedder it wraps
+type renamedEmbedder struct {
+	*recordingEmbedder
+}
+
+func (e renamedEmbedder) ModelName() string {
+	return "other-model"
+}
+
+func TestReindexAllKeepsIndexWhenEmbeddingFails(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	ctx := context.Background()
+	createPipelineFiles(t, tmpDir, 2)
+	emb := newRecordingEmbedder()
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+	require.NoError(t, indexer.ReindexAll(ctx))
+	chunksBefore, err := database.ChunkCount(ctx)
+	require.NoError(t, err)
+
+	// The embedder goes down while a changed file is re-indexed
+	path := filepath.Join(tmpDir, "file00.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\nfunc Unreachable() {}\n"), 0644))
+	emb.failOn = "Unreachable"
+	require.NoError(t, indexer.ReindexAll(ctx))
+
+	files, err := database.FileCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), files)
+	chunks, err := database.ChunkCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, chunksBefore, chunks, "the failed file should keep its old chunks")
+	embeddings, err := database.EmbeddingCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, chunks, int64(embeddings))
+}
+
+func TestReindexAllClearsIndexWhenModelChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Embedding.BatchSize = 1
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	ctx := context.Background()
+	createPipelineFiles(t, tmpDir, 2)
+	old, err := NewIndexer(tmpDir, cfg, database, newRecordingEmbedder(), testLogger())
+	require.NoError(t, err)
+	require.NoError(t, old.ReindexAll(ctx))
+
+	// Embeddings from another model can't be kept, even for a file that fails
+	emb := renamedEmbedder{newRecordingEmbedder()}
+	emb.failOn = "handler01"
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+	changed, err := indexer.EmbeddingSchemeChanged(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	require.NoError(t, indexer.ReindexAll(ctx))
+	files, err := database.FileCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), files)
+	assert.Zero(t, indexer.Stats().ChunksReused)
+
+	info, err := database.GetProviderInfo(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "other-model", info.Model)
+	changed, err = indexer.EmbeddingSchemeChanged(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
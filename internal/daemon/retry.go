@@ -0,0 +1,115 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	// retryInterval is how often the daemon retries files that failed to index.
+	retryInterval = time.Minute
+
+	// maxIndexAttempts is the number of times a file is attempted before it
+	// is left alone until it changes again.
+	maxIndexAttempts = 5
+)
+
+// FailedFile records a file whose index update failed and is waiting to be retried.
+type FailedFile struct {
+	Path        string
+	Error       string
+	Attempts    int
+	LastAttempt time.Time
+}
+
+// recordFailure records a failed index update for path. Files that no longer
+// exist are not recorded, since the watcher or next scan removes them.
+func (i *Indexer) recordFailure(path string, err error) {
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	i.failedMu.Lock()
+	defer i.failedMu.Unlock()
+
+	failed, ok := i.failed[path]
+	if !ok {
+		failed = &FailedFile{Path: path}
+		i.failed[path] = failed
+	}
+	failed.Error = err.Error()
+	failed.Attempts++
+	failed.LastAttempt = time.Now()
+}
+
+// clearFailure forgets any recorded failure for path.
+func (i *Indexer) clearFailure(path string) {
+	i.failedMu.Lock()
+	delete(i.failed, path)
+	i.failedMu.Unlock()
+}
+
+// FailedFiles returns the files waiting to be retried, sorted by path.
+func (i *Indexer) FailedFiles() []FailedFile {
+	i.failedMu.Lock()
+	defer i.failedMu.Unlock()
+
+	files := make([]FailedFile, 0, len(i.failed))
+	for _, failed := range i.failed {
+		files = append(files, *failed)
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].Path < files[b].Path })
+	return files
+}
+
+// RetryFailed re-indexes files whose previous update failed. Files that have
+// used up their attempts stay recorded but are skipped until they change and
+// are indexed again. Returns the number of files that were indexed.
+func (i *Indexer) RetryFailed(ctx context.Context) int {
+	var paths []string
+	for _, failed := range i.FailedFiles() {
+		if failed.Attempts < maxIndexAttempts {
+			paths = append(paths, failed.Path)
+		}
+	}
+
+	indexed := 0
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			i.clearFailure(path)
+			continue
+		}
+		if err := i.IndexFile(ctx, path); err != nil {
+			i.logger.Warn("retry failed", "path", path, "error", err)
+			continue
+		}
+		indexed++
+	}
+
+	if indexed > 0 {
+		i.logger.Info("retried failed files", "indexed", indexed)
+	}
+	return indexed
+}
+
+// retryFailedFiles periodically retries files that failed to index until
+// the context is cancelled.
+func (d *Daemon) retryFailedFiles(ctx context.Context) {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.indexer.RetryFailed(ctx)
+		}
+	}
+}
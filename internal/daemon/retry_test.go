@@ -0,0 +1,142 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// indexedContent returns the content of every chunk stored for path
+func indexedContent(t *testing.T, indexer *Indexer, path string) string {
+	t.Helper()
+	ctx := context.Background()
+	ids, err := indexer.db.GetChunkIDsByFile(ctx, path)
+	require.NoError(t, err)
+	chunks, err := indexer.db.GetChunksByIDs(ctx, ids)
+	require.NoError(t, err)
+
+	var content strings.Builder
+	for _, chunk := range chunks {
+		content.WriteString(chunk.Content)
+	}
+	return content.String()
+}
+
+func TestIndexFileFailureKeepsPreviousVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	path := createTestFile(t, tmpDir, "service.go", "package main\n\nfunc alpha() {\n\treturn\n}\n")
+	require.NoError(t, indexer.IndexFile(ctx, path))
+
+	// The new version can't be embedded
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\nfunc broken() {\n\treturn\n}\n"), 0644))
+	emb.failOn = "broken"
+	require.Error(t, indexer.IndexFile(ctx, path))
+
+	content := indexedContent(t, indexer, path)
+	assert.Contains(t, content, "alpha", "previous version should stay searchable")
+	assert.NotContains(t, content, "broken")
+
+	failed := indexer.FailedFiles()
+	require.Len(t, failed, 1)
+	assert.Equal(t, path, failed[0].Path)
+	assert.Equal(t, 1, failed[0].Attempts)
+	assert.Contains(t, failed[0].Error, "embedding failed")
+	assert.Equal(t, int64(1), indexer.Stats().FailedFiles)
+
+	// Once the embedder recovers the retry picks up the new version
+	emb.failOn = ""
+	assert.Equal(t, 1, indexer.RetryFailed(ctx))
+	assert.Empty(t, indexer.FailedFiles())
+	assert.Zero(t, indexer.Stats().FailedFiles)
+
+	content = indexedContent(t, indexer, path)
+	assert.Contains(t, content, "broken")
+	assert.NotContains(t, content, "alpha")
+}
+
+func TestPipelineRecordsFailedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Embedding.BatchSize = 1 // Keep the failure to the broken file's batch
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	emb.failOn = "broken"
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	createPipelineFiles(t, tmpDir, 3)
+	path := createTestFile(t, tmpDir, "broken.go", "package main\n\nfunc broken() {\n\treturn\n}\n")
+
+	ctx := context.Background()
+	require.NoError(t, indexer.ReindexAll(ctx))
+
+	failed := indexer.FailedFiles()
+	require.Len(t, failed, 1)
+	assert.Equal(t, path, failed[0].Path)
+
+	emb.failOn = ""
+	assert.Equal(t, 1, indexer.RetryFailed(ctx))
+	assert.Empty(t, indexer.FailedFiles())
+	assert.Contains(t, indexedContent(t, indexer, path), "broken")
+}
+
+func TestRetryFailedStopsAfterMaxAttempts(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	emb.failOn = "broken"
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	path := createTestFile(t, tmpDir, "broken.go", "package main\n\nfunc broken() {\n\treturn\n}\n")
+	require.Error(t, indexer.IndexFile(ctx, path))
+
+	for n := 0; n < maxIndexAttempts+2; n++ {
+		assert.Zero(t, indexer.RetryFailed(ctx))
+	}
+
+	failed := indexer.FailedFiles()
+	require.Len(t, failed, 1)
+	assert.Equal(t, maxIndexAttempts, failed[0].Attempts)
+	assert.Len(t, emb.batchSizes(), maxIndexAttempts)
+}
+
+func TestRetryFailedForgetsDeletedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	emb := newRecordingEmbedder()
+	emb.failOn = "broken"
+	indexer, err := NewIndexer(tmpDir, cfg, database, emb, testLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	path := createTestFile(t, tmpDir, "broken.go", "package main\n\nfunc broken() {\n\treturn\n}\n")
+	require.Error(t, indexer.IndexFile(ctx, path))
+	require.Len(t, indexer.FailedFiles(), 1)
+
+	require.NoError(t, os.Remove(path))
+	assert.Zero(t, indexer.RetryFailed(ctx))
+	assert.Empty(t, indexer.FailedFiles())
+}
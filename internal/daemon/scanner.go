@@ -12,6 +12,8 @@ import (
 )
 
 // ScanResult contains the results of a filesystem scan.
+// Modified and Added paths are relative to the project root; Deleted paths
+// are reported as stored in the database so they can be removed directly.
 type ScanResult struct {
 	Modified []string
 	Added    []string
@@ -54,9 +56,18 @@ func (s *StartupScanner) Scan(ctx context.Context) (*ScanResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Key indexed files by relative path; the indexer stores absolute paths
 	indexedMap := make(map[string]time.Time)
+	storedPaths := make(map[string]string)
 	for _, f := range indexed {
-		indexedMap[f.Path] = f.ModifiedAt
+		relPath := f.Path
+		if filepath.IsAbs(relPath) {
+			if rel, err := filepath.Rel(s.projectRoot, relPath); err == nil {
+				relPath = rel
+			}
+		}
+		indexedMap[relPath] = f.ModifiedAt
+		storedPaths[relPath] = f.Path
 	}
 
 	// Walk filesystem
@@ -113,7 +124,7 @@ func (s *StartupScanner) Scan(ctx context.Context) (*ScanResult, error) {
 	// Find deleted files
 	for path := range indexedMap {
 		if !seenPaths[path] {
-			result.Deleted = append(result.Deleted, path)
+			result.Deleted = append(result.Deleted, storedPaths[path])
 		}
 	}
 
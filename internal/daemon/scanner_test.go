@@ -286,3 +286,36 @@ func TestScanner_UnchangedFiles(t *testing.T) {
 	assert.Empty(t, result.Modified)
 	assert.Empty(t, result.Deleted)
 }
+
+func TestScanner_MatchesAbsoluteIndexedPaths(t *testing.T) {
+	database := setupScannerTestDB(t)
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	unchanged := filepath.Join(tmpDir, "unchanged.go")
+	require.NoError(t, os.WriteFile(unchanged, []byte("package main"), 0644))
+	info, err := os.Stat(unchanged)
+	require.NoError(t, err)
+
+	// The indexer stores absolute paths
+	_, err = database.InsertFile(ctx, unchanged, "hash", "go", 12, info.ModTime())
+	require.NoError(t, err)
+	deleted := filepath.Join(tmpDir, "deleted.go")
+	_, err = database.InsertFile(ctx, deleted, "hash", "go", 12, time.Now())
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.IncludePatterns = []string{"*.go"}
+
+	ignorer, err := NewIgnorer(tmpDir, nil)
+	require.NoError(t, err)
+
+	scanner := NewStartupScanner(tmpDir, cfg, database, ignorer)
+	result, err := scanner.Scan(ctx)
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Added)
+	assert.Empty(t, result.Modified)
+	// Deleted paths are reported as stored
+	assert.Equal(t, []string{deleted}, result.Deleted)
+}
@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/pommel-dev/pommel/internal/subproject"
+)
+
+// SubprojectSummary is a sub-project together with its index counts.
+type SubprojectSummary struct {
+	*models.Subproject
+	Files  int64
+	Chunks int64
+}
+
+// SyncSubprojects detects sub-projects, syncs them into the database and
+// re-tags every indexed file with the sub-project that now contains it.
+// Files indexed afterwards are tagged as they are chunked.
+func (i *Indexer) SyncSubprojects(ctx context.Context) error {
+	manager := subproject.NewManager(i.db, i.projectRoot, &i.config.Subprojects)
+	added, removed, unchanged, err := manager.SyncSubprojects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sync subprojects: %w", err)
+	}
+
+	subprojects, err := i.db.ListSubprojects(ctx)
+	if err != nil {
+		return err
+	}
+
+	i.subprojectsMu.Lock()
+	i.subprojects = subprojects
+	i.subprojectsMu.Unlock()
+
+	files, err := i.db.ListFiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	assignments := make(map[string]*models.Subproject, len(files))
+	for _, file := range files {
+		assignments[file.Path] = i.subprojectFor(file.Path)
+	}
+	if err := i.db.SetFileSubprojects(ctx, assignments); err != nil {
+		return err
+	}
+
+	i.logger.Info("synced subprojects", "added", added, "removed", removed, "unchanged", unchanged)
+	return nil
+}
+
+// subprojectFor returns the most specific known sub-project containing path,
+// or nil if no sub-project contains it.
+func (i *Indexer) subprojectFor(path string) *models.Subproject {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(i.projectRoot, path)
+		if err != nil {
+			return nil
+		}
+		path = rel
+	}
+
+	i.subprojectsMu.RLock()
+	defer i.subprojectsMu.RUnlock()
+
+	var best *models.Subproject
+	for _, sp := range i.subprojects {
+		if sp.ContainsPath(path) && (best == nil || len(sp.Path) > len(best.Path)) {
+			best = sp
+		}
+	}
+	return best
+}
+
+// Subprojects returns the known sub-projects with their indexed file and chunk counts.
+func (i *Indexer) Subprojects(ctx context.Context) ([]SubprojectSummary, error) {
+	subprojects, err := i.db.ListSubprojects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := i.db.SubprojectCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]SubprojectSummary, len(subprojects))
+	for idx, sp := range subprojects {
+		summaries[idx] = SubprojectSummary{
+			Subproject: sp,
+			Files:      counts[sp.ID].Files,
+			Chunks:     counts[sp.ID].Chunks,
+		}
+	}
+	return summaries, nil
+}
+
+// syncSubprojects syncs sub-projects, logging rather than returning failures.
+func (d *Daemon) syncSubprojects(ctx context.Context) {
+	if err := d.indexer.SyncSubprojects(ctx); err != nil && ctx.Err() == nil {
+		d.logger.Warn("failed to sync subprojects", "error", err)
+	}
+}
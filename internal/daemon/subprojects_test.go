@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createNestedFile creates a file under dir, creating parent directories as needed
+func createNestedFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+// chunkSubprojects returns the subproject ID of each chunk stored for path
+func chunkSubprojects(t *testing.T, indexer *Indexer, path string) []string {
+	t.Helper()
+	ctx := context.Background()
+	ids, err := indexer.db.GetChunkIDsByFile(ctx, path)
+	require.NoError(t, err)
+	chunks, err := indexer.db.GetChunksByIDs(ctx, ids)
+	require.NoError(t, err)
+	require.NotEmpty(t, chunks, "no chunks indexed for %s", path)
+
+	subprojects := make([]string, len(chunks))
+	for idx, chunk := range chunks {
+		if chunk.SubprojectID != nil {
+			subprojects[idx] = *chunk.SubprojectID
+		}
+	}
+	return subprojects
+}
+
+func TestIndexFileTagsChunksWithSubproject(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Subprojects.AutoDetect = true
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, cfg, database, newRecordingEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	createNestedFile(t, tmpDir, "services/billing/go.mod", "module billing\n")
+	billing := createNestedFile(t, tmpDir, "services/billing/invoice.go", "package billing\n\nfunc Total() int {\n\treturn 0\n}\n")
+	root := createNestedFile(t, tmpDir, "main.go", "package main\n\nfunc main() {\n}\n")
+
+	ctx := context.Background()
+	require.NoError(t, indexer.SyncSubprojects(ctx))
+	require.NoError(t, indexer.IndexFile(ctx, billing))
+	require.NoError(t, indexer.IndexFile(ctx, root))
+
+	billingTags := chunkSubprojects(t, indexer, billing)
+	require.NotEmpty(t, billingTags)
+	for _, id := range billingTags {
+		assert.Equal(t, "billing", id)
+	}
+	for _, id := range chunkSubprojects(t, indexer, root) {
+		assert.Empty(t, id)
+	}
+
+	summaries, err := indexer.Subprojects(ctx)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "billing", summaries[0].ID)
+	assert.Equal(t, "services/billing", summaries[0].Path)
+	assert.Equal(t, int64(1), summaries[0].Files)
+	assert.Equal(t, int64(len(billingTags)), summaries[0].Chunks)
+}
+
+func TestSyncSubprojectsRetagsIndexedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Subprojects.AutoDetect = true
+	database := setupTestDB(t, tmpDir)
+	defer database.Close()
+
+	indexer, err := NewIndexer(tmpDir, cfg, database, newRecordingEmbedder(), testLogger())
+	require.NoError(t, err)
+
+	path := createNestedFile(t, tmpDir, "web/app.go", "package web\n\nfunc Serve() {\n}\n")
+	ctx := context.Background()
+	require.NoError(t, indexer.IndexFile(ctx, path))
+	for _, id := range chunkSubprojects(t, indexer, path) {
+		assert.Empty(t, id)
+	}
+
+	// A new marker turns web into a sub-project
+	marker := createNestedFile(t, tmpDir, "web/package.json", "{}\n")
+	require.NoError(t, indexer.SyncSubprojects(ctx))
+	for _, id := range chunkSubprojects(t, indexer, path) {
+		assert.Equal(t, "web", id)
+	}
+
+	// Removing it untags the file again
+	require.NoError(t, os.Remove(marker))
+	require.NoError(t, indexer.SyncSubprojects(ctx))
+	for _, id := range chunkSubprojects(t, indexer, path) {
+		assert.Empty(t, id)
+	}
+	summaries, err := indexer.Subprojects(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+}
+
+func TestMarkerFileEventSyncsSubprojects(t *testing.T) {
+	projectRoot := t.TempDir()
+	cfg := daemonTestConfig()
+	cfg.IncludePatterns = []string{"**/*.go"}
+	cfg.Subprojects.AutoDetect = true
+
+	d, err := New(projectRoot, cfg, daemonTestLogger())
+	require.NoError(t, err)
+	defer d.Close()
+	useMockEmbedder(t, d)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	path := createNestedFile(t, projectRoot, "api/server.go", "package api\n\nfunc Run() {\n}\n")
+	d.handleFileEvent(ctx, FileEvent{Path: path, Op: OpCreate})
+
+	marker := createNestedFile(t, projectRoot, "api/go.mod", "module api\n")
+	d.handleFileEvent(ctx, FileEvent{Path: marker, Op: OpCreate})
+
+	for _, id := range chunkSubprojects(t, d.indexer, path) {
+		assert.Equal(t, "api", id)
+	}
+}
@@ -68,47 +68,268 @@ func (db *DB) GetFileIDByPath(ctx context.Context, path string) (int64, error) {
 	return id, nil
 }
 
-// DeleteFileByPath deletes a file record by path.
-// This also cascades to delete associated chunks.
+// DeleteFileByPath deletes a file record by path together with its chunks,
+// their embeddings and their FTS entries in a single transaction.
 func (db *DB) DeleteFileByPath(ctx context.Context, path string) error {
-	_, err := db.Exec(ctx, `DELETE FROM files WHERE path = ?`, path)
+	tx, err := db.conn.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var fileID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM files WHERE path = ?`, path).Scan(&fileID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get file ID: %w", err)
+	}
+
+	if err := deleteFileChunksTx(ctx, tx, fileID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM files WHERE id = ?`, fileID); err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
-// InsertChunk inserts a chunk record.
+// InsertChunk inserts a chunk record and its FTS entry in a single transaction.
+// The FTS entry uses the path of the file the chunk belongs to.
 func (db *DB) InsertChunk(ctx context.Context, chunk *models.Chunk, fileID int64) error {
-	_, err := db.Exec(ctx, `
-		INSERT OR REPLACE INTO chunks (id, file_id, level, name, start_line, end_line, content, content_hash, parent_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, chunk.ID, fileID, string(chunk.Level), chunk.Name, chunk.StartLine, chunk.EndLine, chunk.Content, chunk.ContentHash, chunk.ParentID)
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertChunkTx(ctx, tx, chunk, fileID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertChunkTx inserts a chunk record and its FTS entry within a transaction.
+func insertChunkTx(ctx context.Context, tx *sql.Tx, chunk *models.Chunk, fileID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO chunks (
+			id, file_id, level, name, start_line, end_line, content, content_hash, parent_id,
+			language, signature, subproject_id, subproject_path, parent_chunk_id, chunk_index, is_partial
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, chunk.ID, fileID, string(chunk.Level), chunk.Name, chunk.StartLine, chunk.EndLine, chunk.Content, chunk.ContentHash, chunk.ParentID,
+		nullString(chunk.Language), nullString(chunk.Signature), chunk.SubprojectID, chunk.SubprojectPath, nullString(chunk.ParentChunkID), chunk.ChunkIndex, chunk.IsPartial)
 	if err != nil {
 		return fmt.Errorf("failed to insert chunk: %w", err)
 	}
+
+	// FTS5 doesn't support INSERT OR REPLACE well, so delete first
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks_fts WHERE chunk_id = ?`, chunk.ID); err != nil {
+		return fmt.Errorf("failed to delete existing FTS entry: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO chunks_fts (chunk_id, content, name, file_path, identifiers)
+		SELECT ?, ?, ?, path, ? FROM files WHERE id = ?
+	`, chunk.ID, chunk.Content, chunk.Name, ftsIdentifiers(chunk.Content, chunk.Name), fileID)
+	if err != nil {
+		return fmt.Errorf("failed to insert FTS entry: %w", err)
+	}
+
 	return nil
 }
 
-// DeleteChunksByFileID deletes all chunks for a file ID.
+// FileUpdate is the complete indexed state of a single file.
+type FileUpdate struct {
+	Path        string
+	ContentHash string
+	Language    string
+	Size        int64
+	ModifiedAt  time.Time
+	IsTest      bool // Whether the file holds tests
+	Chunks      []*models.Chunk
+	Embeddings  [][]float32 // One per chunk, in chunk order
+}
+
+// ReplaceFile replaces everything indexed for a file in a single transaction
+// covering files, chunks, chunk_embeddings and chunks_fts. If any step fails
+// the previous version of the file stays indexed. Returns the file ID.
+func (db *DB) ReplaceFile(ctx context.Context, update *FileUpdate) (int64, error) {
+	if len(update.Chunks) != len(update.Embeddings) {
+		return 0, fmt.Errorf("chunks and embeddings must have the same length: got %d and %d", len(update.Chunks), len(update.Embeddings))
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var fileID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM files WHERE path = ?`, update.Path).Scan(&fileID)
+	switch {
+	case err == sql.ErrNoRows:
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO files (path, content_hash, size, modified_at, language, is_test)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, update.Path, update.ContentHash, update.Size, update.ModifiedAt, update.Language, update.IsTest)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert file: %w", err)
+		}
+		if fileID, err = result.LastInsertId(); err != nil {
+			return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+		}
+	case err != nil:
+		return 0, fmt.Errorf("failed to get file ID: %w", err)
+	default:
+		if err := deleteFileChunksTx(ctx, tx, fileID); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE files
+			SET content_hash = ?, size = ?, modified_at = ?, indexed_at = CURRENT_TIMESTAMP, language = ?, is_test = ?
+			WHERE id = ?
+		`, update.ContentHash, update.Size, update.ModifiedAt, update.Language, update.IsTest, fileID); err != nil {
+			return 0, fmt.Errorf("failed to update file: %w", err)
+		}
+	}
+
+	for _, chunk := range update.Chunks {
+		if err := insertChunkTx(ctx, tx, chunk, fileID); err != nil {
+			return 0, err
+		}
+	}
+
+	chunkIDs := make([]string, len(update.Chunks))
+	for i, chunk := range update.Chunks {
+		chunkIDs[i] = chunk.ID
+	}
+	if err := insertEmbeddingsTx(ctx, tx, chunkIDs, update.Embeddings); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return fileID, nil
+}
+
+// deleteFileChunksTx deletes a file's chunks with their embeddings and FTS
+// entries within a transaction.
+func deleteFileChunksTx(ctx context.Context, tx *sql.Tx, fileID int64) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM chunks WHERE file_id = ?`, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to query chunk IDs: %w", err)
+	}
+	var chunkIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan chunk ID: %w", err)
+		}
+		chunkIDs = append(chunkIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating chunk IDs: %w", err)
+	}
+
+	// Delete embeddings one at a time so vec0 can use its primary key
+	if len(chunkIDs) > 0 {
+		stmt, err := tx.PrepareContext(ctx, `DELETE FROM chunk_embeddings WHERE chunk_id = ?`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare embedding delete: %w", err)
+		}
+		defer stmt.Close()
+		for _, id := range chunkIDs {
+			if _, err := stmt.ExecContext(ctx, id); err != nil {
+				return fmt.Errorf("failed to delete embedding for chunk %s: %w", id, err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM chunks_fts WHERE chunk_id IN (
+			SELECT id FROM chunks WHERE file_id = ?
+		)
+	`, fileID); err != nil {
+		return fmt.Errorf("failed to delete FTS entries: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks WHERE file_id = ?`, fileID); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteChunksByFileID deletes all chunks for a file ID along with their FTS entries.
 func (db *DB) DeleteChunksByFileID(ctx context.Context, fileID int64) error {
-	_, err := db.Exec(ctx, `DELETE FROM chunks WHERE file_id = ?`, fileID)
+	tx, err := db.conn.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM chunks_fts WHERE chunk_id IN (
+			SELECT id FROM chunks WHERE file_id = ?
+		)
+	`, fileID); err != nil {
+		return fmt.Errorf("failed to delete FTS entries: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks WHERE file_id = ?`, fileID); err != nil {
 		return fmt.Errorf("failed to delete chunks: %w", err)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
-// DeleteChunksByFile deletes all chunks associated with a file path.
+// DeleteChunksByFile deletes all chunks associated with a file path along with
+// their FTS entries.
 func (db *DB) DeleteChunksByFile(ctx context.Context, filePath string) error {
-	_, err := db.Exec(ctx, `
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM chunks_fts WHERE chunk_id IN (
+			SELECT c.id FROM chunks c
+			JOIN files f ON c.file_id = f.id
+			WHERE f.path = ?
+		)
+	`, filePath); err != nil {
+		return fmt.Errorf("failed to delete FTS entries by file path: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
 		DELETE FROM chunks WHERE file_id IN (
 			SELECT id FROM files WHERE path = ?
 		)
-	`, filePath)
-	if err != nil {
+	`, filePath); err != nil {
 		return fmt.Errorf("failed to delete chunks by file path: %w", err)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
@@ -171,6 +392,11 @@ func (db *DB) ClearAll(ctx context.Context) error {
 		return fmt.Errorf("failed to clear chunk_embeddings: %w", err)
 	}
 
+	// Delete from chunks_fts so keyword search doesn't return removed chunks
+	if _, err := db.Exec(ctx, `DELETE FROM chunks_fts`); err != nil {
+		return fmt.Errorf("failed to clear chunks_fts: %w", err)
+	}
+
 	// Delete from chunks (has FK to files)
 	if _, err := db.Exec(ctx, `DELETE FROM chunks`); err != nil {
 		return fmt.Errorf("failed to clear chunks: %w", err)
@@ -204,6 +430,35 @@ func (db *DB) FileCount(ctx context.Context) (int64, error) {
 	return count, nil
 }
 
+// SetTestFiles updates the is_test flag of indexed files, in a single
+// transaction. Paths that aren't indexed are ignored, and files whose flag
+// is already right are left untouched, so the index generation only changes
+// when a flag does.
+func (db *DB) SetTestFiles(ctx context.Context, flags map[string]bool) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE files SET is_test = ?1 WHERE path = ?2 AND is_test != ?1`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare test flag update: %w", err)
+	}
+	defer stmt.Close()
+
+	for path, isTest := range flags {
+		if _, err := stmt.ExecContext(ctx, isTest, path); err != nil {
+			return fmt.Errorf("failed to flag %s: %w", path, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // IndexedFile represents a file in the index with its metadata.
 type IndexedFile struct {
 	Path       string
@@ -235,17 +490,14 @@ func (db *DB) ListFiles(ctx context.Context) ([]IndexedFile, error) {
 }
 
 // GetChunkByID retrieves a chunk by its ID.
+// Returns nil without an error if the chunk doesn't exist.
 func (db *DB) GetChunkByID(ctx context.Context, id string) (*models.Chunk, error) {
-	var chunk models.Chunk
-	var parentID sql.NullString
-	var filePath string
-
-	err := db.QueryRow(ctx, `
-		SELECT c.id, f.path, c.start_line, c.end_line, c.level, c.name, c.content, c.content_hash, c.parent_id
+	chunk, err := scanChunk(db.QueryRow(ctx, `
+		SELECT `+chunkColumns+`
 		FROM chunks c
 		JOIN files f ON c.file_id = f.id
 		WHERE c.id = ?
-	`, id).Scan(&chunk.ID, &filePath, &chunk.StartLine, &chunk.EndLine, &chunk.Level, &chunk.Name, &chunk.Content, &chunk.ContentHash, &parentID)
+	`, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -253,12 +505,52 @@ func (db *DB) GetChunkByID(ctx context.Context, id string) (*models.Chunk, error
 		return nil, fmt.Errorf("failed to get chunk: %w", err)
 	}
 
-	chunk.FilePath = filePath
-	if parentID.Valid {
-		chunk.ParentID = &parentID.String
+	return chunk, nil
+}
+
+// GetChunkAt retrieves the innermost chunk of a file that covers a line.
+// Returns nil without an error if no chunk covers it.
+func (db *DB) GetChunkAt(ctx context.Context, filePath string, line int) (*models.Chunk, error) {
+	chunk, err := scanChunk(db.QueryRow(ctx, `
+		SELECT `+chunkColumns+`
+		FROM chunks c
+		JOIN files f ON c.file_id = f.id
+		WHERE f.path = ? AND c.start_line <= ? AND c.end_line >= ?
+		ORDER BY c.end_line - c.start_line, c.chunk_index
+		LIMIT 1
+	`, filePath, line, line))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk: %w", err)
 	}
 
-	return &chunk, nil
+	return chunk, nil
+}
+
+// GetSplitChunkIDs returns the IDs of the splits of an original chunk.
+func (db *DB) GetSplitChunkIDs(ctx context.Context, parentChunkID string) ([]string, error) {
+	rows, err := db.Query(ctx, `SELECT id FROM chunks WHERE parent_chunk_id = ?`, parentChunkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query split chunk IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chunk IDs: %w", err)
+	}
+
+	return ids, nil
 }
 
 // GetChunksByIDs retrieves multiple chunks by their IDs.
@@ -276,11 +568,11 @@ func (db *DB) GetChunksByIDs(ctx context.Context, ids []string) ([]*models.Chunk
 	}
 
 	query := fmt.Sprintf(`
-		SELECT c.id, f.path, c.start_line, c.end_line, c.level, c.name, c.content, c.content_hash, c.parent_id
+		SELECT %s
 		FROM chunks c
 		JOIN files f ON c.file_id = f.id
 		WHERE c.id IN (%s)
-	`, strings.Join(placeholders, ", "))
+	`, chunkColumns, strings.Join(placeholders, ", "))
 
 	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
@@ -290,19 +582,11 @@ func (db *DB) GetChunksByIDs(ctx context.Context, ids []string) ([]*models.Chunk
 
 	var chunks []*models.Chunk
 	for rows.Next() {
-		var chunk models.Chunk
-		var parentID sql.NullString
-		var filePath string
-
-		if err := rows.Scan(&chunk.ID, &filePath, &chunk.StartLine, &chunk.EndLine, &chunk.Level, &chunk.Name, &chunk.Content, &chunk.ContentHash, &parentID); err != nil {
+		chunk, err := scanChunk(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan chunk: %w", err)
 		}
-
-		chunk.FilePath = filePath
-		if parentID.Valid {
-			chunk.ParentID = &parentID.String
-		}
-		chunks = append(chunks, &chunk)
+		chunks = append(chunks, chunk)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -311,3 +595,57 @@ func (db *DB) GetChunksByIDs(ctx context.Context, ids []string) ([]*models.Chunk
 
 	return chunks, nil
 }
+
+// chunkColumns is the column list read by scanChunk.
+// Queries must alias chunks as c and join files as f.
+const chunkColumns = `c.id, f.path, c.start_line, c.end_line, c.level, c.name, c.content, c.content_hash, c.parent_id,
+		COALESCE(c.language, f.language), c.signature, c.subproject_id, c.subproject_path,
+		c.parent_chunk_id, c.chunk_index, c.is_partial, f.modified_at, f.is_test`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanChunk scans a row selected with chunkColumns into a chunk.
+func scanChunk(row rowScanner) (*models.Chunk, error) {
+	var chunk models.Chunk
+	var level string
+	var name, parentID, language, signature, subprojectID, subprojectPath, parentChunkID sql.NullString
+	var chunkIndex sql.NullInt64
+	var isPartial sql.NullBool
+	var modifiedAt sql.NullTime
+
+	if err := row.Scan(
+		&chunk.ID, &chunk.FilePath, &chunk.StartLine, &chunk.EndLine, &level, &name, &chunk.Content, &chunk.ContentHash, &parentID,
+		&language, &signature, &subprojectID, &subprojectPath,
+		&parentChunkID, &chunkIndex, &isPartial, &modifiedAt, &chunk.IsTest,
+	); err != nil {
+		return nil, err
+	}
+
+	chunk.Level = models.ChunkLevel(level)
+	chunk.Name = name.String
+	chunk.Language = language.String
+	chunk.Signature = signature.String
+	chunk.ParentChunkID = parentChunkID.String
+	chunk.ChunkIndex = int(chunkIndex.Int64)
+	chunk.IsPartial = isPartial.Bool
+	chunk.LastModified = modifiedAt.Time
+	if parentID.Valid {
+		chunk.ParentID = &parentID.String
+	}
+	if subprojectID.Valid {
+		chunk.SubprojectID = &subprojectID.String
+	}
+	if subprojectPath.Valid {
+		chunk.SubprojectPath = &subprojectPath.String
+	}
+
+	return &chunk, nil
+}
+
+// nullString converts an empty string to NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
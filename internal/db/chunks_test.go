@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -456,6 +457,239 @@ func TestGetChunkByID_WithParentID(t *testing.T) {
 	assert.Equal(t, parent.ID, *retrieved.ParentID)
 }
 
+func TestInsertChunk_RoundTripsFullModel(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fileID, err := db.InsertFile(ctx, "api/src/handler.py", "hash", "python", 1000, modTime)
+	require.NoError(t, err)
+
+	subprojectID := "api"
+	subprojectPath := "api"
+	original := &models.Chunk{
+		FilePath:       "api/src/handler.py",
+		Level:          models.ChunkLevelMethod,
+		Language:       "python",
+		Name:           "handle",
+		Signature:      "def handle(request):",
+		Content:        "def handle(request):\n    return ok(request)",
+		StartLine:      10,
+		EndLine:        40,
+		SubprojectID:   &subprojectID,
+		SubprojectPath: &subprojectPath,
+		ParentChunkID:  "original-chunk",
+		ChunkIndex:     2,
+		IsPartial:      true,
+	}
+	original.SetHashes()
+	require.NoError(t, db.InsertChunk(ctx, original, fileID))
+
+	assertFullModel := func(t *testing.T, retrieved *models.Chunk) {
+		t.Helper()
+		require.NotNil(t, retrieved)
+		assert.Equal(t, original.ID, retrieved.ID)
+		assert.Equal(t, "python", retrieved.Language)
+		assert.Equal(t, "def handle(request):", retrieved.Signature)
+		assert.Equal(t, original.ContentHash, retrieved.ContentHash)
+		require.NotNil(t, retrieved.SubprojectID)
+		assert.Equal(t, "api", *retrieved.SubprojectID)
+		require.NotNil(t, retrieved.SubprojectPath)
+		assert.Equal(t, "api", *retrieved.SubprojectPath)
+		assert.Equal(t, "original-chunk", retrieved.ParentChunkID)
+		assert.Equal(t, 2, retrieved.ChunkIndex)
+		assert.True(t, retrieved.IsPartial)
+		assert.True(t, modTime.Equal(retrieved.LastModified), "expected %v, got %v", modTime, retrieved.LastModified)
+	}
+
+	t.Run("GetChunkByID", func(t *testing.T) {
+		retrieved, err := db.GetChunkByID(ctx, original.ID)
+		require.NoError(t, err)
+		assertFullModel(t, retrieved)
+	})
+
+	t.Run("GetChunk", func(t *testing.T) {
+		retrieved, err := db.GetChunk(ctx, original.ID)
+		require.NoError(t, err)
+		assertFullModel(t, retrieved)
+	})
+
+	t.Run("GetChunksByIDs", func(t *testing.T) {
+		chunks, err := db.GetChunksByIDs(ctx, []string{original.ID})
+		require.NoError(t, err)
+		require.Len(t, chunks, 1)
+		assertFullModel(t, chunks[0])
+	})
+}
+
+func TestInsertChunk_UnsplitChunkHasNoParentChunk(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	fileID, err := db.InsertFile(ctx, "src/whole.go", "hash", "go", 1000, time.Now())
+	require.NoError(t, err)
+
+	chunk := &models.Chunk{
+		FilePath:  "src/whole.go",
+		Level:     models.ChunkLevelMethod,
+		Name:      "whole",
+		Content:   "func whole() {}",
+		StartLine: 1,
+		EndLine:   1,
+	}
+	chunk.SetHashes()
+	require.NoError(t, db.InsertChunk(ctx, chunk, fileID))
+
+	// An empty parent chunk ID is stored as NULL
+	var parentChunkID *string
+	err = db.QueryRow(ctx, "SELECT parent_chunk_id FROM chunks WHERE id = ?", chunk.ID).Scan(&parentChunkID)
+	require.NoError(t, err)
+	assert.Nil(t, parentChunkID)
+
+	retrieved, err := db.GetChunkByID(ctx, chunk.ID)
+	require.NoError(t, err)
+	require.NotNil(t, retrieved)
+	assert.Empty(t, retrieved.ParentChunkID)
+	assert.False(t, retrieved.IsPartial)
+	assert.Nil(t, retrieved.SubprojectID)
+	// Language falls back to the file's language when the chunk has none
+	assert.Equal(t, "go", retrieved.Language)
+}
+
+// =============================================================================
+// Tests for atomic file updates
+// =============================================================================
+
+func newTestFileUpdate(path string, contents ...string) *FileUpdate {
+	update := &FileUpdate{
+		Path:        path,
+		ContentHash: fmt.Sprintf("hash-%d", len(contents)),
+		Language:    "go",
+		Size:        100,
+		ModifiedAt:  time.Now(),
+	}
+	for i, content := range contents {
+		chunk := &models.Chunk{
+			FilePath:  path,
+			Level:     models.ChunkLevelMethod,
+			Language:  "go",
+			Name:      fmt.Sprintf("fn%d", i),
+			Content:   content,
+			StartLine: i*10 + 1,
+			EndLine:   i*10 + 5,
+		}
+		chunk.SetHashes()
+		update.Chunks = append(update.Chunks, chunk)
+		update.Embeddings = append(update.Embeddings, makeEmbedding(float32(i+1)*0.1))
+	}
+	return update
+}
+
+func countRows(t *testing.T, db *DB, table string) int {
+	t.Helper()
+	var count int
+	require.NoError(t, db.QueryRow(context.Background(), "SELECT COUNT(*) FROM "+table).Scan(&count))
+	return count
+}
+
+func TestReplaceFile_ReplacesPreviousVersion(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	first := newTestFileUpdate("src/replace.go", "func a() {}", "func b() {}", "func c() {}")
+	firstID, err := db.ReplaceFile(ctx, first)
+	require.NoError(t, err)
+
+	second := newTestFileUpdate("src/replace.go", "func d() {}")
+	second.Chunks[0].StartLine = 100 // Distinct chunk ID from the first version
+	second.Chunks[0].SetHashes()
+	secondID, err := db.ReplaceFile(ctx, second)
+	require.NoError(t, err)
+	assert.Equal(t, firstID, secondID, "file row should be updated in place")
+
+	files, err := db.FileCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), files)
+	assert.Equal(t, 1, countRows(t, db, "chunks"))
+	assert.Equal(t, 1, countRows(t, db, "chunks_fts"))
+	embeddings, err := db.EmbeddingCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, embeddings)
+
+	chunk, err := db.GetChunkByID(ctx, second.Chunks[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, chunk)
+	assert.Equal(t, "func d() {}", chunk.Content)
+
+	drift, err := db.CheckFTSDrift(ctx)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+}
+
+func TestReplaceFile_FailureKeepsPreviousVersion(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	first := newTestFileUpdate("src/keep.go", "func a() {}", "func b() {}")
+	_, err := db.ReplaceFile(ctx, first)
+	require.NoError(t, err)
+
+	// A wrong-dimension embedding fails after the old chunks were removed
+	// within the transaction
+	second := newTestFileUpdate("src/keep.go", "func c() {}")
+	second.Chunks[0].StartLine = 100
+	second.Chunks[0].SetHashes()
+	second.Embeddings[0] = []float32{1, 2, 3}
+	_, err = db.ReplaceFile(ctx, second)
+	require.Error(t, err)
+
+	assert.Equal(t, 2, countRows(t, db, "chunks"))
+	assert.Equal(t, 2, countRows(t, db, "chunks_fts"))
+	embeddings, err := db.EmbeddingCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, embeddings)
+
+	chunk, err := db.GetChunkByID(ctx, first.Chunks[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, chunk)
+	assert.Equal(t, "func a() {}", chunk.Content)
+
+	var hash string
+	require.NoError(t, db.QueryRow(ctx, `SELECT content_hash FROM files WHERE path = ?`, "src/keep.go").Scan(&hash))
+	assert.Equal(t, first.ContentHash, hash)
+}
+
+func TestReplaceFile_RejectsMismatchedEmbeddings(t *testing.T) {
+	db := setupTestDB(t)
+
+	update := newTestFileUpdate("src/mismatch.go", "func a() {}")
+	update.Embeddings = nil
+	_, err := db.ReplaceFile(context.Background(), update)
+	require.Error(t, err)
+}
+
+func TestDeleteFileByPath_RemovesChunksEmbeddingsAndFTS(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	_, err := db.ReplaceFile(ctx, newTestFileUpdate("src/gone.go", "func a() {}", "func b() {}"))
+	require.NoError(t, err)
+	_, err = db.ReplaceFile(ctx, newTestFileUpdate("src/kept.go", "func keep() {}"))
+	require.NoError(t, err)
+
+	require.NoError(t, db.DeleteFileByPath(ctx, "src/gone.go"))
+
+	assert.Equal(t, 1, countRows(t, db, "chunks"))
+	assert.Equal(t, 1, countRows(t, db, "chunks_fts"))
+	embeddings, err := db.EmbeddingCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, embeddings)
+
+	drift, err := db.CheckFTSDrift(ctx)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+}
+
 // =============================================================================
 // Tests for ClearAll
 // =============================================================================
@@ -528,3 +762,57 @@ func TestClearAll_EmptyDatabase(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, int64(0), fileCount)
 }
+
+// =============================================================================
+// Tests for chunk lookup by location
+// =============================================================================
+
+func TestGetChunkAt_ReturnsInnermostChunk(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	update := newTestFileUpdate("/project/server.go", "package main", "func serve() {}")
+	update.Chunks[0].Level = models.ChunkLevelFile
+	update.Chunks[0].StartLine, update.Chunks[0].EndLine = 1, 40
+	update.Chunks[1].StartLine, update.Chunks[1].EndLine = 10, 20
+	for _, chunk := range update.Chunks {
+		chunk.SetHashes()
+	}
+	_, err := db.ReplaceFile(ctx, update)
+	require.NoError(t, err)
+
+	chunk, err := db.GetChunkAt(ctx, "/project/server.go", 15)
+	require.NoError(t, err)
+	require.NotNil(t, chunk)
+	assert.Equal(t, update.Chunks[1].ID, chunk.ID)
+
+	chunk, err = db.GetChunkAt(ctx, "/project/server.go", 30)
+	require.NoError(t, err)
+	require.NotNil(t, chunk)
+	assert.Equal(t, update.Chunks[0].ID, chunk.ID)
+
+	chunk, err = db.GetChunkAt(ctx, "/project/server.go", 41)
+	require.NoError(t, err)
+	assert.Nil(t, chunk)
+}
+
+func TestGetSplitChunkIDs(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	update := newTestFileUpdate("/project/big.go", "part one", "part two", "func other() {}")
+	for _, chunk := range update.Chunks[:2] {
+		chunk.ParentChunkID = "original"
+		chunk.IsPartial = true
+	}
+	_, err := db.ReplaceFile(ctx, update)
+	require.NoError(t, err)
+
+	ids, err := db.GetSplitChunkIDs(ctx, "original")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{update.Chunks[0].ID, update.Chunks[1].ID}, ids)
+
+	ids, err = db.GetSplitChunkIDs(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -188,9 +189,9 @@ func TestMigrate_CreatesChunkEmbeddingsTable(t *testing.T) {
 
 	// Insert using vec0 format
 	_, err = db.Exec(ctx, `
-		INSERT INTO chunk_embeddings (chunk_id, embedding)
-		VALUES (?, ?)
-	`, "chunk-1", serialized)
+		INSERT INTO chunk_embeddings (chunk_id, embedding, level, language, subproject_id, file_path)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, "chunk-1", serialized, "method", "go", "", "/test/main.go")
 	require.NoError(t, err)
 
 	// Verify we can query the embedding
@@ -251,6 +252,189 @@ func TestMigrate_Idempotent(t *testing.T) {
 	assert.Equal(t, SchemaVersion, version)
 }
 
+func TestMigrateV5_AddsChunkModelColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, err := Open(tmpDir, EmbeddingDimension)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	assert.True(t, db.columnExists(ctx, "chunks", "language"))
+	assert.True(t, db.columnExists(ctx, "chunks", "signature"))
+}
+
+func TestMigrateV5_BackfillsLanguageFromFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, err := Open(tmpDir, EmbeddingDimension)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	// Simulate a chunk indexed before v5 with no language recorded
+	fileID, err := db.InsertFile(ctx, "/test/legacy.ts", "hash", "typescript", 100, time.Now())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO chunks (id, file_id, level, name, start_line, end_line, content, content_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, "legacy-chunk", fileID, "method", "legacy", 1, 2, "function legacy() {}", "hash")
+	require.NoError(t, err)
+
+	// Roll back to v4 and migrate again
+	_, err = db.Exec(ctx, "DELETE FROM schema_version WHERE version >= 5")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	var language string
+	err = db.QueryRow(ctx, "SELECT language FROM chunks WHERE id = ?", "legacy-chunk").Scan(&language)
+	require.NoError(t, err)
+	assert.Equal(t, "typescript", language)
+}
+
+func TestMigrateV6_AddsEmbeddingMetadataInPlace(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, err := Open(tmpDir, EmbeddingDimension)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	// Recreate the v5 embeddings table holding a chunk's embedding and an orphan
+	_, err = db.Exec(ctx, "DROP TABLE chunk_embeddings")
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, fmt.Sprintf(`
+		CREATE VIRTUAL TABLE chunk_embeddings USING vec0(
+			chunk_id TEXT PRIMARY KEY,
+			embedding FLOAT[%d]
+		)
+	`, EmbeddingDimension))
+	require.NoError(t, err)
+
+	fileID, err := db.InsertFile(ctx, "/test/legacy.py", "hash", "python", 100, time.Now())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO chunks (id, file_id, level, name, start_line, end_line, content, content_hash, language, subproject_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, "legacy-chunk", fileID, "class", "Legacy", 1, 2, "class Legacy: pass", "hash", "python", "api")
+	require.NoError(t, err)
+
+	for _, id := range []string{"legacy-chunk", "orphan-chunk"} {
+		serialized, err := sqlite_vec.SerializeFloat32(makeEmbedding(0.1))
+		require.NoError(t, err)
+		_, err = db.Exec(ctx, "INSERT INTO chunk_embeddings (chunk_id, embedding) VALUES (?, ?)", id, serialized)
+		require.NoError(t, err)
+	}
+
+	// Roll back to v5 and migrate again
+	_, err = db.Exec(ctx, "DELETE FROM schema_version WHERE version >= 6")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	count, err := db.EmbeddingCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "orphaned embeddings should be dropped")
+
+	var level, language, subprojectID string
+	err = db.QueryRow(ctx, `
+		SELECT level, language, subproject_id FROM chunk_embeddings WHERE chunk_id = ?
+	`, "legacy-chunk").Scan(&level, &language, &subprojectID)
+	require.NoError(t, err)
+	assert.Equal(t, "class", level)
+	assert.Equal(t, "python", language)
+	assert.Equal(t, "api", subprojectID)
+
+	embeddings, err := db.GetFileEmbeddingsByContentHash(ctx, "/test/legacy.py")
+	require.NoError(t, err)
+	assert.Equal(t, makeEmbedding(0.1), embeddings["hash"], "embedding should survive the migration")
+
+	results, err := db.SearchChunks(ctx, SearchOptions{
+		Embedding:  makeEmbedding(0.1),
+		Limit:      10,
+		Levels:     []string{"class"},
+		PathPrefix: "/test/",
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "legacy-chunk", results[0].ChunkID)
+}
+
+func TestMigrateV8_RebuildsFTSWithIdentifiers(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, err := Open(tmpDir, EmbeddingDimension)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	// Recreate the v7 FTS table holding an indexed chunk
+	fileID, err := db.InsertFile(ctx, "/test/users.go", "hash", "go", 100, time.Now())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO chunks (id, file_id, level, name, start_line, end_line, content, content_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, "legacy-chunk", fileID, "method", "getUserByEmail", 1, 2, "func getUserByEmail() {}", "hash")
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "DROP TABLE chunks_fts")
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		CREATE VIRTUAL TABLE chunks_fts USING fts5(
+			chunk_id UNINDEXED, content, name, file_path, tokenize='porter unicode61'
+		)
+	`)
+	require.NoError(t, err)
+	_, err = db.PopulateFTSFromChunks(ctx)
+	assert.Error(t, err, "v7 table has no identifiers column")
+
+	// Roll back to v7 and migrate again
+	_, err = db.Exec(ctx, "DELETE FROM schema_version WHERE version >= 8")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	assert.True(t, db.columnExists(ctx, "chunks_fts", "identifiers"))
+	results, err := db.FTSSearch(ctx, "user email", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "legacy-chunk", results[0].ChunkID)
+}
+
+func TestMigrateV9_AddsTestFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, err := Open(tmpDir, EmbeddingDimension)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	// Recreate a v8 files table holding an indexed file
+	_, err = db.Exec(ctx, "DROP INDEX idx_files_is_test")
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "ALTER TABLE files DROP COLUMN is_test")
+	require.NoError(t, err)
+	_, err = db.InsertFile(ctx, "/test/users_test.go", "hash", "go", 100, time.Now())
+	require.NoError(t, err)
+
+	// Roll back to v8 and migrate again
+	_, err = db.Exec(ctx, "DELETE FROM schema_version WHERE version >= 9")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	assert.True(t, db.columnExists(ctx, "files", "is_test"))
+	var isTest bool
+	require.NoError(t, db.QueryRow(ctx, "SELECT is_test FROM files WHERE path = ?", "/test/users_test.go").Scan(&isTest))
+	assert.False(t, isTest, "existing files start unflagged")
+}
+
 func TestClose(t *testing.T) {
 	tmpDir := t.TempDir()
 
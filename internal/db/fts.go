@@ -2,10 +2,13 @@ package db
 
 import (
 	"context"
+	"crypto/sha256"
+	"database/sql"
 	"fmt"
 	"strings"
 
 	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/pommel-dev/pommel/internal/tokenize"
 )
 
 // FTSResult represents a result from full-text search.
@@ -16,6 +19,8 @@ type FTSResult struct {
 
 // CreateFTSTable creates the FTS5 virtual table for full-text search.
 // Uses porter tokenizer for stemming and unicode61 for unicode support.
+// unicode61 keeps getUserByEmail as one token, so the identifiers column
+// holds the sub-words of the chunk's compound identifiers (see ftsIdentifiers).
 func (db *DB) CreateFTSTable(ctx context.Context) error {
 	_, err := db.Exec(ctx, `
 		CREATE VIRTUAL TABLE IF NOT EXISTS chunks_fts USING fts5(
@@ -23,6 +28,7 @@ func (db *DB) CreateFTSTable(ctx context.Context) error {
 			content,
 			name,
 			file_path,
+			identifiers,
 			tokenize='porter unicode61'
 		)
 	`)
@@ -86,9 +92,9 @@ func (db *DB) InsertFTSEntry(ctx context.Context, chunk *models.Chunk) error {
 
 	// Insert the new entry
 	_, err = db.Exec(ctx, `
-		INSERT INTO chunks_fts (chunk_id, content, name, file_path)
-		VALUES (?, ?, ?, ?)
-	`, chunk.ID, chunk.Content, chunk.Name, chunk.FilePath)
+		INSERT INTO chunks_fts (chunk_id, content, name, file_path, identifiers)
+		VALUES (?, ?, ?, ?, ?)
+	`, chunk.ID, chunk.Content, chunk.Name, chunk.FilePath, ftsIdentifiers(chunk.Content, chunk.Name))
 	if err != nil {
 		return fmt.Errorf("failed to insert FTS entry: %w", err)
 	}
@@ -120,9 +126,9 @@ func (db *DB) UpdateFTSEntry(ctx context.Context, chunk *models.Chunk) error {
 	}
 
 	_, err = db.Exec(ctx, `
-		INSERT INTO chunks_fts (chunk_id, content, name, file_path)
-		VALUES (?, ?, ?, ?)
-	`, chunk.ID, chunk.Content, chunk.Name, chunk.FilePath)
+		INSERT INTO chunks_fts (chunk_id, content, name, file_path, identifiers)
+		VALUES (?, ?, ?, ?, ?)
+	`, chunk.ID, chunk.Content, chunk.Name, chunk.FilePath, ftsIdentifiers(chunk.Content, chunk.Name))
 	if err != nil {
 		return fmt.Errorf("failed to insert updated FTS entry: %w", err)
 	}
@@ -151,9 +157,33 @@ func (db *DB) DeleteFTSEntriesByFile(ctx context.Context, filePath string) error
 	return nil
 }
 
+// FTSFilter restricts a full-text search to matching chunks. It filters on the
+// same chunk attributes as SearchOptions does for vector search.
+type FTSFilter struct {
+	Levels       []string // Filter by chunk levels (e.g., "file", "method", "class")
+	Languages    []string // Filter by chunk language (e.g., "go", "python")
+	SubprojectID string   // Filter by sub-project ID
+	PathPrefix   string   // Filter by file path prefix
+	FilePaths    []string // Restrict to these files; nil means any file, empty means none
+	TestFiles    *bool    // Restrict to test files (true) or other files (false); nil means any file
+}
+
+// isEmpty reports whether the filter matches every chunk.
+func (f FTSFilter) isEmpty() bool {
+	return len(f.Levels) == 0 && len(f.Languages) == 0 && f.SubprojectID == "" &&
+		f.PathPrefix == "" && f.FilePaths == nil && f.TestFiles == nil
+}
+
 // FTSSearch performs a full-text search and returns matching chunk IDs with scores.
 // The query can use FTS5 syntax (AND, OR, NOT, prefix*, "phrases").
 func (db *DB) FTSSearch(ctx context.Context, query string, limit int) ([]FTSResult, error) {
+	return db.FTSSearchWithFilter(ctx, query, limit, FTSFilter{})
+}
+
+// FTSSearchWithFilter performs a full-text search restricted to the chunks
+// matching filter. Filters are applied in the query, so up to limit matching
+// results are returned however selective the filter is.
+func (db *DB) FTSSearchWithFilter(ctx context.Context, query string, limit int, filter FTSFilter) ([]FTSResult, error) {
 	// Handle empty query
 	if strings.TrimSpace(query) == "" {
 		return []FTSResult{}, nil
@@ -177,15 +207,64 @@ func (db *DB) FTSSearch(ctx context.Context, query string, limit int) ([]FTSResu
 		return []FTSResult{}, nil
 	}
 
+	if filter.FilePaths != nil && len(filter.FilePaths) == 0 {
+		return []FTSResult{}, nil
+	}
+
+	from := "chunks_fts"
+	conditions := []string{"chunks_fts MATCH ?"}
+	args := []any{safeQuery}
+
+	if !filter.isEmpty() {
+		from += `
+		JOIN chunks c ON c.id = chunks_fts.chunk_id
+		JOIN files f ON f.id = c.file_id`
+
+		if len(filter.Levels) > 0 {
+			conditions = append(conditions, "c.level IN ("+placeholders(len(filter.Levels))+")")
+			for _, level := range filter.Levels {
+				args = append(args, level)
+			}
+		}
+		if len(filter.Languages) > 0 {
+			conditions = append(conditions, "COALESCE(c.language, f.language, '') IN ("+placeholders(len(filter.Languages))+")")
+			for _, language := range filter.Languages {
+				args = append(args, language)
+			}
+		}
+		if filter.SubprojectID != "" {
+			conditions = append(conditions, "c.subproject_id = ?")
+			args = append(args, filter.SubprojectID)
+		}
+		if filter.PathPrefix != "" {
+			lower, upper := pathRangeArgs(filter.PathPrefix)
+			conditions = append(conditions, "f.path >= ?", "f.path <= ?")
+			args = append(args, lower, upper)
+		}
+		if filter.FilePaths != nil {
+			list, err := jsonList(filter.FilePaths)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, "f.path IN (SELECT value FROM json_each(?))")
+			args = append(args, list)
+		}
+		if filter.TestFiles != nil {
+			conditions = append(conditions, "f.is_test = ?")
+			args = append(args, *filter.TestFiles)
+		}
+	}
+	args = append(args, limit)
+
 	// BM25 returns negative scores where more negative = more relevant
 	// We negate to make higher scores = more relevant
 	rows, err := db.Query(ctx, `
-		SELECT chunk_id, -bm25(chunks_fts) as score
-		FROM chunks_fts
-		WHERE chunks_fts MATCH ?
+		SELECT chunks_fts.chunk_id, -bm25(chunks_fts) as score
+		FROM `+from+`
+		WHERE `+strings.Join(conditions, " AND ")+`
 		ORDER BY score DESC
 		LIMIT ?
-	`, safeQuery, limit)
+	`, args...)
 	if err != nil {
 		// Check if it's a context error
 		if ctx.Err() != nil {
@@ -238,7 +317,8 @@ func sanitizeFTSQuery(query string) string {
 }
 
 // PopulateFTSFromChunks rebuilds the FTS index from the chunks table.
-// This clears existing FTS entries and repopulates from the chunks table.
+// This clears existing FTS entries and repopulates from the chunks table in a
+// single transaction, so keyword search never sees a partially built index.
 // Returns the number of entries populated.
 func (db *DB) PopulateFTSFromChunks(ctx context.Context) (int, error) {
 	// Check context
@@ -248,52 +328,160 @@ func (db *DB) PopulateFTSFromChunks(ctx context.Context) (int, error) {
 	default:
 	}
 
-	// Clear existing FTS entries
-	_, err := db.Exec(ctx, `DELETE FROM chunks_fts`)
+	tx, err := db.conn.BeginTx(ctx, nil)
 	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Clear existing FTS entries
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks_fts`); err != nil {
 		return 0, fmt.Errorf("failed to clear FTS table: %w", err)
 	}
 
-	// Query all chunks with their file paths
-	rows, err := db.Query(ctx, `
-		SELECT c.id, c.content, c.name, f.path
+	// Repopulate from all chunks with their file paths. Identifiers are
+	// split in Go, so the rows are read before any are inserted.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT c.id, c.content, COALESCE(c.name, ''), f.path
 		FROM chunks c
 		JOIN files f ON c.file_id = f.id
 	`)
 	if err != nil {
 		return 0, fmt.Errorf("failed to query chunks: %w", err)
 	}
-	defer rows.Close()
 
-	count := 0
+	type ftsEntry struct{ id, content, name, path string }
+	var entries []ftsEntry
 	for rows.Next() {
-		// Check context periodically
-		select {
-		case <-ctx.Done():
-			return count, ctx.Err()
-		default:
+		var e ftsEntry
+		if err := rows.Scan(&e.id, &e.content, &e.name, &e.path); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("error iterating chunks: %w", err)
+	}
+	rows.Close()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO chunks_fts (chunk_id, content, name, file_path, identifiers)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare FTS insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		if _, err := stmt.ExecContext(ctx, e.id, e.content, e.name, e.path, ftsIdentifiers(e.content, e.name)); err != nil {
+			return 0, fmt.Errorf("failed to populate FTS table: %w", err)
 		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(entries), nil
+}
+
+// ftsIdentifiers returns the identifiers column of a chunk's FTS entry: the
+// sub-words of the compound identifiers in its name and content.
+func ftsIdentifiers(content, name string) string {
+	return strings.Join(tokenize.SubWords(name+"\n"+content), " ")
+}
+
+// FTSDriftReport describes differences between the chunks table and the FTS index.
+type FTSDriftReport struct {
+	ChunkCount int // Number of rows in chunks
+	FTSCount   int // Number of rows in chunks_fts
+	Missing    int // Chunks without an FTS entry
+	Orphaned   int // FTS entries whose chunk no longer exists
+	Stale      int // FTS entries whose indexed columns differ from the chunk
+	Duplicates int // Extra FTS entries for a chunk that already has one
+}
 
+// HasDrift returns true if the FTS index doesn't match the chunks table.
+func (r *FTSDriftReport) HasDrift() bool {
+	return r.Missing > 0 || r.Orphaned > 0 || r.Stale > 0 || r.Duplicates > 0
+}
+
+// CheckFTSDrift compares the chunks table with the FTS index and reports
+// any entries that are missing, orphaned, stale or duplicated.
+func (db *DB) CheckFTSDrift(ctx context.Context) (*FTSDriftReport, error) {
+	report := &FTSDriftReport{}
+
+	// Fingerprint every chunk as it should appear in the FTS index
+	rows, err := db.Query(ctx, `
+		SELECT c.id, c.content, COALESCE(c.name, ''), f.path
+		FROM chunks c
+		JOIN files f ON c.file_id = f.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chunks: %w", err)
+	}
+
+	expected := make(map[string][32]byte)
+	for rows.Next() {
 		var id, content, name, path string
 		if err := rows.Scan(&id, &content, &name, &path); err != nil {
-			return count, fmt.Errorf("failed to scan chunk: %w", err)
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
 		}
+		expected[id] = ftsFingerprint(content, name, path, ftsIdentifiers(content, name))
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error iterating chunks: %w", err)
+	}
+	rows.Close()
+	report.ChunkCount = len(expected)
 
-		_, err := db.Exec(ctx, `
-			INSERT INTO chunks_fts (chunk_id, content, name, file_path)
-			VALUES (?, ?, ?, ?)
-		`, id, content, name, path)
-		if err != nil {
-			return count, fmt.Errorf("failed to insert FTS entry for chunk %s: %w", id, err)
-		}
-		count++
+	// Compare against the FTS entries
+	rows, err = db.Query(ctx, `SELECT chunk_id, content, name, file_path, identifiers FROM chunks_fts`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query FTS entries: %w", err)
 	}
+	defer rows.Close()
 
+	seen := make(map[string]bool, len(expected))
+	for rows.Next() {
+		var id, content, name, path, identifiers sql.NullString
+		if err := rows.Scan(&id, &content, &name, &path, &identifiers); err != nil {
+			return nil, fmt.Errorf("failed to scan FTS entry: %w", err)
+		}
+		report.FTSCount++
+
+		fingerprint, ok := expected[id.String]
+		switch {
+		case !ok:
+			report.Orphaned++
+		case seen[id.String]:
+			report.Duplicates++
+		case fingerprint != ftsFingerprint(content.String, name.String, path.String, identifiers.String):
+			report.Stale++
+		}
+		seen[id.String] = true
+	}
 	if err := rows.Err(); err != nil {
-		return count, fmt.Errorf("error iterating chunks: %w", err)
+		return nil, fmt.Errorf("error iterating FTS entries: %w", err)
+	}
+
+	for id := range expected {
+		if !seen[id] {
+			report.Missing++
+		}
 	}
 
-	return count, nil
+	return report, nil
+}
+
+// ftsFingerprint hashes the indexed columns of an FTS entry.
+func ftsFingerprint(content, name, path, identifiers string) [32]byte {
+	return sha256.Sum256([]byte(content + "\x00" + name + "\x00" + path + "\x00" + identifiers))
 }
 
 // ClearFTS removes all entries from the FTS table.
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
@@ -1126,6 +1127,47 @@ func TestFTSSearch_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestFTSSearch_IdentifierSubWords(t *testing.T) {
+	db := setupFTSTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	if err := db.CreateFTSTable(ctx); err != nil {
+		t.Fatalf("CreateFTSTable failed: %v", err)
+	}
+
+	for _, chunk := range []*models.Chunk{
+		{ID: "camel", Content: "func getUserByEmail(addr string) {}", Name: "getUserByEmail", FilePath: "/users.go"},
+		{ID: "acronym", Content: "type HTTPServer struct{}", Name: "HTTPServer", FilePath: "/server.go"},
+		{ID: "dotted", Content: "config.loadDefaults()", Name: "init", FilePath: "/init.go"},
+	} {
+		if err := db.InsertFTSEntry(ctx, chunk); err != nil {
+			t.Fatalf("InsertFTSEntry failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"email", "camel"},
+		{"getUserByEmail", "camel"},
+		{"server", "acronym"},
+		{"http", "acronym"},
+		{"defaults", "dotted"},
+	}
+	for _, tt := range tests {
+		results, err := db.FTSSearch(ctx, tt.query, 10)
+		if err != nil {
+			t.Fatalf("FTSSearch(%q) failed: %v", tt.query, err)
+		}
+		if len(results) != 1 || results[0].ChunkID != tt.want {
+			t.Errorf("FTSSearch(%q) = %v, want only %s", tt.query, results, tt.want)
+		}
+	}
+}
+
 // ============================================================================
 // 26.7 Bulk FTS Population Tests
 // ============================================================================
@@ -1280,6 +1322,271 @@ func TestPopulateFTS_ContextCancellation(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// FTS Sync Tests
+// ============================================================================
+
+func TestInsertChunk_MirrorsToFTS(t *testing.T) {
+	db := setupFTSTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	fileID, err := db.InsertFile(ctx, "/test/auth.go", "hash1", "go", 100, time.Now())
+	if err != nil {
+		t.Fatalf("InsertFile failed: %v", err)
+	}
+
+	chunk := &models.Chunk{ID: "chunk-1", Content: "func authenticate() {}", Name: "handler", Level: "function"}
+	if err := db.InsertChunk(ctx, chunk, fileID); err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+
+	var filePath string
+	if err := db.QueryRow(ctx, `SELECT file_path FROM chunks_fts WHERE chunk_id = ?`, "chunk-1").Scan(&filePath); err != nil {
+		t.Fatalf("FTS entry not found: %v", err)
+	}
+	if filePath != "/test/auth.go" {
+		t.Errorf("Expected FTS file_path /test/auth.go, got %s", filePath)
+	}
+
+	// Re-inserting the chunk with new content replaces the FTS entry
+	chunk.Content = "func authorize() {}"
+	if err := db.InsertChunk(ctx, chunk, fileID); err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+
+	results, err := db.FTSSearch(ctx, "authenticate", 10)
+	if err != nil {
+		t.Fatalf("FTSSearch failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected old content to be gone, got %d results", len(results))
+	}
+
+	results, err = db.FTSSearch(ctx, "authorize", 10)
+	if err != nil {
+		t.Fatalf("FTSSearch failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected 1 result for new content, got %d", len(results))
+	}
+}
+
+func TestDeleteChunksByFile_RemovesFTSEntries(t *testing.T) {
+	db := setupFTSTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	for _, path := range []string{"/test/a.go", "/test/b.go"} {
+		fileID, err := db.InsertFile(ctx, path, "hash-"+path, "go", 100, time.Now())
+		if err != nil {
+			t.Fatalf("InsertFile failed: %v", err)
+		}
+		if err := db.InsertChunk(ctx, &models.Chunk{
+			ID: "chunk-" + path, Content: "shared keyword", Name: "fn", Level: "function",
+		}, fileID); err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+	}
+
+	if err := db.DeleteChunksByFile(ctx, "/test/a.go"); err != nil {
+		t.Fatalf("DeleteChunksByFile failed: %v", err)
+	}
+
+	results, err := db.FTSSearch(ctx, "keyword", 10)
+	if err != nil {
+		t.Fatalf("FTSSearch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ChunkID != "chunk-/test/b.go" {
+		t.Errorf("Expected only chunk from b.go to remain, got %v", results)
+	}
+
+	if err := db.DeleteFileByPath(ctx, "/test/b.go"); err != nil {
+		t.Fatalf("DeleteFileByPath failed: %v", err)
+	}
+
+	results, err = db.FTSSearch(ctx, "keyword", 10)
+	if err != nil {
+		t.Fatalf("FTSSearch failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results after deleting file, got %d", len(results))
+	}
+}
+
+func TestClearAll_ClearsFTS(t *testing.T) {
+	db := setupFTSTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	fileID, err := db.InsertFile(ctx, "/test/file.go", "hash1", "go", 100, time.Now())
+	if err != nil {
+		t.Fatalf("InsertFile failed: %v", err)
+	}
+	if err := db.InsertChunk(ctx, &models.Chunk{ID: "chunk-1", Content: "content", Level: "function"}, fileID); err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+
+	if err := db.ClearAll(ctx); err != nil {
+		t.Fatalf("ClearAll failed: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM chunks_fts`).Scan(&count); err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected empty FTS table, got %d entries", count)
+	}
+}
+
+func TestCheckFTSDrift_InSync(t *testing.T) {
+	db := setupFTSTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	fileID, err := db.InsertFile(ctx, "/test/file.go", "hash1", "go", 100, time.Now())
+	if err != nil {
+		t.Fatalf("InsertFile failed: %v", err)
+	}
+	for _, id := range []string{"chunk-1", "chunk-2"} {
+		if err := db.InsertChunk(ctx, &models.Chunk{ID: id, Content: "content " + id, Level: "function"}, fileID); err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+	}
+
+	report, err := db.CheckFTSDrift(ctx)
+	if err != nil {
+		t.Fatalf("CheckFTSDrift failed: %v", err)
+	}
+	if report.HasDrift() {
+		t.Errorf("Expected no drift, got %+v", report)
+	}
+	if report.ChunkCount != 2 || report.FTSCount != 2 {
+		t.Errorf("Expected 2 chunks and 2 FTS entries, got %+v", report)
+	}
+}
+
+func TestCheckFTSDrift_DetectsAndRepairsDrift(t *testing.T) {
+	db := setupFTSTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	fileID, err := db.InsertFile(ctx, "/test/file.go", "hash1", "go", 100, time.Now())
+	if err != nil {
+		t.Fatalf("InsertFile failed: %v", err)
+	}
+	for _, id := range []string{"missing", "stale", "duplicate"} {
+		if err := db.InsertChunk(ctx, &models.Chunk{ID: id, Content: "content " + id, Level: "function"}, fileID); err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+	}
+
+	// Introduce each kind of drift directly in the FTS table
+	if err := db.DeleteFTSEntry(ctx, "missing"); err != nil {
+		t.Fatalf("DeleteFTSEntry failed: %v", err)
+	}
+	if _, err := db.Exec(ctx, `UPDATE chunks_fts SET content = 'outdated' WHERE chunk_id = 'stale'`); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if _, err := db.Exec(ctx, `INSERT INTO chunks_fts (chunk_id, content, name, file_path) VALUES ('duplicate', 'content duplicate', '', '/test/file.go')`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if _, err := db.Exec(ctx, `INSERT INTO chunks_fts (chunk_id, content, name, file_path) VALUES ('orphan', 'gone', '', '/old.go')`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	report, err := db.CheckFTSDrift(ctx)
+	if err != nil {
+		t.Fatalf("CheckFTSDrift failed: %v", err)
+	}
+	if !report.HasDrift() {
+		t.Fatal("Expected drift to be detected")
+	}
+	if report.Missing != 1 || report.Stale != 1 || report.Duplicates != 1 || report.Orphaned != 1 {
+		t.Errorf("Unexpected drift report: %+v", report)
+	}
+
+	if _, err := db.PopulateFTSFromChunks(ctx); err != nil {
+		t.Fatalf("PopulateFTSFromChunks failed: %v", err)
+	}
+
+	report, err = db.CheckFTSDrift(ctx)
+	if err != nil {
+		t.Fatalf("CheckFTSDrift failed: %v", err)
+	}
+	if report.HasDrift() {
+		t.Errorf("Expected no drift after rebuild, got %+v", report)
+	}
+}
+
+func TestFTSSearchWithFilter(t *testing.T) {
+	db := setupFTSTestDB(t)
+	ctx := context.Background()
+
+	files := []struct {
+		path     string
+		language string
+		level    models.ChunkLevel
+	}{
+		{"/project/api/retry.go", "go", models.ChunkLevelMethod},
+		{"/project/api/retry_test.go", "go", models.ChunkLevelMethod},
+		{"/project/web/retry.ts", "typescript", models.ChunkLevelClass},
+	}
+	for i, f := range files {
+		fileID, err := db.InsertFile(ctx, f.path, "hash", f.language, 100, time.Now())
+		if err != nil {
+			t.Fatalf("InsertFile failed: %v", err)
+		}
+		chunk := &models.Chunk{
+			ID: fmt.Sprintf("chunk%d", i), FilePath: f.path, Content: "retry with backoff",
+			Level: f.level, Language: f.language,
+		}
+		if err := db.InsertChunk(ctx, chunk, fileID); err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+	}
+	if err := db.SetTestFiles(ctx, map[string]bool{"/project/api/retry_test.go": true}); err != nil {
+		t.Fatalf("SetTestFiles failed: %v", err)
+	}
+	tests, others := true, false
+
+	cases := []struct {
+		name   string
+		filter FTSFilter
+		want   []string
+	}{
+		{"none", FTSFilter{}, []string{"chunk0", "chunk1", "chunk2"}},
+		{"level", FTSFilter{Levels: []string{"class"}}, []string{"chunk2"}},
+		{"language", FTSFilter{Languages: []string{"go"}}, []string{"chunk0", "chunk1"}},
+		{"path prefix", FTSFilter{PathPrefix: "/project/web/"}, []string{"chunk2"}},
+		{"files", FTSFilter{FilePaths: []string{"/project/api/retry.go", "/project/web/retry.ts"}}, []string{"chunk0", "chunk2"}},
+		{"no files", FTSFilter{FilePaths: []string{}}, nil},
+		{"combined", FTSFilter{Languages: []string{"go"}, FilePaths: []string{"/project/api/retry_test.go"}}, []string{"chunk1"}},
+		{"test files", FTSFilter{TestFiles: &tests}, []string{"chunk1"}},
+		{"other files", FTSFilter{TestFiles: &others}, []string{"chunk0", "chunk2"}},
+	}
+	for _, tt := range cases {
+		results, err := db.FTSSearchWithFilter(ctx, "retry", 10, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: FTSSearchWithFilter failed: %v", tt.name, err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.ChunkID)
+		}
+		sort.Strings(got)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
 // ============================================================================
 // Helper Functions
 // ============================================================================
@@ -52,6 +52,18 @@ func (db *DB) DeleteMetadata(ctx context.Context, key string) error {
 	return nil
 }
 
+// IndexGeneration returns a counter that changes whenever a file is indexed,
+// re-indexed or removed, or chunks are assigned to a different sub-project.
+// Anything derived from a search is stale once the generation moves on.
+func (db *DB) IndexGeneration(ctx context.Context) (int64, error) {
+	var generation int64
+	err := db.QueryRow(ctx, `SELECT generation FROM index_generation WHERE id = 1`).Scan(&generation)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get index generation: %w", err)
+	}
+	return generation, nil
+}
+
 // GetProviderInfo retrieves the stored embedding provider information.
 func (db *DB) GetProviderInfo(ctx context.Context) (ProviderInfo, error) {
 	info := ProviderInfo{}
@@ -97,6 +109,17 @@ func (db *DB) SetProviderInfo(ctx context.Context, info ProviderInfo) error {
 	return nil
 }
 
+// GetDocumentPrefix returns the prefix stored embeddings were made with. Indexes
+// built before it was recorded embedded content as is, so a missing value is "".
+func (db *DB) GetDocumentPrefix(ctx context.Context) (string, error) {
+	return db.GetMetadata(ctx, "document_prefix")
+}
+
+// SetDocumentPrefix records the prefix stored embeddings were made with.
+func (db *DB) SetDocumentPrefix(ctx context.Context, prefix string) error {
+	return db.SetMetadata(ctx, "document_prefix", prefix)
+}
+
 // ProviderChanged checks if the current provider configuration differs from what's stored.
 // Returns false if no provider is currently stored (fresh database).
 func (db *DB) ProviderChanged(ctx context.Context, provider, model string) (bool, error) {
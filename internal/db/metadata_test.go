@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/pommel-dev/pommel/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -125,3 +126,58 @@ func TestDB_ProviderChanged(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, changed)
 }
+
+func TestDB_DocumentPrefix(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	// Not recorded yet - content was embedded without a prefix
+	prefix, err := db.GetDocumentPrefix(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, prefix)
+
+	require.NoError(t, db.SetDocumentPrefix(ctx, "Passage: "))
+	prefix, err = db.GetDocumentPrefix(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Passage: ", prefix)
+}
+
+func TestDB_IndexGeneration(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	generation := func() int64 {
+		t.Helper()
+		g, err := db.IndexGeneration(ctx)
+		require.NoError(t, err)
+		return g
+	}
+
+	last := generation()
+	assertChanged := func(what string) {
+		t.Helper()
+		current := generation()
+		assert.NotEqual(t, last, current, "%s should change the index generation", what)
+		last = current
+	}
+
+	_, err := db.ReplaceFile(ctx, newTestFileUpdate("/project/api/server.go", "func a() {}"))
+	require.NoError(t, err)
+	assertChanged("indexing a new file")
+
+	_, err = db.ReplaceFile(ctx, newTestFileUpdate("/project/api/server.go", "func a() {}", "func b() {}"))
+	require.NoError(t, err)
+	assertChanged("re-indexing a file")
+
+	require.NoError(t, db.SetFileSubprojects(ctx, map[string]*models.Subproject{
+		"/project/api/server.go": {ID: "api", Path: "api"},
+	}))
+	assertChanged("moving chunks to a sub-project")
+
+	_, err = db.SearchChunks(ctx, SearchOptions{Embedding: makeEmbedding(0.1), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, last, generation(), "searching should not change the index generation")
+
+	require.NoError(t, db.DeleteFileByPath(ctx, "/project/api/server.go"))
+	assertChanged("removing a file")
+}
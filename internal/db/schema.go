@@ -5,7 +5,7 @@ import (
 	"fmt"
 )
 
-const SchemaVersion = 4
+const SchemaVersion = 9
 
 // Migrate runs database migrations to ensure schema is up to date.
 func (db *DB) Migrate(ctx context.Context) error {
@@ -43,6 +43,36 @@ func (db *DB) Migrate(ctx context.Context) error {
 		}
 	}
 
+	if currentVersion < 5 {
+		if err := db.migrateV5(ctx); err != nil {
+			return fmt.Errorf("failed to run v5 migration: %w", err)
+		}
+	}
+
+	if currentVersion < 6 {
+		if err := db.migrateV6(ctx); err != nil {
+			return fmt.Errorf("failed to run v6 migration: %w", err)
+		}
+	}
+
+	if currentVersion < 7 {
+		if err := db.migrateV7(ctx); err != nil {
+			return fmt.Errorf("failed to run v7 migration: %w", err)
+		}
+	}
+
+	if currentVersion < 8 {
+		if err := db.migrateV8(ctx); err != nil {
+			return fmt.Errorf("failed to run v8 migration: %w", err)
+		}
+	}
+
+	if currentVersion < 9 {
+		if err := db.migrateV9(ctx); err != nil {
+			return fmt.Errorf("failed to run v9 migration: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -343,3 +373,195 @@ func (db *DB) migrateV4(ctx context.Context) error {
 
 	return nil
 }
+
+// migrateV5 persists the remaining chunk model fields.
+// Adds language and signature columns to chunks; language is backfilled
+// from the owning file for chunks indexed before this migration.
+func (db *DB) migrateV5(ctx context.Context) error {
+	if !db.columnExists(ctx, "chunks", "language") {
+		if _, err := db.Exec(ctx, `
+			ALTER TABLE chunks ADD COLUMN language TEXT
+		`); err != nil {
+			return fmt.Errorf("failed to add language column: %w", err)
+		}
+	}
+
+	if !db.columnExists(ctx, "chunks", "signature") {
+		if _, err := db.Exec(ctx, `
+			ALTER TABLE chunks ADD COLUMN signature TEXT
+		`); err != nil {
+			return fmt.Errorf("failed to add signature column: %w", err)
+		}
+	}
+
+	// Backfill language from the files table
+	if _, err := db.Exec(ctx, `
+		UPDATE chunks
+		SET language = (SELECT f.language FROM files f WHERE f.id = chunks.file_id)
+		WHERE language IS NULL
+	`); err != nil {
+		return fmt.Errorf("failed to backfill chunk language: %w", err)
+	}
+
+	// Update schema version
+	if err := db.setSchemaVersion(ctx, 5); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	return nil
+}
+
+// migrateV6 rebuilds chunk_embeddings with level, language, subproject_id and
+// file_path metadata columns so that search filters are applied inside the
+// KNN scan. vec0 tables can't be altered or renamed, so existing embeddings
+// are copied out, the table is recreated and the embeddings are copied back.
+func (db *DB) migrateV6(ctx context.Context) error {
+	if !db.columnExists(ctx, "chunk_embeddings", "file_path") {
+		tx, err := db.conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, `
+			CREATE TEMP TABLE chunk_embeddings_v5 AS
+			SELECT chunk_id, embedding FROM chunk_embeddings
+		`); err != nil {
+			return fmt.Errorf("failed to copy embeddings: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DROP TABLE chunk_embeddings`); err != nil {
+			return fmt.Errorf("failed to drop chunk_embeddings table: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			CREATE VIRTUAL TABLE chunk_embeddings USING vec0(
+				chunk_id TEXT PRIMARY KEY,
+				embedding FLOAT[%d],
+				level TEXT,
+				language TEXT,
+				subproject_id TEXT,
+				file_path TEXT
+			)
+		`, db.dimensions)); err != nil {
+			return fmt.Errorf("failed to create chunk_embeddings table: %w", err)
+		}
+
+		// Embeddings whose chunk no longer exists are dropped
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO chunk_embeddings (chunk_id, embedding, level, language, subproject_id, file_path)
+			SELECT e.chunk_id, e.embedding, `+embeddingMetadataColumns+`
+			FROM chunk_embeddings_v5 e
+			JOIN chunks c ON c.id = e.chunk_id
+			JOIN files f ON f.id = c.file_id
+		`); err != nil {
+			return fmt.Errorf("failed to restore embeddings: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DROP TABLE chunk_embeddings_v5`); err != nil {
+			return fmt.Errorf("failed to drop embedding copy: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+
+	// Update schema version
+	if err := db.setSchemaVersion(ctx, 6); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	return nil
+}
+
+// migrateV7 adds the index generation counter. Triggers bump it whenever a
+// file is added, replaced or removed, or chunks move to another sub-project,
+// so anything derived from a search can tell when the index has changed.
+func (db *DB) migrateV7(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS index_generation (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			generation INTEGER NOT NULL
+		)`,
+		`INSERT OR IGNORE INTO index_generation (id, generation) VALUES (1, 0)`,
+		`CREATE TRIGGER IF NOT EXISTS files_generation_insert AFTER INSERT ON files
+		BEGIN
+			UPDATE index_generation SET generation = generation + 1;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS files_generation_update AFTER UPDATE ON files
+		BEGIN
+			UPDATE index_generation SET generation = generation + 1;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS files_generation_delete AFTER DELETE ON files
+		BEGIN
+			UPDATE index_generation SET generation = generation + 1;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS chunks_generation_subproject
+		AFTER UPDATE OF subproject_id, subproject_path ON chunks
+		BEGIN
+			UPDATE index_generation SET generation = generation + 1;
+		END`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create index generation: %w", err)
+		}
+	}
+
+	// Update schema version
+	if err := db.setSchemaVersion(ctx, 7); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	return nil
+}
+
+// migrateV8 rebuilds the FTS table with the identifiers column, which holds
+// the sub-words of camelCase, snake_case and dotted identifiers.
+func (db *DB) migrateV8(ctx context.Context) error {
+	if !db.columnExists(ctx, "chunks_fts", "identifiers") {
+		if _, err := db.Exec(ctx, `DROP TABLE IF EXISTS chunks_fts`); err != nil {
+			return fmt.Errorf("failed to drop FTS table: %w", err)
+		}
+		if err := db.CreateFTSTable(ctx); err != nil {
+			return fmt.Errorf("failed to create FTS table: %w", err)
+		}
+		if _, err := db.PopulateFTSFromChunks(ctx); err != nil {
+			return fmt.Errorf("failed to populate FTS table: %w", err)
+		}
+	}
+
+	// Update schema version
+	if err := db.setSchemaVersion(ctx, 8); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	return nil
+}
+
+// migrateV9 adds the is_test flag to files, set at index time for test
+// files so searches can include, exclude or select them. Files indexed
+// before this migration are flagged when the daemon next starts.
+func (db *DB) migrateV9(ctx context.Context) error {
+	if !db.columnExists(ctx, "files", "is_test") {
+		if _, err := db.Exec(ctx, `
+			ALTER TABLE files ADD COLUMN is_test INTEGER NOT NULL DEFAULT 0
+		`); err != nil {
+			return fmt.Errorf("failed to add is_test column: %w", err)
+		}
+	}
+
+	if _, err := db.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_files_is_test ON files(is_test)
+	`); err != nil {
+		return fmt.Errorf("failed to create files is_test index: %w", err)
+	}
+
+	// Update schema version
+	if err := db.setSchemaVersion(ctx, 9); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	return nil
+}
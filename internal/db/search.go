@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -16,10 +17,14 @@ var ErrChunkNotFound = errors.New("chunk not found")
 
 // SearchOptions specifies parameters for semantic search.
 type SearchOptions struct {
-	Embedding  []float32 // Query embedding vector
-	Limit      int       // Maximum number of results to return
-	Levels     []string  // Filter by chunk levels (e.g., "file", "method", "class")
-	PathPrefix string    // Filter by file path prefix
+	Embedding    []float32 // Query embedding vector
+	Limit        int       // Maximum number of results to return
+	Levels       []string  // Filter by chunk levels (e.g., "file", "method", "class")
+	Languages    []string  // Filter by chunk language (e.g., "go", "python")
+	SubprojectID string    // Filter by sub-project ID
+	PathPrefix   string    // Filter by file path prefix
+	FilePaths    []string  // Restrict to these files; nil means any file, empty means none
+	TestFiles    *bool     // Restrict to test files (true) or other files (false); nil means any file
 }
 
 // VectorResult represents a single search result with similarity distance.
@@ -28,7 +33,10 @@ type VectorResult struct {
 	Distance float32
 }
 
-// SearchChunks performs a semantic search with optional filtering by level and path.
+// SearchChunks performs a semantic search with optional filtering by level,
+// language, sub-project, path prefix, file and test file flag. Filters are applied to the chunk_embeddings
+// metadata columns inside the KNN scan, so up to Limit matching results are
+// returned however selective the filters are.
 // Results are ordered by distance (ascending - smaller is more similar).
 func (db *DB) SearchChunks(ctx context.Context, opts SearchOptions) ([]VectorResult, error) {
 	// Return empty slice for limit 0
@@ -42,97 +50,61 @@ func (db *DB) SearchChunks(ctx context.Context, opts SearchOptions) ([]VectorRes
 		return nil, fmt.Errorf("failed to serialize query embedding: %w", err)
 	}
 
-	// Check if we need filtering
-	hasLevelFilter := len(opts.Levels) > 0
-	hasPathFilter := opts.PathPrefix != ""
-
-	if !hasLevelFilter && !hasPathFilter {
-		// No filtering needed, use simple vector search
-		rows, err := db.Query(ctx, `
-			SELECT chunk_id, distance
-			FROM chunk_embeddings
-			WHERE embedding MATCH ?
-			ORDER BY distance
-			LIMIT ?
-		`, serialized, opts.Limit)
-		if err != nil {
-			return nil, fmt.Errorf("failed to search embeddings: %w", err)
-		}
-		defer rows.Close()
+	conditions := []string{"embedding MATCH ?", "k = ?"}
+	args := []any{serialized, opts.Limit}
 
-		return scanVectorResults(rows)
+	if len(opts.Levels) > 0 {
+		conditions = append(conditions, "level IN ("+placeholders(len(opts.Levels))+")")
+		for _, level := range opts.Levels {
+			args = append(args, level)
+		}
 	}
 
-	// Build a filtered query using a JOIN with the chunks and files tables
-	// We need to first get matching chunk IDs, then search within those
-	var whereConditions []string
-	var filterArgs []any
-
-	if hasLevelFilter {
-		placeholders := make([]string, len(opts.Levels))
-		for i, level := range opts.Levels {
-			placeholders[i] = "?"
-			filterArgs = append(filterArgs, level)
+	if len(opts.Languages) > 0 {
+		conditions = append(conditions, "language IN ("+placeholders(len(opts.Languages))+")")
+		for _, language := range opts.Languages {
+			args = append(args, language)
 		}
-		whereConditions = append(whereConditions, fmt.Sprintf("c.level IN (%s)", strings.Join(placeholders, ", ")))
 	}
 
-	if hasPathFilter {
-		whereConditions = append(whereConditions, "f.path LIKE ?")
-		filterArgs = append(filterArgs, opts.PathPrefix+"%")
+	if opts.SubprojectID != "" {
+		conditions = append(conditions, "subproject_id = ?")
+		args = append(args, opts.SubprojectID)
 	}
 
-	// Get all matching chunk IDs first
-	filterQuery := fmt.Sprintf(`
-		SELECT c.id
-		FROM chunks c
-		JOIN files f ON c.file_id = f.id
-		WHERE %s
-	`, strings.Join(whereConditions, " AND "))
-
-	filterRows, err := db.Query(ctx, filterQuery, filterArgs...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to filter chunks: %w", err)
+	if opts.PathPrefix != "" {
+		lower, upper := pathRangeArgs(opts.PathPrefix)
+		conditions = append(conditions, "file_path >= ?", "file_path <= ?")
+		args = append(args, lower, upper)
 	}
-	defer filterRows.Close()
 
-	var matchingChunkIDs []string
-	for filterRows.Next() {
-		var id string
-		if err := filterRows.Scan(&id); err != nil {
-			return nil, fmt.Errorf("failed to scan chunk ID: %w", err)
+	if opts.FilePaths != nil {
+		if len(opts.FilePaths) == 0 {
+			return []VectorResult{}, nil
 		}
-		matchingChunkIDs = append(matchingChunkIDs, id)
-	}
-
-	if err := filterRows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating chunk IDs: %w", err)
-	}
-
-	// If no chunks match the filter, return empty results
-	if len(matchingChunkIDs) == 0 {
-		return []VectorResult{}, nil
+		keys := make([]string, len(opts.FilePaths))
+		for i, path := range opts.FilePaths {
+			keys[i] = embeddingPathKey(path)
+		}
+		list, err := jsonList(keys)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, "file_path IN (SELECT value FROM json_each(?))")
+		args = append(args, list)
 	}
 
-	// Build IN clause for the embedding search
-	placeholders := make([]string, len(matchingChunkIDs))
-	searchArgs := make([]any, len(matchingChunkIDs)+2)
-	searchArgs[0] = serialized
-	searchArgs[1] = opts.Limit
-	for i, id := range matchingChunkIDs {
-		placeholders[i] = "?"
-		searchArgs[i+2] = id
+	if opts.TestFiles != nil {
+		conditions = append(conditions, "file_path IN (SELECT "+embeddingPathKeyColumn+" FROM files f WHERE f.is_test = ?)")
+		args = append(args, *opts.TestFiles)
 	}
 
-	searchQuery := fmt.Sprintf(`
+	rows, err := db.Query(ctx, `
 		SELECT chunk_id, distance
 		FROM chunk_embeddings
-		WHERE embedding MATCH ? AND k = ?
-		  AND chunk_id IN (%s)
+		WHERE `+strings.Join(conditions, " AND ")+`
 		ORDER BY distance
-	`, strings.Join(placeholders, ", "))
-
-	rows, err := db.Query(ctx, searchQuery, searchArgs...)
+	`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search embeddings: %w", err)
 	}
@@ -141,6 +113,22 @@ func (db *DB) SearchChunks(ctx context.Context, opts SearchOptions) ([]VectorRes
 	return scanVectorResults(rows)
 }
 
+// placeholders returns a comma-separated list of n "?" placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// jsonList encodes values as a JSON array, to be bound as a single parameter
+// and expanded with json_each. This avoids SQLite's bound variable limit for
+// long lists.
+func jsonList(values []string) (string, error) {
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode filter list: %w", err)
+	}
+	return string(encoded), nil
+}
+
 // scanVectorResults scans rows into a slice of VectorResult.
 func scanVectorResults(rows *sql.Rows) ([]VectorResult, error) {
 	var results []VectorResult
@@ -166,18 +154,12 @@ func scanVectorResults(rows *sql.Rows) ([]VectorResult, error) {
 
 // GetChunk retrieves a chunk by ID, returning ErrChunkNotFound if not found.
 func (db *DB) GetChunk(ctx context.Context, id string) (*models.Chunk, error) {
-	var chunk models.Chunk
-	var parentID sql.NullString
-	var filePath string
-	var level string
-
-	err := db.QueryRow(ctx, `
-		SELECT c.id, f.path, c.start_line, c.end_line, c.level, c.name, c.content, c.content_hash, c.parent_id
+	chunk, err := scanChunk(db.QueryRow(ctx, `
+		SELECT `+chunkColumns+`
 		FROM chunks c
 		JOIN files f ON c.file_id = f.id
 		WHERE c.id = ?
-	`, id).Scan(&chunk.ID, &filePath, &chunk.StartLine, &chunk.EndLine, &level, &chunk.Name, &chunk.Content, &chunk.ContentHash, &parentID)
-
+	`, id))
 	if err == sql.ErrNoRows {
 		return nil, ErrChunkNotFound
 	}
@@ -185,11 +167,5 @@ func (db *DB) GetChunk(ctx context.Context, id string) (*models.Chunk, error) {
 		return nil, fmt.Errorf("failed to get chunk: %w", err)
 	}
 
-	chunk.FilePath = filePath
-	chunk.Level = models.ChunkLevel(level)
-	if parentID.Valid {
-		chunk.ParentID = &parentID.String
-	}
-
-	return &chunk, nil
+	return chunk, nil
 }
@@ -2,9 +2,11 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/cgo"
 	"github.com/pommel-dev/pommel/internal/embedder"
 	"github.com/pommel-dev/pommel/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -620,6 +622,216 @@ func TestSearchChunks_Limit_WithFilters(t *testing.T) {
 	assert.Len(t, results, 3, "should return exactly 3 method-level results")
 }
 
+// =============================================================================
+// Metadata filter tests
+// =============================================================================
+
+// replaceTestFile indexes a file of method chunks written in language.
+func replaceTestFile(t *testing.T, ctx context.Context, db *DB, path, language string, contents ...string) {
+	t.Helper()
+	update := newTestFileUpdate(path, contents...)
+	update.Language = language
+	for _, chunk := range update.Chunks {
+		chunk.Language = language
+	}
+	_, err := db.ReplaceFile(ctx, update)
+	require.NoError(t, err)
+}
+
+// searchChunkPaths returns the file paths of the chunks found by a search.
+func searchChunkPaths(t *testing.T, ctx context.Context, db *DB, opts SearchOptions) []string {
+	t.Helper()
+	opts.Embedding = makeEmbedding(0.1)
+	if opts.Limit == 0 {
+		opts.Limit = 100
+	}
+	results, err := db.SearchChunks(ctx, opts)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, r := range results {
+		chunk, err := db.GetChunk(ctx, r.ChunkID)
+		require.NoError(t, err)
+		paths = append(paths, chunk.FilePath)
+	}
+	return paths
+}
+
+func TestSearchChunks_SelectiveFilterReturnsFullLimit(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	// Far more chunks outside the filter than SQLite allows as bound variables
+	contents := make([]string, 2000)
+	for i := range contents {
+		contents[i] = fmt.Sprintf("func vendored%d() {}", i)
+	}
+	replaceTestFile(t, ctx, db, "/project/vendor/lib.go", "go", contents...)
+	replaceTestFile(t, ctx, db, "/project/app/main.go", "go", contents[:20]...)
+
+	paths := searchChunkPaths(t, ctx, db, SearchOptions{
+		Limit:      10,
+		Levels:     []string{"method"},
+		PathPrefix: "/project/app/",
+	})
+	require.Len(t, paths, 10, "filtered search should fill the limit")
+	for _, path := range paths {
+		assert.Equal(t, "/project/app/main.go", path)
+	}
+}
+
+func TestSearchChunks_LanguageFilter(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	replaceTestFile(t, ctx, db, "/project/main.go", "go", "func main() {}")
+	replaceTestFile(t, ctx, db, "/project/main.py", "python", "def main(): pass")
+	replaceTestFile(t, ctx, db, "/project/main.ts", "typescript", "function main() {}")
+
+	assert.Equal(t, []string{"/project/main.py"},
+		searchChunkPaths(t, ctx, db, SearchOptions{Languages: []string{"python"}}))
+	assert.ElementsMatch(t, []string{"/project/main.go", "/project/main.ts"},
+		searchChunkPaths(t, ctx, db, SearchOptions{Languages: []string{"go", "typescript"}}))
+	assert.Empty(t, searchChunkPaths(t, ctx, db, SearchOptions{Languages: []string{"rust"}}))
+}
+
+func TestSearchChunks_SubprojectFilter(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	replaceTestFile(t, ctx, db, "/project/api/server.go", "go", "func serve() {}")
+	replaceTestFile(t, ctx, db, "/project/web/app.go", "go", "func render() {}")
+
+	require.NoError(t, db.SetFileSubprojects(ctx, map[string]*models.Subproject{
+		"/project/api/server.go": {ID: "api", Path: "api"},
+		"/project/web/app.go":    {ID: "web", Path: "web"},
+	}))
+	assert.Equal(t, []string{"/project/api/server.go"},
+		searchChunkPaths(t, ctx, db, SearchOptions{SubprojectID: "api"}))
+
+	// Retagging updates the stored embeddings too
+	require.NoError(t, db.SetFileSubprojects(ctx, map[string]*models.Subproject{
+		"/project/api/server.go": nil,
+	}))
+	assert.Empty(t, searchChunkPaths(t, ctx, db, SearchOptions{SubprojectID: "api"}))
+	assert.Equal(t, []string{"/project/web/app.go"},
+		searchChunkPaths(t, ctx, db, SearchOptions{SubprojectID: "web"}))
+}
+
+func TestSearchChunks_PathFilter_Boundaries(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	for _, path := range []string{
+		"src/main.go",             // 11 bytes
+		"src/main2.go",            // 12 bytes
+		"/project/src/fo",         // Shorter than the prefix below
+		"/project/src/foo/bar.go", // Under the prefix below
+		"/project/src/fop.go",
+	} {
+		replaceTestFile(t, ctx, db, path, "go", "func f() {}")
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"src/", []string{"src/main.go", "src/main2.go"}},
+		{"src/main2.go", []string{"src/main2.go"}},
+		{"src/main.go", []string{"src/main.go"}},
+		{"/project/src/foo", []string{"/project/src/foo/bar.go"}},
+		{"/project/src/fo", []string{"/project/src/fo", "/project/src/foo/bar.go", "/project/src/fop.go"}},
+		{"/project/src/foo/bar.go", []string{"/project/src/foo/bar.go"}},
+	}
+	for _, tt := range tests {
+		assert.ElementsMatch(t, tt.want,
+			searchChunkPaths(t, ctx, db, SearchOptions{PathPrefix: tt.prefix}), "prefix %q", tt.prefix)
+	}
+}
+
+func TestSearchChunks_TwelveBytePathKey(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	// An 11-byte path would get a 12-byte key from the sentinel alone
+	require.Len(t, "src/main.go", 11)
+	assert.Len(t, embeddingPathKey("src/main.go"), 13)
+	assert.Len(t, embeddingPathKey("src/main2.go"), 13)
+
+	replaceTestFile(t, ctx, db, "src/main.go", "go", "func f() {}")
+	replaceTestFile(t, ctx, db, "src/main2.go", "go", "func f() {}")
+	assert.ElementsMatch(t, []string{"src/main.go", "src/main2.go"},
+		searchChunkPaths(t, ctx, db, SearchOptions{PathPrefix: "src/"}))
+
+	// sqlite-vec itself still fails range filters over a 12-byte value. If this
+	// starts passing after an upgrade, the padding in embeddingPathKey can go.
+	serialized, err := sqlite_vec.SerializeFloat32(makeEmbedding(0.1))
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO chunk_embeddings (chunk_id, embedding, level, language, subproject_id, file_path)
+		VALUES ('raw', ?, '', '', '', ?)
+	`, serialized, "src/main.go\x01")
+	require.NoError(t, err)
+	_, err = db.SearchChunks(ctx, SearchOptions{Embedding: makeEmbedding(0.1), Limit: 10, PathPrefix: "src/"})
+	assert.ErrorContains(t, err, "Could not filter metadata fields")
+}
+
+func TestSearchChunks_FilePathsFilter(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	paths := []string{
+		"src/main.go",             // 11 bytes
+		"src/main2.go",            // 12 bytes
+		"/project/src/foo/bar.go", // Longer than the 12-byte inline limit
+		"/project/src/foo/bar.go.orig",
+	}
+	for _, path := range paths {
+		replaceTestFile(t, ctx, db, path, "go", "func f() {}")
+	}
+
+	tests := []struct {
+		files []string
+		want  []string
+	}{
+		{nil, paths},
+		{[]string{}, nil},
+		{[]string{"src/main.go", "src/main2.go"}, []string{"src/main.go", "src/main2.go"}},
+		{[]string{"/project/src/foo/bar.go"}, []string{"/project/src/foo/bar.go"}},
+		{[]string{"/project/src/foo/bar.go.orig", "missing.go"}, []string{"/project/src/foo/bar.go.orig"}},
+	}
+	for _, tt := range tests {
+		assert.ElementsMatch(t, tt.want,
+			searchChunkPaths(t, ctx, db, SearchOptions{FilePaths: tt.files}), "files %q", tt.files)
+	}
+}
+
+func TestSearchChunks_TestFilesFilter(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	replaceTestFile(t, ctx, db, "/project/retry.go", "go", "func Retry() {}")
+	update := newTestFileUpdate("/project/retry_test.go", "func TestRetry() {}")
+	update.IsTest = true
+	_, err := db.ReplaceFile(ctx, update)
+	require.NoError(t, err)
+	replaceTestFile(t, ctx, db, "src/a_test.go", "go", "func TestA() {}") // 11 bytes
+
+	tests, others := true, false
+	assert.Equal(t, []string{"/project/retry_test.go"},
+		searchChunkPaths(t, ctx, db, SearchOptions{TestFiles: &tests}))
+	assert.ElementsMatch(t, []string{"/project/retry.go", "src/a_test.go"},
+		searchChunkPaths(t, ctx, db, SearchOptions{TestFiles: &others}))
+
+	require.NoError(t, db.SetTestFiles(ctx, map[string]bool{"src/a_test.go": true, "missing.go": true}))
+	assert.ElementsMatch(t, []string{"/project/retry_test.go", "src/a_test.go"},
+		searchChunkPaths(t, ctx, db, SearchOptions{TestFiles: &tests}))
+
+	chunk, err := db.GetChunk(ctx, update.Chunks[0].ID)
+	require.NoError(t, err)
+	assert.True(t, chunk.IsTest)
+}
+
 // =============================================================================
 // TestGetChunk_Found - Retrieves existing chunk
 // =============================================================================
@@ -151,3 +151,126 @@ func (db *DB) SubprojectCount(ctx context.Context) (int64, error) {
 	}
 	return count, nil
 }
+
+// SetFileSubprojects tags the chunks of each file, and their embeddings, with
+// the subproject it belongs to, in a single transaction. Files mapped to nil
+// are untagged. Paths that aren't indexed are ignored.
+func (db *DB) SetFileSubprojects(ctx context.Context, assignments map[string]*models.Subproject) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	chunkStmt, err := tx.PrepareContext(ctx, `
+		UPDATE chunks SET subproject_id = ?1, subproject_path = ?2
+		WHERE file_id = (SELECT id FROM files WHERE path = ?3)
+		  AND (subproject_id IS NOT ?1 OR subproject_path IS NOT ?2)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare subproject update: %w", err)
+	}
+	defer chunkStmt.Close()
+
+	// Update embeddings one chunk at a time so vec0 can use its primary key
+	embeddingStmt, err := tx.PrepareContext(ctx, `
+		UPDATE chunk_embeddings SET subproject_id = ? WHERE chunk_id = ?
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare embedding subproject update: %w", err)
+	}
+	defer embeddingStmt.Close()
+
+	for path, sp := range assignments {
+		var id, spPath sql.NullString
+		if sp != nil {
+			id = sql.NullString{String: sp.ID, Valid: true}
+			spPath = sql.NullString{String: sp.Path, Valid: true}
+		}
+		result, err := chunkStmt.ExecContext(ctx, id, spPath, path)
+		if err != nil {
+			return fmt.Errorf("failed to tag chunks of %s: %w", path, err)
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			continue // Already tagged
+		}
+
+		chunkIDs, err := fileChunkIDsTx(ctx, tx, path)
+		if err != nil {
+			return err
+		}
+		for _, chunkID := range chunkIDs {
+			if _, err := embeddingStmt.ExecContext(ctx, id.String, chunkID); err != nil {
+				return fmt.Errorf("failed to tag embedding of chunk %s: %w", chunkID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// fileChunkIDsTx returns the IDs of the chunks indexed for path within a transaction.
+func fileChunkIDsTx(ctx context.Context, tx *sql.Tx, path string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT c.id FROM chunks c
+		JOIN files f ON c.file_id = f.id
+		WHERE f.path = ?
+	`, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chunk IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chunk IDs: %w", err)
+	}
+	return ids, nil
+}
+
+// SubprojectCounts holds the number of indexed files and chunks in a subproject.
+type SubprojectCounts struct {
+	Files  int64
+	Chunks int64
+}
+
+// SubprojectCounts returns indexed file and chunk counts keyed by subproject ID.
+// Subprojects without indexed chunks are absent from the map.
+func (db *DB) SubprojectCounts(ctx context.Context) (map[string]SubprojectCounts, error) {
+	rows, err := db.Query(ctx, `
+		SELECT subproject_id, COUNT(DISTINCT file_id), COUNT(*)
+		FROM chunks
+		WHERE subproject_id IS NOT NULL
+		GROUP BY subproject_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count subproject chunks: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]SubprojectCounts)
+	for rows.Next() {
+		var id string
+		var c SubprojectCounts
+		if err := rows.Scan(&id, &c.Files, &c.Chunks); err != nil {
+			return nil, fmt.Errorf("failed to scan subproject counts: %w", err)
+		}
+		counts[id] = c
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate subproject counts: %w", err)
+	}
+
+	return counts, nil
+}
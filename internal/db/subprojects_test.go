@@ -268,3 +268,45 @@ func TestSubprojectCount(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, int64(5), count)
 }
+
+// =============================================================================
+// Chunk Tagging Tests
+// =============================================================================
+
+func TestSetFileSubprojects(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	_, err := db.ReplaceFile(ctx, newTestFileUpdate("/project/api/server.go", "func a() {}", "func b() {}"))
+	require.NoError(t, err)
+	_, err = db.ReplaceFile(ctx, newTestFileUpdate("/project/main.go", "func main() {}"))
+	require.NoError(t, err)
+
+	api := &models.Subproject{ID: "api", Path: "api"}
+	require.NoError(t, db.SetFileSubprojects(ctx, map[string]*models.Subproject{
+		"/project/api/server.go": api,
+		"/project/main.go":       nil,
+		"/project/missing.go":    api, // Not indexed, ignored
+	}))
+
+	counts, err := db.SubprojectCounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]SubprojectCounts{"api": {Files: 1, Chunks: 2}}, counts)
+
+	ids, err := db.GetChunkIDsByFile(ctx, "/project/api/server.go")
+	require.NoError(t, err)
+	chunk, err := db.GetChunkByID(ctx, ids[0])
+	require.NoError(t, err)
+	require.NotNil(t, chunk.SubprojectID)
+	assert.Equal(t, "api", *chunk.SubprojectID)
+	require.NotNil(t, chunk.SubprojectPath)
+	assert.Equal(t, "api", *chunk.SubprojectPath)
+
+	// Untagging removes the file from the counts
+	require.NoError(t, db.SetFileSubprojects(ctx, map[string]*models.Subproject{
+		"/project/api/server.go": nil,
+	}))
+	counts, err = db.SubprojectCounts(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
@@ -2,7 +2,10 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/binary"
 	"fmt"
+	"math"
 	"strings"
 
 	sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/cgo"
@@ -11,6 +14,54 @@ import (
 // EmbeddingDimension is the dimensionality of the embedding vectors (Jina Code Embeddings).
 const EmbeddingDimension = 768
 
+// embeddingMetadataColumns selects the chunk_embeddings metadata columns for
+// a chunk c in file f. Missing values are stored as empty strings because
+// vec0 metadata columns can't hold NULL.
+//
+// file_path holds the file path followed by a \x01 sentinel, padded so it is
+// never exactly 12 bytes long. sqlite-vec compares long text metadata up to
+// the stored value's length, so the sentinel keeps path-prefix ranges exact.
+// sqlite-vec v0.1.6 stores text of up to 12 bytes inline, but the range
+// operators in vec0_metadata_filter_text only treat values shorter than 12
+// bytes as inline; any 12-byte value makes a range filter fail with "Could
+// not filter metadata fields". TestSearchChunks_TwelveBytePathKey pins both.
+// See pathRangeArgs and embeddingPathKey.
+const embeddingMetadataColumns = `
+	COALESCE(c.level, ''),
+	COALESCE(c.language, f.language, ''),
+	COALESCE(c.subproject_id, ''),
+	` + embeddingPathKeyColumn
+
+// embeddingPathKeyColumn is embeddingPathKey of the path of a file f, in SQL.
+const embeddingPathKeyColumn = `CASE WHEN length(CAST(COALESCE(f.path, '') AS BLOB)) = 11
+		THEN COALESCE(f.path, '') || char(1, 1)
+		ELSE COALESCE(f.path, '') || char(1)
+	END`
+
+// insertEmbeddingSQL inserts the embedding for chunk ?1, copying its filter
+// metadata from the chunks and files tables.
+const insertEmbeddingSQL = `
+	INSERT INTO chunk_embeddings (chunk_id, embedding, level, language, subproject_id, file_path)
+	SELECT ?1, ?2, ` + embeddingMetadataColumns + `
+	FROM (SELECT 1)
+	LEFT JOIN chunks c ON c.id = ?1
+	LEFT JOIN files f ON f.id = c.file_id
+`
+
+// pathRangeArgs returns the bounds of the file_path metadata range matching
+// every file whose path starts with prefix.
+func pathRangeArgs(prefix string) (lower, upper string) {
+	return prefix, prefix + "\xff"
+}
+
+// embeddingPathKey returns the file_path metadata value stored for a path.
+func embeddingPathKey(path string) string {
+	if len(path) == 11 {
+		return path + "\x01\x01"
+	}
+	return path + "\x01"
+}
+
 // VectorSearchResult represents a single result from a similarity search.
 type VectorSearchResult struct {
 	ChunkID  string
@@ -19,6 +70,8 @@ type VectorSearchResult struct {
 
 // InsertEmbedding inserts a single embedding for a chunk.
 // If an embedding with the same chunk_id exists, it replaces it.
+// The chunk's level, language, sub-project and file path are stored
+// alongside the vector for filtered search.
 func (db *DB) InsertEmbedding(ctx context.Context, chunkID string, embedding []float32) error {
 	serialized, err := sqlite_vec.SerializeFloat32(embedding)
 	if err != nil {
@@ -32,10 +85,7 @@ func (db *DB) InsertEmbedding(ctx context.Context, chunkID string, embedding []f
 		return fmt.Errorf("failed to delete existing embedding: %w", err)
 	}
 
-	_, err = db.Exec(ctx, `
-		INSERT INTO chunk_embeddings (chunk_id, embedding)
-		VALUES (?, ?)
-	`, chunkID, serialized)
+	_, err = db.Exec(ctx, insertEmbeddingSQL, chunkID, serialized)
 	if err != nil {
 		return fmt.Errorf("failed to insert embedding: %w", err)
 	}
@@ -60,6 +110,23 @@ func (db *DB) InsertEmbeddings(ctx context.Context, chunkIDs []string, embedding
 	}
 	defer tx.Rollback()
 
+	if err := insertEmbeddingsTx(ctx, tx, chunkIDs, embeddings); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertEmbeddingsTx inserts or replaces embeddings within a transaction.
+func insertEmbeddingsTx(ctx context.Context, tx *sql.Tx, chunkIDs []string, embeddings [][]float32) error {
+	if len(chunkIDs) == 0 {
+		return nil
+	}
+
 	// sqlite-vec's vec0 virtual table doesn't support INSERT OR REPLACE directly,
 	// so we delete first then insert
 	deleteStmt, err := tx.PrepareContext(ctx, `DELETE FROM chunk_embeddings WHERE chunk_id = ?`)
@@ -68,10 +135,7 @@ func (db *DB) InsertEmbeddings(ctx context.Context, chunkIDs []string, embedding
 	}
 	defer deleteStmt.Close()
 
-	insertStmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO chunk_embeddings (chunk_id, embedding)
-		VALUES (?, ?)
-	`)
+	insertStmt, err := tx.PrepareContext(ctx, insertEmbeddingSQL)
 	if err != nil {
 		return fmt.Errorf("failed to prepare insert statement: %w", err)
 	}
@@ -94,10 +158,6 @@ func (db *DB) InsertEmbeddings(ctx context.Context, chunkIDs []string, embedding
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
@@ -144,6 +204,119 @@ func (db *DB) DeleteEmbeddingsByChunkIDs(ctx context.Context, chunkIDs []string)
 	return nil
 }
 
+// GetFileEmbeddingsByContentHash returns the stored embeddings for a file's
+// chunks, keyed by chunk content hash. Chunks without an embedding are omitted.
+func (db *DB) GetFileEmbeddingsByContentHash(ctx context.Context, path string) (map[string][]float32, error) {
+	rows, err := db.Query(ctx, `
+		SELECT c.id, c.content_hash
+		FROM chunks c
+		JOIN files f ON c.file_id = f.id
+		WHERE f.path = ?
+	`, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chunks: %w", err)
+	}
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var id, hash string
+		if err := rows.Scan(&id, &hash); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		hashes[id] = hash
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chunks: %w", err)
+	}
+
+	embeddings := make(map[string][]float32, len(hashes))
+	if len(hashes) == 0 {
+		return embeddings, nil
+	}
+
+	// Look up one chunk at a time so vec0 can use its primary key
+	stmt, err := db.conn.PrepareContext(ctx, `SELECT embedding FROM chunk_embeddings WHERE chunk_id = ?`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare embedding query: %w", err)
+	}
+	defer stmt.Close()
+
+	for id, hash := range hashes {
+		if _, ok := embeddings[hash]; ok {
+			continue
+		}
+
+		var blob []byte
+		if err := stmt.QueryRowContext(ctx, id).Scan(&blob); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get embedding for chunk %s: %w", id, err)
+		}
+		embeddings[hash] = deserializeFloat32(blob)
+	}
+
+	return embeddings, nil
+}
+
+// GetEmbedding returns the stored embedding for a chunk.
+// Returns nil without an error if the chunk has no embedding.
+func (db *DB) GetEmbedding(ctx context.Context, chunkID string) ([]float32, error) {
+	var blob []byte
+	err := db.QueryRow(ctx, `SELECT embedding FROM chunk_embeddings WHERE chunk_id = ?`, chunkID).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding: %w", err)
+	}
+	return deserializeFloat32(blob), nil
+}
+
+// GetEmbeddings returns the stored embeddings for chunks, keyed by chunk ID.
+// Chunks without an embedding are omitted.
+func (db *DB) GetEmbeddings(ctx context.Context, chunkIDs []string) (map[string][]float32, error) {
+	embeddings := make(map[string][]float32, len(chunkIDs))
+	if len(chunkIDs) == 0 {
+		return embeddings, nil
+	}
+
+	// Look up one chunk at a time so vec0 can use its primary key
+	stmt, err := db.conn.PrepareContext(ctx, `SELECT embedding FROM chunk_embeddings WHERE chunk_id = ?`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare embedding query: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, id := range chunkIDs {
+		if _, ok := embeddings[id]; ok {
+			continue
+		}
+
+		var blob []byte
+		if err := stmt.QueryRowContext(ctx, id).Scan(&blob); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get embedding for chunk %s: %w", id, err)
+		}
+		embeddings[id] = deserializeFloat32(blob)
+	}
+
+	return embeddings, nil
+}
+
+// deserializeFloat32 decodes a vector stored by sqlite-vec as little-endian float32s.
+func deserializeFloat32(blob []byte) []float32 {
+	vector := make([]float32, len(blob)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[i*4:]))
+	}
+	return vector
+}
+
 // EmbeddingCount returns the total number of embeddings stored.
 func (db *DB) EmbeddingCount(ctx context.Context) (int, error) {
 	var count int
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/pommel-dev/pommel/internal/models"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -69,6 +72,40 @@ func TestInsertEmbeddings_Batch(t *testing.T) {
 	assert.Equal(t, 3, count)
 }
 
+func TestGetEmbedding(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	embedding := makeEmbedding(0.4)
+	require.NoError(t, db.InsertEmbedding(ctx, "chunk-1", embedding))
+
+	got, err := db.GetEmbedding(ctx, "chunk-1")
+	require.NoError(t, err)
+	assert.Equal(t, embedding, got)
+
+	got, err = db.GetEmbedding(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestGetEmbeddings(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, db.InsertEmbeddings(ctx, []string{"chunk-1", "chunk-2"}, [][]float32{makeEmbedding(0.1), makeEmbedding(0.2)}))
+
+	got, err := db.GetEmbeddings(ctx, []string{"chunk-1", "chunk-2", "missing", "chunk-1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]float32{
+		"chunk-1": makeEmbedding(0.1),
+		"chunk-2": makeEmbedding(0.2),
+	}, got)
+
+	got, err = db.GetEmbeddings(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
 func TestInsertEmbedding_Replace(t *testing.T) {
 	db := setupTestDB(t)
 	ctx := context.Background()
@@ -469,3 +506,31 @@ func TestInsertEmbeddings_Empty(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, count)
 }
+
+func TestGetFileEmbeddingsByContentHash(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	fileID, err := db.InsertFile(ctx, "/src/reuse.go", "hash", "go", 100, time.Now())
+	require.NoError(t, err)
+
+	embedded := &models.Chunk{FilePath: "/src/reuse.go", Level: models.ChunkLevelMethod, Name: "a", Content: "func a() {}", StartLine: 1, EndLine: 1}
+	embedded.SetHashes()
+	pending := &models.Chunk{FilePath: "/src/reuse.go", Level: models.ChunkLevelMethod, Name: "b", Content: "func b() {}", StartLine: 3, EndLine: 3}
+	pending.SetHashes()
+	require.NoError(t, db.InsertChunk(ctx, embedded, fileID))
+	require.NoError(t, db.InsertChunk(ctx, pending, fileID))
+	require.NoError(t, db.InsertEmbedding(ctx, embedded.ID, makeEmbedding(0.5)))
+
+	embeddings, err := db.GetFileEmbeddingsByContentHash(ctx, "/src/reuse.go")
+	require.NoError(t, err)
+
+	// Only chunks with a stored embedding are returned, keyed by content hash
+	require.Len(t, embeddings, 1)
+	assert.Equal(t, makeEmbedding(0.5), embeddings[embedded.ContentHash])
+
+	// Unknown files have no embeddings
+	embeddings, err = db.GetFileEmbeddingsByContentHash(ctx, "/src/missing.go")
+	require.NoError(t, err)
+	assert.Empty(t, embeddings)
+}
@@ -114,10 +114,19 @@ func NewCachedEmbedder(embedder Embedder, capacity int) *CachedEmbedder {
 	}
 }
 
-// EmbedSingle generates an embedding for a single text, using the cache if available.
-func (c *CachedEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+// Cache key prefixes. Queries and documents are cached apart because a model
+// may embed the same text differently as each.
+const (
+	queryKeyPrefix    = "q:"
+	documentKeyPrefix = "d:"
+)
+
+// EmbedQuery generates an embedding for a query, using the cache if available.
+func (c *CachedEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	key := queryKeyPrefix + query
+
 	// Check cache first
-	if embedding := c.cache.Get(text); embedding != nil {
+	if embedding := c.cache.Get(key); embedding != nil {
 		c.mu.Lock()
 		c.metrics.Hits++
 		c.mu.Unlock()
@@ -129,26 +138,26 @@ func (c *CachedEmbedder) EmbedSingle(ctx context.Context, text string) ([]float3
 	c.metrics.Misses++
 	c.mu.Unlock()
 
-	embedding, err := c.embedder.EmbedSingle(ctx, text)
+	embedding, err := c.embedder.EmbedQuery(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
 	// Store in cache
-	c.cache.Put(text, embedding)
+	c.cache.Put(key, embedding)
 
 	return embedding, nil
 }
 
-// Embed generates embeddings for multiple texts, using the cache where available.
-func (c *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+// EmbedDocuments generates embeddings for multiple documents, using the cache where available.
+func (c *CachedEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
 	results := make([][]float32, len(texts))
 	var uncachedTexts []string
 	var uncachedIndices []int
 
 	// Check cache for each text
 	for i, text := range texts {
-		if embedding := c.cache.Get(text); embedding != nil {
+		if embedding := c.cache.Get(documentKeyPrefix + text); embedding != nil {
 			c.mu.Lock()
 			c.metrics.Hits++
 			c.mu.Unlock()
@@ -168,7 +177,7 @@ func (c *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32
 	}
 
 	// Embed only the uncached texts
-	embeddings, err := c.embedder.Embed(ctx, uncachedTexts)
+	embeddings, err := c.embedder.EmbedDocuments(ctx, uncachedTexts)
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +187,7 @@ func (c *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32
 		idx := uncachedIndices[i]
 		text := uncachedTexts[i]
 		results[idx] = embedding
-		c.cache.Put(text, embedding)
+		c.cache.Put(documentKeyPrefix+text, embedding)
 	}
 
 	return results, nil
@@ -199,6 +208,11 @@ func (c *CachedEmbedder) Dimensions() int {
 	return c.embedder.Dimensions()
 }
 
+// DocumentPrefix delegates to the underlying embedder.
+func (c *CachedEmbedder) DocumentPrefix() string {
+	return DocumentPrefix(c.embedder)
+}
+
 // Metrics returns cache hit/miss statistics.
 func (c *CachedEmbedder) Metrics() CacheMetrics {
 	c.mu.RLock()
@@ -17,10 +17,10 @@ import (
 // TrackingMockEmbedder wraps MockEmbedder to track call counts for testing cache behavior.
 type TrackingMockEmbedder struct {
 	*MockEmbedder
-	embedSingleCalls atomic.Int64
-	embedCalls       atomic.Int64
-	lastEmbedTexts   []string
-	mu               sync.Mutex
+	queryCalls     atomic.Int64
+	documentCalls  atomic.Int64
+	lastEmbedTexts []string
+	mu             sync.Mutex
 }
 
 func NewTrackingMockEmbedder() *TrackingMockEmbedder {
@@ -29,26 +29,26 @@ func NewTrackingMockEmbedder() *TrackingMockEmbedder {
 	}
 }
 
-func (t *TrackingMockEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
-	t.embedSingleCalls.Add(1)
-	return t.MockEmbedder.EmbedSingle(ctx, text)
+func (t *TrackingMockEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	t.queryCalls.Add(1)
+	return t.MockEmbedder.EmbedQuery(ctx, query)
 }
 
-func (t *TrackingMockEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
-	t.embedCalls.Add(1)
+func (t *TrackingMockEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	t.documentCalls.Add(1)
 	t.mu.Lock()
 	t.lastEmbedTexts = make([]string, len(texts))
 	copy(t.lastEmbedTexts, texts)
 	t.mu.Unlock()
-	return t.MockEmbedder.Embed(ctx, texts)
+	return t.MockEmbedder.EmbedDocuments(ctx, texts)
 }
 
-func (t *TrackingMockEmbedder) EmbedSingleCallCount() int64 {
-	return t.embedSingleCalls.Load()
+func (t *TrackingMockEmbedder) QueryCallCount() int64 {
+	return t.queryCalls.Load()
 }
 
-func (t *TrackingMockEmbedder) EmbedCallCount() int64 {
-	return t.embedCalls.Load()
+func (t *TrackingMockEmbedder) DocumentsCallCount() int64 {
+	return t.documentCalls.Load()
 }
 
 func (t *TrackingMockEmbedder) LastEmbedTexts() []string {
@@ -87,12 +87,12 @@ func TestCachedEmbedder_CacheMiss(t *testing.T) {
 	ctx := context.Background()
 	text := "func hello() { return 42 }"
 
-	embedding, err := cached.EmbedSingle(ctx, text)
+	embedding, err := cached.EmbedQuery(ctx, text)
 
-	require.NoError(t, err, "EmbedSingle should not return error")
+	require.NoError(t, err, "EmbedQuery should not return error")
 	require.NotNil(t, embedding, "Embedding should not be nil")
 	assert.Len(t, embedding, 768, "Embedding should have 768 dimensions")
-	assert.Equal(t, int64(1), underlying.EmbedSingleCallCount(),
+	assert.Equal(t, int64(1), underlying.QueryCallCount(),
 		"Underlying embedder should be called once on cache miss")
 }
 
@@ -107,13 +107,13 @@ func TestCachedEmbedder_CacheHit(t *testing.T) {
 	text := "func hello() { return 42 }"
 
 	// First call - cache miss
-	embedding1, err1 := cached.EmbedSingle(ctx, text)
-	require.NoError(t, err1, "First EmbedSingle should not return error")
+	embedding1, err1 := cached.EmbedQuery(ctx, text)
+	require.NoError(t, err1, "First EmbedQuery should not return error")
 	require.NotNil(t, embedding1, "First embedding should not be nil")
 
 	// Second call - should be cache hit
-	embedding2, err2 := cached.EmbedSingle(ctx, text)
-	require.NoError(t, err2, "Second EmbedSingle should not return error")
+	embedding2, err2 := cached.EmbedQuery(ctx, text)
+	require.NoError(t, err2, "Second EmbedQuery should not return error")
 	require.NotNil(t, embedding2, "Second embedding should not be nil")
 
 	// Verify embeddings are identical
@@ -121,7 +121,7 @@ func TestCachedEmbedder_CacheHit(t *testing.T) {
 		"Cached embedding should be identical to original")
 
 	// Underlying embedder should only be called once
-	assert.Equal(t, int64(1), underlying.EmbedSingleCallCount(),
+	assert.Equal(t, int64(1), underlying.QueryCallCount(),
 		"Underlying embedder should be called only once (second call was cache hit)")
 }
 
@@ -141,23 +141,23 @@ func TestCachedEmbedder_EmbedMultiple_AllCached(t *testing.T) {
 
 	// First, cache all texts individually
 	for _, text := range texts {
-		_, err := cached.EmbedSingle(ctx, text)
-		require.NoError(t, err, "EmbedSingle should not return error")
+		_, err := cached.EmbedDocuments(ctx, []string{text})
+		require.NoError(t, err, "EmbedDocuments should not return error")
 	}
 
-	initialCalls := underlying.EmbedSingleCallCount()
+	initialCalls := underlying.DocumentsCallCount()
 
-	// Now call Embed with all texts - should all be cached
-	embeddings, err := cached.Embed(ctx, texts)
+	// Now call EmbedDocuments with all texts - should all be cached
+	embeddings, err := cached.EmbedDocuments(ctx, texts)
 
-	require.NoError(t, err, "Embed should not return error")
+	require.NoError(t, err, "EmbedDocuments should not return error")
 	require.Len(t, embeddings, 3, "Should return 3 embeddings")
 
 	// No additional calls to underlying embedder
-	assert.Equal(t, int64(0), underlying.EmbedCallCount(),
-		"Underlying Embed should not be called when all texts are cached")
-	assert.Equal(t, initialCalls, underlying.EmbedSingleCallCount(),
-		"Underlying EmbedSingle should not be called when all texts are cached")
+	assert.Equal(t, initialCalls, underlying.DocumentsCallCount(),
+		"Underlying EmbedDocuments should not be called when all texts are cached")
+	assert.Equal(t, int64(0), underlying.QueryCallCount(),
+		"Underlying EmbedQuery should not be called for documents")
 }
 
 // TestCachedEmbedder_EmbedMultiple_PartialCache verifies that when some texts are
@@ -172,8 +172,8 @@ func TestCachedEmbedder_EmbedMultiple_PartialCache(t *testing.T) {
 	// Pre-cache some texts
 	cachedTexts := []string{"func cached1() {}", "func cached2() {}"}
 	for _, text := range cachedTexts {
-		_, err := cached.EmbedSingle(ctx, text)
-		require.NoError(t, err, "EmbedSingle should not return error")
+		_, err := cached.EmbedDocuments(ctx, []string{text})
+		require.NoError(t, err, "EmbedDocuments should not return error")
 	}
 
 	// Request mix of cached and uncached
@@ -184,9 +184,9 @@ func TestCachedEmbedder_EmbedMultiple_PartialCache(t *testing.T) {
 		"func uncached2() {}", // not cached
 	}
 
-	embeddings, err := cached.Embed(ctx, allTexts)
+	embeddings, err := cached.EmbedDocuments(ctx, allTexts)
 
-	require.NoError(t, err, "Embed should not return error")
+	require.NoError(t, err, "EmbedDocuments should not return error")
 	require.Len(t, embeddings, 4, "Should return 4 embeddings")
 
 	// Only the uncached texts should have been sent to underlying embedder
@@ -199,6 +199,26 @@ func TestCachedEmbedder_EmbedMultiple_PartialCache(t *testing.T) {
 		"Uncached text 2 should be sent to embedder")
 }
 
+// TestCachedEmbedder_QueriesAndDocumentsCachedApart verifies that a cached
+// document embedding is not returned for a query with the same text, since
+// the model may embed the two differently.
+func TestCachedEmbedder_QueriesAndDocumentsCachedApart(t *testing.T) {
+	underlying := NewTrackingMockEmbedder()
+	cached := NewCachedEmbedder(underlying, 100)
+	ctx := context.Background()
+	text := "func retry() {}"
+
+	_, err := cached.EmbedDocuments(ctx, []string{text})
+	require.NoError(t, err)
+	_, err = cached.EmbedQuery(ctx, text)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), underlying.DocumentsCallCount())
+	assert.Equal(t, int64(1), underlying.QueryCallCount(),
+		"A query should not be served from the document cache")
+	assert.Equal(t, 2, cached.CacheSize())
+}
+
 // TestCachedEmbedder_LRUEviction verifies that when the cache reaches capacity,
 // the least recently used entries are evicted.
 func TestCachedEmbedder_LRUEviction(t *testing.T) {
@@ -212,28 +232,28 @@ func TestCachedEmbedder_LRUEviction(t *testing.T) {
 	// Fill the cache to capacity
 	texts := []string{"text1", "text2", "text3"}
 	for _, text := range texts {
-		_, err := cached.EmbedSingle(ctx, text)
-		require.NoError(t, err, "EmbedSingle should not return error")
+		_, err := cached.EmbedQuery(ctx, text)
+		require.NoError(t, err, "EmbedQuery should not return error")
 	}
 
 	assert.Equal(t, capacity, cached.CacheSize(),
 		"Cache should be at capacity")
 
 	// Add one more - should evict "text1" (oldest)
-	_, err := cached.EmbedSingle(ctx, "text4")
-	require.NoError(t, err, "EmbedSingle should not return error")
+	_, err := cached.EmbedQuery(ctx, "text4")
+	require.NoError(t, err, "EmbedQuery should not return error")
 
 	assert.Equal(t, capacity, cached.CacheSize(),
 		"Cache should still be at capacity after eviction")
 
 	// Reset call count
-	callsBefore := underlying.EmbedSingleCallCount()
+	callsBefore := underlying.QueryCallCount()
 
 	// "text1" should be evicted, so accessing it should cause a cache miss
-	_, err = cached.EmbedSingle(ctx, "text1")
-	require.NoError(t, err, "EmbedSingle should not return error")
+	_, err = cached.EmbedQuery(ctx, "text1")
+	require.NoError(t, err, "EmbedQuery should not return error")
 
-	assert.Equal(t, callsBefore+1, underlying.EmbedSingleCallCount(),
+	assert.Equal(t, callsBefore+1, underlying.QueryCallCount(),
 		"Accessing evicted entry should cause cache miss")
 }
 
@@ -249,30 +269,30 @@ func TestCachedEmbedder_LRUAccess(t *testing.T) {
 
 	// Fill the cache: text1, text2, text3 (text1 is oldest)
 	for _, text := range []string{"text1", "text2", "text3"} {
-		_, err := cached.EmbedSingle(ctx, text)
-		require.NoError(t, err, "EmbedSingle should not return error")
+		_, err := cached.EmbedQuery(ctx, text)
+		require.NoError(t, err, "EmbedQuery should not return error")
 	}
 
 	// Access text1 - moves it to front (most recently used)
-	_, err := cached.EmbedSingle(ctx, "text1")
-	require.NoError(t, err, "EmbedSingle should not return error")
+	_, err := cached.EmbedQuery(ctx, "text1")
+	require.NoError(t, err, "EmbedQuery should not return error")
 
 	// Now text2 is the oldest. Add text4 - should evict text2 (not text1)
-	_, err = cached.EmbedSingle(ctx, "text4")
-	require.NoError(t, err, "EmbedSingle should not return error")
+	_, err = cached.EmbedQuery(ctx, "text4")
+	require.NoError(t, err, "EmbedQuery should not return error")
 
-	callsBefore := underlying.EmbedSingleCallCount()
+	callsBefore := underlying.QueryCallCount()
 
 	// text1 should still be cached (we accessed it, moving it to front)
-	_, err = cached.EmbedSingle(ctx, "text1")
-	require.NoError(t, err, "EmbedSingle should not return error")
-	assert.Equal(t, callsBefore, underlying.EmbedSingleCallCount(),
+	_, err = cached.EmbedQuery(ctx, "text1")
+	require.NoError(t, err, "EmbedQuery should not return error")
+	assert.Equal(t, callsBefore, underlying.QueryCallCount(),
 		"text1 should still be cached after LRU access")
 
 	// text2 should be evicted
-	_, err = cached.EmbedSingle(ctx, "text2")
-	require.NoError(t, err, "EmbedSingle should not return error")
-	assert.Equal(t, callsBefore+1, underlying.EmbedSingleCallCount(),
+	_, err = cached.EmbedQuery(ctx, "text2")
+	require.NoError(t, err, "EmbedQuery should not return error")
+	assert.Equal(t, callsBefore+1, underlying.QueryCallCount(),
 		"text2 should have been evicted (cache miss)")
 }
 
@@ -291,25 +311,25 @@ func TestCachedEmbedder_Metrics_HitsAndMisses(t *testing.T) {
 	assert.Equal(t, int64(0), metrics.Misses, "Initial misses should be 0")
 
 	// First call - cache miss
-	_, _ = cached.EmbedSingle(ctx, "text1")
+	_, _ = cached.EmbedQuery(ctx, "text1")
 	metrics = cached.Metrics()
 	assert.Equal(t, int64(0), metrics.Hits, "Hits should still be 0")
 	assert.Equal(t, int64(1), metrics.Misses, "Misses should be 1")
 
 	// Second call same text - cache hit
-	_, _ = cached.EmbedSingle(ctx, "text1")
+	_, _ = cached.EmbedQuery(ctx, "text1")
 	metrics = cached.Metrics()
 	assert.Equal(t, int64(1), metrics.Hits, "Hits should be 1")
 	assert.Equal(t, int64(1), metrics.Misses, "Misses should still be 1")
 
 	// Third call different text - cache miss
-	_, _ = cached.EmbedSingle(ctx, "text2")
+	_, _ = cached.EmbedQuery(ctx, "text2")
 	metrics = cached.Metrics()
 	assert.Equal(t, int64(1), metrics.Hits, "Hits should still be 1")
 	assert.Equal(t, int64(2), metrics.Misses, "Misses should be 2")
 
 	// Fourth call same as first - cache hit
-	_, _ = cached.EmbedSingle(ctx, "text1")
+	_, _ = cached.EmbedQuery(ctx, "text1")
 	metrics = cached.Metrics()
 	assert.Equal(t, int64(2), metrics.Hits, "Hits should be 2")
 	assert.Equal(t, int64(2), metrics.Misses, "Misses should still be 2")
@@ -328,15 +348,15 @@ func TestCachedEmbedder_CacheSize(t *testing.T) {
 	assert.Equal(t, 0, cached.CacheSize(), "Initial cache size should be 0")
 
 	// Add one entry
-	_, _ = cached.EmbedSingle(ctx, "text1")
+	_, _ = cached.EmbedQuery(ctx, "text1")
 	assert.Equal(t, 1, cached.CacheSize(), "Cache size should be 1")
 
 	// Add another entry
-	_, _ = cached.EmbedSingle(ctx, "text2")
+	_, _ = cached.EmbedQuery(ctx, "text2")
 	assert.Equal(t, 2, cached.CacheSize(), "Cache size should be 2")
 
 	// Accessing existing entry should not change size
-	_, _ = cached.EmbedSingle(ctx, "text1")
+	_, _ = cached.EmbedQuery(ctx, "text1")
 	assert.Equal(t, 2, cached.CacheSize(), "Cache size should still be 2 after cache hit")
 }
 
@@ -351,8 +371,8 @@ func TestCachedEmbedder_ClearCache(t *testing.T) {
 
 	// Add some entries
 	for _, text := range []string{"text1", "text2", "text3"} {
-		_, err := cached.EmbedSingle(ctx, text)
-		require.NoError(t, err, "EmbedSingle should not return error")
+		_, err := cached.EmbedQuery(ctx, text)
+		require.NoError(t, err, "EmbedQuery should not return error")
 	}
 	assert.Equal(t, 3, cached.CacheSize(), "Cache should have 3 entries")
 
@@ -362,9 +382,9 @@ func TestCachedEmbedder_ClearCache(t *testing.T) {
 	assert.Equal(t, 0, cached.CacheSize(), "Cache should be empty after clear")
 
 	// All entries should now be cache misses
-	callsBefore := underlying.EmbedSingleCallCount()
-	_, _ = cached.EmbedSingle(ctx, "text1")
-	assert.Equal(t, callsBefore+1, underlying.EmbedSingleCallCount(),
+	callsBefore := underlying.QueryCallCount()
+	_, _ = cached.EmbedQuery(ctx, "text1")
+	assert.Equal(t, callsBefore+1, underlying.QueryCallCount(),
 		"Previously cached entry should now be a cache miss")
 }
 
@@ -442,8 +462,8 @@ func TestCachedEmbedder_Concurrent(t *testing.T) {
 			defer wg.Done()
 			for j := 0; j < numIterations; j++ {
 				text := texts[(id+j)%len(texts)]
-				embedding, err := cached.EmbedSingle(ctx, text)
-				assert.NoError(t, err, "Concurrent EmbedSingle should not error")
+				embedding, err := cached.EmbedQuery(ctx, text)
+				assert.NoError(t, err, "Concurrent EmbedQuery should not error")
 				assert.NotNil(t, embedding, "Concurrent embedding should not be nil")
 				assert.Len(t, embedding, 768, "Embedding should have 768 dimensions")
 			}
@@ -453,8 +473,8 @@ func TestCachedEmbedder_Concurrent(t *testing.T) {
 	wg.Wait()
 
 	// Verify cache is consistent - same text should produce same embedding
-	embedding1, _ := cached.EmbedSingle(ctx, "text1")
-	embedding2, _ := cached.EmbedSingle(ctx, "text1")
+	embedding1, _ := cached.EmbedQuery(ctx, "text1")
+	embedding2, _ := cached.EmbedQuery(ctx, "text1")
 	assert.Equal(t, embedding1, embedding2,
 		"Cached embeddings should be consistent after concurrent access")
 }
@@ -489,7 +509,7 @@ func TestLRUCache_Concurrent(t *testing.T) {
 			for j := 0; j < numIterations; j++ {
 				// Use many different texts to cause evictions
 				text := "text" + string(rune('A'+id%26)) + string(rune('0'+j%10))
-				embedding, err := cached.EmbedSingle(ctx, text)
+				embedding, err := cached.EmbedQuery(ctx, text)
 				if err != nil {
 					errors <- err
 					return
@@ -2,14 +2,34 @@ package embedder
 
 import "context"
 
-// Embedder generates vector embeddings from text
+// Embedder generates vector embeddings from text. Queries and documents are
+// embedded separately because many models expect a different instruction or
+// input type for each; symmetric models embed both the same way.
 type Embedder interface {
-	EmbedSingle(ctx context.Context, text string) ([]float32, error)
-	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// EmbedQuery generates an embedding for a search query.
+	EmbedQuery(ctx context.Context, query string) ([]float32, error)
+	// EmbedDocuments generates embeddings for content to be indexed.
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
 	Health(ctx context.Context) error
 	ModelName() string
 	Dimensions() int
 }
 
+// documentPrefixer is implemented by embedders that prepend a task prefix to
+// indexed content.
+type documentPrefixer interface {
+	DocumentPrefix() string
+}
+
+// DocumentPrefix returns the prefix e prepends to indexed content, or "" if
+// it embeds content as is. Stored embeddings are only comparable with new
+// ones made with the same prefix.
+func DocumentPrefix(e Embedder) string {
+	if p, ok := e.(documentPrefixer); ok {
+		return p.DocumentPrefix()
+	}
+	return ""
+}
+
 // Compile-time check that OllamaClient implements Embedder
 var _ Embedder = (*OllamaClient)(nil)
@@ -30,8 +30,8 @@ func TestEmbedder_InterfaceMethods(t *testing.T) {
 	// If the interface changes, the compile-time check in embedder.go will fail.
 	//
 	// Expected methods:
-	// - EmbedSingle(ctx context.Context, text string) ([]float32, error)
-	// - Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// - EmbedQuery(ctx context.Context, query string) ([]float32, error)
+	// - EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
 	// - Health(ctx context.Context) error
 	// - ModelName() string
 	// - Dimensions() int
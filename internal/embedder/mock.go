@@ -43,6 +43,18 @@ func (m *MockEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32,
 	return m.generateDeterministic(text), nil
 }
 
+// EmbedQuery generates a deterministic embedding for a query. The mock embeds
+// queries and documents the same way, so a query equal to a document's
+// content finds it exactly.
+func (m *MockEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return m.EmbedSingle(ctx, query)
+}
+
+// EmbedDocuments generates deterministic embeddings for documents.
+func (m *MockEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return m.Embed(ctx, texts)
+}
+
 // Embed generates deterministic embeddings for multiple text inputs.
 // Each input text produces a consistent embedding.
 func (m *MockEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
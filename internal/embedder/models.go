@@ -11,6 +11,12 @@ type ModelInfo struct {
 	Dimensions  int    // Embedding vector dimensions
 	ContextSize int    // Maximum context window in tokens
 	Size        string // Human-readable size (e.g., "~300MB")
+
+	// QueryPrefix and DocumentPrefix are prepended to queries and to indexed
+	// content for models trained on asymmetric retrieval. Empty for models
+	// that embed both the same way.
+	QueryPrefix    string
+	DocumentPrefix string
 }
 
 // EmbeddingModels maps short names to model info.
@@ -26,6 +32,9 @@ var EmbeddingModels = map[string]ModelInfo{
 		Dimensions:  1024,
 		ContextSize: 32768,
 		Size:        "~8GB",
+		// Retrieval prefixes from the Jina v4 GGUF model card
+		QueryPrefix:    "Query: ",
+		DocumentPrefix: "Passage: ",
 	},
 }
 
@@ -94,3 +103,16 @@ func GetShortNameForModel(fullName string) string {
 	}
 	return ""
 }
+
+// withPrefix returns texts with prefix prepended to each, or texts itself if
+// prefix is empty.
+func withPrefix(prefix string, texts []string) []string {
+	if prefix == "" {
+		return texts
+	}
+	prefixed := make([]string, len(texts))
+	for i, text := range texts {
+		prefixed[i] = prefix + text
+	}
+	return prefixed
+}
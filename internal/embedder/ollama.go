@@ -41,6 +41,9 @@ type OllamaClient struct {
 	baseURL    string
 	httpClient *http.Client
 	model      string
+
+	queryPrefix    string
+	documentPrefix string
 }
 
 // OllamaConfig holds configuration options for the Ollama client.
@@ -84,13 +87,18 @@ func NewOllamaClient(cfg OllamaConfig) *OllamaClient {
 		cfg.Timeout = DefaultOllamaConfig().Timeout
 	}
 
-	return &OllamaClient{
+	client := &OllamaClient{
 		baseURL: cfg.BaseURL,
 		model:   cfg.Model,
 		httpClient: &http.Client{
 			Timeout: cfg.Timeout,
 		},
 	}
+	if info := GetModelByFullName(cfg.Model); info != nil {
+		client.queryPrefix = info.QueryPrefix
+		client.documentPrefix = info.DocumentPrefix
+	}
+	return client
 }
 
 // Health checks if Ollama is running and accessible.
@@ -138,6 +146,18 @@ func (c *OllamaClient) EmbedSingle(ctx context.Context, text string) ([]float32,
 	return embeddings[0], nil
 }
 
+// EmbedQuery generates an embedding for a search query, with the model's
+// query prefix if it has one.
+func (c *OllamaClient) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return c.EmbedSingle(ctx, c.queryPrefix+query)
+}
+
+// EmbedDocuments generates embeddings for content to be indexed, with the
+// model's document prefix if it has one.
+func (c *OllamaClient) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return c.Embed(ctx, withPrefix(c.documentPrefix, texts))
+}
+
 // Embed generates embeddings for multiple texts in a single request.
 func (c *OllamaClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
@@ -299,6 +319,11 @@ func (c *OllamaClient) ModelName() string {
 	return c.model
 }
 
+// DocumentPrefix returns the model's prefix for indexed content, if any.
+func (c *OllamaClient) DocumentPrefix() string {
+	return c.documentPrefix
+}
+
 // Dimensions returns the embedding dimension size based on the configured model.
 func (c *OllamaClient) Dimensions() int {
 	return GetDimensionsForModel(c.model)
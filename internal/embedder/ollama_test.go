@@ -710,3 +710,51 @@ func TestOllamaClient_SetsNumCtxOption_V4Model(t *testing.T) {
 	require.True(t, ok, "num_ctx should be a number")
 	assert.Equal(t, float64(32768), numCtxFloat, "num_ctx should be 32768 for v4 model")
 }
+
+func TestOllamaClient_QueryAndDocumentPrefixes(t *testing.T) {
+	var inputs []any
+
+	server := createMockOllamaServer(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		inputs = append(inputs, req["input"])
+
+		count := 1
+		if batch, ok := req["input"].([]any); ok {
+			count = len(batch)
+		}
+		embeddings := make([][]float32, count)
+		for i := range embeddings {
+			embeddings[i] = generate768DimEmbedding()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mockOllamaResponse{Embeddings: embeddings})
+	})
+	defer server.Close()
+
+	v4 := NewOllamaClient(OllamaConfig{BaseURL: server.URL, Model: "sellerscrisp/jina-embeddings-v4-text-code-q4"})
+	_, err := v4.EmbedQuery(context.Background(), "retry logic")
+	require.NoError(t, err)
+	_, err = v4.EmbedDocuments(context.Background(), []string{"func a() {}", "func b() {}"})
+	require.NoError(t, err)
+
+	v2 := NewOllamaClient(OllamaConfig{BaseURL: server.URL, Model: "unclemusclez/jina-embeddings-v2-base-code"})
+	_, err = v2.EmbedQuery(context.Background(), "retry logic")
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{
+		"Query: retry logic",
+		[]any{"Passage: func a() {}", "Passage: func b() {}"},
+		"retry logic",
+	}, inputs, "v4 should get task prefixes and v2 none")
+}
+
+func TestDocumentPrefix(t *testing.T) {
+	v4 := NewOllamaClient(OllamaConfig{Model: "sellerscrisp/jina-embeddings-v4-text-code-q4"})
+	v2 := NewOllamaClient(OllamaConfig{Model: "unclemusclez/jina-embeddings-v2-base-code"})
+
+	assert.Equal(t, "Passage: ", DocumentPrefix(v4))
+	assert.Equal(t, "Passage: ", DocumentPrefix(NewCachedEmbedder(v4, 10)), "cache should pass the prefix through")
+	assert.Empty(t, DocumentPrefix(v2))
+	assert.Empty(t, DocumentPrefix(NewMockEmbedder()))
+}
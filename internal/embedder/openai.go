@@ -112,6 +112,17 @@ func (c *OpenAIClient) EmbedSingle(ctx context.Context, text string) ([]float32,
 	return embeddings[0], nil
 }
 
+// EmbedQuery generates an embedding for a search query. OpenAI models embed
+// queries and documents the same way.
+func (c *OpenAIClient) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return c.EmbedSingle(ctx, query)
+}
+
+// EmbedDocuments generates embeddings for content to be indexed.
+func (c *OpenAIClient) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return c.Embed(ctx, texts)
+}
+
 // Embed generates embeddings for multiple texts in a single request.
 func (c *OpenAIClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
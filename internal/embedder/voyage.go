@@ -36,6 +36,13 @@ type VoyageClient struct {
 	httpClient *http.Client
 }
 
+// Voyage input types, which tell the model whether it is embedding a search
+// query or content to be searched.
+const (
+	voyageInputQuery    = "query"
+	voyageInputDocument = "document"
+)
+
 // voyageEmbedRequest represents the request to Voyage's embeddings endpoint.
 type voyageEmbedRequest struct {
 	Model     string `json:"model"`
@@ -92,9 +99,26 @@ func (c *VoyageClient) Health(ctx context.Context) error {
 	return err
 }
 
-// EmbedSingle generates an embedding for a single text input.
+// EmbedQuery generates an embedding for a search query, using Voyage's
+// "query" input type.
+func (c *VoyageClient) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return c.embedOne(ctx, query, voyageInputQuery)
+}
+
+// EmbedDocuments generates embeddings for content to be indexed, using
+// Voyage's "document" input type.
+func (c *VoyageClient) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return c.Embed(ctx, texts)
+}
+
+// EmbedSingle generates an embedding for a single text input, embedded as a document.
 func (c *VoyageClient) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
-	embeddings, err := c.embed(ctx, text)
+	return c.embedOne(ctx, text, voyageInputDocument)
+}
+
+// embedOne generates an embedding for a single text with the given input type.
+func (c *VoyageClient) embedOne(ctx context.Context, text, inputType string) ([]float32, error) {
+	embeddings, err := c.embed(ctx, text, inputType)
 	if err != nil {
 		return nil, err
 	}
@@ -107,20 +131,20 @@ func (c *VoyageClient) EmbedSingle(ctx context.Context, text string) ([]float32,
 	return embeddings[0], nil
 }
 
-// Embed generates embeddings for multiple texts in a single request.
+// Embed generates embeddings for multiple texts in a single request, embedded as documents.
 func (c *VoyageClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
-	return c.embed(ctx, texts)
+	return c.embed(ctx, texts, voyageInputDocument)
 }
 
 // embed is the internal method that handles the actual embedding request.
-func (c *VoyageClient) embed(ctx context.Context, input any) ([][]float32, error) {
+func (c *VoyageClient) embed(ctx context.Context, input any, inputType string) ([][]float32, error) {
 	reqBody := voyageEmbedRequest{
 		Model:     c.model,
 		Input:     input,
-		InputType: "document", // Use document for code indexing
+		InputType: inputType,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -293,3 +293,27 @@ func TestVoyageClient_EmbedSingle_EmptyData(t *testing.T) {
 	_, err := client.EmbedSingle(context.Background(), "test")
 	require.Error(t, err)
 }
+
+func TestVoyageClient_InputTypes(t *testing.T) {
+	var inputTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req voyageEmbedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		inputTypes = append(inputTypes, req.InputType)
+
+		resp := map[string]any{
+			"data": []map[string]any{{"index": 0, "embedding": make([]float64, 1024)}},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewVoyageClient(VoyageConfig{APIKey: "pa-test", BaseURL: server.URL})
+
+	_, err := client.EmbedQuery(context.Background(), "retry logic")
+	require.NoError(t, err)
+	_, err = client.EmbedDocuments(context.Background(), []string{"func retry() {}"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"query", "document"}, inputTypes)
+}
@@ -32,6 +32,7 @@ type Chunk struct {
 	Signature    string
 	ContentHash  string
 	LastModified time.Time
+	IsTest       bool // Whether the chunk's file holds tests
 
 	// Subproject fields for v0.2 multi-repo support
 	SubprojectID   *string `json:"subproject_id,omitempty"`
@@ -112,6 +112,61 @@ func (f *Formatter) formatVerbose(result *api.SearchResult, index int) string {
 	return sb.String()
 }
 
+// Highlight markers: bold yellow with colors, guillemets without
+const (
+	highlightStartColor = "\033[1;33m"
+	highlightEndColor   = "\033[0m"
+	highlightStartPlain = "«"
+	highlightEndPlain   = "»"
+)
+
+// FormatSnippet renders a result's snippet with line numbers, marking the
+// spans that matched the query. Each line starts with indent. It returns an
+// empty string if the result has no snippet.
+func (f *Formatter) FormatSnippet(result *api.SearchResult, indent string) string {
+	if result.Snippet == nil {
+		return ""
+	}
+
+	start, end := highlightStartPlain, highlightEndPlain
+	if f.ShowColors {
+		start, end = highlightStartColor, highlightEndColor
+	}
+
+	byLine := make(map[int][]api.Highlight)
+	for _, h := range result.Highlights {
+		byLine[h.Line] = append(byLine[h.Line], h)
+	}
+
+	width := len(fmt.Sprint(result.Snippet.EndLine))
+	var sb strings.Builder
+	for i, line := range strings.Split(result.Snippet.Content, "\n") {
+		lineNo := result.Snippet.StartLine + i
+		sb.WriteString(fmt.Sprintf("%s%*d | %s\n", indent, width, lineNo, markSpans(line, byLine[lineNo], start, end)))
+	}
+	return sb.String()
+}
+
+// markSpans wraps each highlighted span of line in the start and end markers.
+// Highlights are in column order and don't overlap.
+func markSpans(line string, highlights []api.Highlight, start, end string) string {
+	var sb strings.Builder
+	pos := 0
+	for _, h := range highlights {
+		from, to := h.StartCol-1, h.EndCol-1
+		if from < pos || to > len(line) || from >= to {
+			continue
+		}
+		sb.WriteString(line[pos:from])
+		sb.WriteString(start)
+		sb.WriteString(line[from:to])
+		sb.WriteString(end)
+		pos = to
+	}
+	sb.WriteString(line[pos:])
+	return sb.String()
+}
+
 // formatScoreDetails formats the detailed score breakdown
 func (f *Formatter) formatScoreDetails(details *api.ScoreDetails) string {
 	var sb strings.Builder
@@ -124,7 +179,13 @@ func (f *Formatter) formatScoreDetails(details *api.ScoreDetails) string {
 		scores = append(scores, fmt.Sprintf("keyword=%.4f", details.KeywordScore))
 	}
 	if details.RRFScore > 0 {
-		scores = append(scores, fmt.Sprintf("rrf=%.4f", details.RRFScore))
+		if details.Fusion != "" {
+			// Show what each leg contributed to the fused score
+			scores = append(scores, fmt.Sprintf("%s=%.4f (vector %.4f + keyword %.4f)",
+				details.Fusion, details.RRFScore, details.VectorContribution, details.KeywordContribution))
+		} else {
+			scores = append(scores, fmt.Sprintf("rrf=%.4f", details.RRFScore))
+		}
 	}
 	if details.RerankerScore > 0 {
 		scores = append(scores, fmt.Sprintf("rerank=%.4f", details.RerankerScore))
@@ -194,6 +194,21 @@ func TestFormatScoreDetails(t *testing.T) {
 	}
 }
 
+func TestFormatScoreDetails_FusionContributions(t *testing.T) {
+	f := NewFormatter(FormatVerbose)
+
+	output := f.formatScoreDetails(&api.ScoreDetails{
+		RRFScore:            0.75,
+		Fusion:              "linear",
+		VectorContribution:  0.5,
+		KeywordContribution: 0.25,
+	})
+
+	if !strings.Contains(output, "linear=0.7500 (vector 0.5000 + keyword 0.2500)") {
+		t.Errorf("Expected fusion contributions, got %q", output)
+	}
+}
+
 func TestFormatScoreDetails_EmptySignals(t *testing.T) {
 	f := NewFormatter(FormatVerbose)
 
@@ -404,3 +419,54 @@ func TestFormatter_IndexNumbering(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatSnippet_MarksHighlights(t *testing.T) {
+	f := NewFormatter(FormatNormal)
+
+	result := &api.SearchResult{
+		Snippet: &api.SourceLines{
+			StartLine: 9,
+			EndLine:   10,
+			Content:   "// InvoiceTotal sums the items.\nfunc InvoiceTotal(items []Item) int {",
+		},
+		Highlights: []api.Highlight{
+			{Line: 10, StartCol: 6, EndCol: 13, Term: "invoice"},
+			{Line: 10, StartCol: 13, EndCol: 18, Term: "total"},
+		},
+	}
+
+	output := f.FormatSnippet(result, "  ")
+
+	expected := "   9 | // InvoiceTotal sums the items.\n" +
+		"  10 | func «Invoice»«Total»(items []Item) int {\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	f.ShowColors = true
+	output = f.FormatSnippet(result, "")
+	if !strings.Contains(output, "func \033[1;33mInvoice\033[0m\033[1;33mTotal\033[0m(items") {
+		t.Errorf("Expected colored highlights, got %q", output)
+	}
+}
+
+func TestFormatSnippet_NoSnippet(t *testing.T) {
+	f := NewFormatter(FormatNormal)
+
+	if output := f.FormatSnippet(&api.SearchResult{Content: "func main() {}"}, ""); output != "" {
+		t.Errorf("Expected empty output without a snippet, got %q", output)
+	}
+}
+
+func TestMarkSpans_SkipsInvalidSpans(t *testing.T) {
+	line := "retry()"
+	highlights := []api.Highlight{
+		{StartCol: 1, EndCol: 6},
+		{StartCol: 3, EndCol: 5},  // overlaps the previous span
+		{StartCol: 6, EndCol: 40}, // runs past the line
+	}
+
+	if got := markSpans(line, highlights, "[", "]"); got != "[retry]()" {
+		t.Errorf("Expected [retry](), got %q", got)
+	}
+}
@@ -0,0 +1,246 @@
+package pathutil
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Pattern is a gitignore-style path pattern. The same syntax is used by
+// .gitignore, .pommelignore and the ignore patterns in the config, and by
+// the include and exclude filters on searches.
+type Pattern struct {
+	original string
+	negation bool
+	dirOnly  bool
+	pattern  string
+}
+
+// ParsePattern parses a gitignore-style pattern. A leading "!" negates the
+// pattern and a trailing "/" restricts it to directories.
+func ParsePattern(p string) Pattern {
+	pat := Pattern{
+		original: p,
+	}
+
+	// Check for negation
+	if strings.HasPrefix(p, "!") {
+		pat.negation = true
+		p = p[1:]
+	}
+
+	// Check for directory-only pattern
+	if strings.HasSuffix(p, "/") {
+		pat.dirOnly = true
+		p = strings.TrimSuffix(p, "/")
+	}
+
+	pat.pattern = p
+	return pat
+}
+
+// ParsePatterns parses a list of gitignore-style patterns.
+func ParsePatterns(patterns []string) []Pattern {
+	parsed := make([]Pattern, len(patterns))
+	for i, p := range patterns {
+		parsed[i] = ParsePattern(p)
+	}
+	return parsed
+}
+
+// String returns the pattern as it was written.
+func (pat Pattern) String() string {
+	return pat.original
+}
+
+// MatchPatterns reports whether a path relative to the project root is
+// matched by a list of patterns. Patterns are applied in order, as in
+// .gitignore: a later negated pattern un-matches a path an earlier one matched.
+func MatchPatterns(patterns []Pattern, path string) bool {
+	matched := false
+	for _, pat := range patterns {
+		if pat.Match(path) {
+			matched = !pat.negation
+		}
+	}
+	return matched
+}
+
+// Match reports whether a path relative to the project root matches the
+// pattern. Negation is not applied; see MatchPatterns.
+func (pat Pattern) Match(path string) bool {
+	// Get the pattern string
+	p := pat.pattern
+
+	// Handle ** patterns
+	if strings.Contains(p, "**") {
+		return matchDoubleStarPattern(path, p)
+	}
+
+	// Handle directory patterns
+	if pat.dirOnly {
+		return matchDirectoryPattern(path, p)
+	}
+
+	// Handle simple glob patterns (*.log)
+	if strings.Contains(p, "*") && !strings.Contains(p, "/") {
+		// Check against basename of all path components and the file itself
+		return matchGlobPattern(path, p)
+	}
+
+	// Handle path patterns (contains /)
+	if strings.Contains(p, "/") {
+		return matchPathPattern(path, p)
+	}
+
+	// Exact match against filename
+	return matchExactPattern(path, p)
+}
+
+// matchDoubleStarPattern matches patterns containing **
+func matchDoubleStarPattern(path, pattern string) bool {
+	// Normalize separators for cross-platform compatibility
+	// This ensures patterns with forward slashes match paths with backslashes (Windows)
+	// We explicitly replace backslashes because filepath.ToSlash only converts the
+	// OS-native separator, which doesn't help when testing with backslash paths on Unix
+	normalizedPath := strings.ReplaceAll(path, "\\", "/")
+	normalizedPattern := strings.ReplaceAll(pattern, "\\", "/")
+
+	// Trim trailing slashes to avoid matching directories without content
+	// e.g., ".venv/" should not match "**/.venv/**" (the pattern requires content inside)
+	normalizedPath = strings.TrimSuffix(normalizedPath, "/")
+
+	// Handle **/*.ext pattern - match at any depth
+	if strings.HasPrefix(normalizedPattern, "**/") {
+		subPattern := normalizedPattern[3:] // Remove **/
+
+		// Check against basename
+		base := filepath.Base(normalizedPath)
+		matched, _ := filepath.Match(subPattern, base)
+		if matched {
+			return true
+		}
+
+		// Handle patterns like **/.venv/** (directory with trailing **)
+		// These need special handling to match paths inside the directory
+		if strings.HasSuffix(subPattern, "/**") {
+			dirName := subPattern[:len(subPattern)-3] // Remove trailing /**
+
+			// Check if any path component matches the directory name
+			parts := strings.Split(normalizedPath, "/")
+			for idx, part := range parts {
+				if part == dirName {
+					// Check if there are actual (non-empty) components after this one
+					hasContentAfter := false
+					for j := idx + 1; j < len(parts); j++ {
+						if parts[j] != "" {
+							hasContentAfter = true
+							break
+						}
+					}
+					if hasContentAfter {
+						return true
+					}
+				}
+			}
+		}
+
+		// Also check against all path components
+		parts := strings.Split(normalizedPath, "/")
+		for idx := 0; idx < len(parts); idx++ {
+			subPath := strings.Join(parts[idx:], "/")
+			matched, _ := filepath.Match(subPattern, subPath)
+			if matched {
+				return true
+			}
+		}
+	}
+
+	// Handle patterns that end with ** but don't start with **
+	// e.g., ".venv/**" should match ".venv/anything"
+	if strings.HasSuffix(normalizedPattern, "/**") && !strings.HasPrefix(normalizedPattern, "**/") {
+		prefix := normalizedPattern[:len(normalizedPattern)-3] // Remove /**
+		if strings.HasPrefix(normalizedPath, prefix+"/") || normalizedPath == prefix {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchDirectoryPattern matches directory patterns (ending with /)
+func matchDirectoryPattern(path, pattern string) bool {
+	// Normalize separators
+	normalizedPath := filepath.ToSlash(path)
+	normalizedPattern := filepath.ToSlash(pattern)
+
+	// If pattern contains /, it's a multi-component directory pattern
+	if strings.Contains(normalizedPattern, "/") {
+		// Check if path starts with the pattern or contains it as a directory segment
+		if strings.HasPrefix(normalizedPath, normalizedPattern+"/") ||
+			normalizedPath == normalizedPattern ||
+			strings.Contains(normalizedPath, "/"+normalizedPattern+"/") ||
+			strings.HasSuffix(normalizedPath, "/"+normalizedPattern) {
+			return true
+		}
+		// Also check if path starts with the pattern (for content inside)
+		if strings.HasPrefix(normalizedPath, normalizedPattern) {
+			return true
+		}
+		return false
+	}
+
+	// Single component directory pattern - check if it appears as a path component
+	parts := strings.Split(normalizedPath, "/")
+	for _, part := range parts {
+		if part == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlobPattern matches simple glob patterns like *.log
+func matchGlobPattern(path, pattern string) bool {
+	// Check against basename
+	base := filepath.Base(path)
+	matched, _ := filepath.Match(pattern, base)
+	if matched {
+		return true
+	}
+
+	// Also check each path component
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for _, part := range parts {
+		matched, _ := filepath.Match(pattern, part)
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPathPattern matches patterns containing /
+func matchPathPattern(path, pattern string) bool {
+	// Normalize separators
+	normalizedPath := filepath.ToSlash(path)
+	normalizedPattern := filepath.ToSlash(pattern)
+
+	// Check if path starts with or contains the pattern
+	if strings.HasPrefix(normalizedPath, normalizedPattern) {
+		return true
+	}
+	if strings.Contains(normalizedPath, "/"+normalizedPattern) {
+		return true
+	}
+
+	return false
+}
+
+// matchExactPattern matches exact filename patterns
+func matchExactPattern(path, pattern string) bool {
+	// Check against basename
+	base := filepath.Base(path)
+	return base == pattern
+}
@@ -0,0 +1,49 @@
+package pathutil
+
+import "testing"
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"**/*_test.go", "internal/db/db_test.go", true},
+		{"**/*_test.go", "internal/db/db.go", false},
+		{"vendor/**", "vendor/github.com/pkg/errors/errors.go", true},
+		{"vendor/**", "internal/vendor.go", false},
+		{"vendor/", "third_party/vendor/lib.go", true},
+		{"*.pb.go", "api/v1/service.pb.go", true},
+		{"internal/api", "internal/api/router.go", true},
+		{"Makefile", "build/Makefile", true},
+		{"Makefile", "Makefile.inc", false},
+	}
+	for _, tt := range tests {
+		if got := ParsePattern(tt.pattern).Match(tt.path); got != tt.want {
+			t.Errorf("ParsePattern(%q).Match(%q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParsePattern_KeepsOriginal(t *testing.T) {
+	if got := ParsePattern("!vendor/").String(); got != "!vendor/" {
+		t.Errorf("expected original pattern, got %q", got)
+	}
+}
+
+func TestMatchPatterns_LastMatchWins(t *testing.T) {
+	patterns := ParsePatterns([]string{"vendor/**", "!vendor/internal/**"})
+
+	if !MatchPatterns(patterns, "vendor/lib/lib.go") {
+		t.Error("expected vendor/lib/lib.go to match")
+	}
+	if MatchPatterns(patterns, "vendor/internal/keep.go") {
+		t.Error("expected negated pattern to un-match vendor/internal/keep.go")
+	}
+	if MatchPatterns(patterns, "main.go") {
+		t.Error("expected main.go not to match")
+	}
+	if MatchPatterns(nil, "main.go") {
+		t.Error("expected no patterns to match nothing")
+	}
+}
@@ -0,0 +1,194 @@
+package rerank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// crossEncoderWeight is the share of the final score taken from the
+	// cross-encoder; the rest comes from the hybrid search score.
+	crossEncoderWeight = 0.8
+)
+
+// CrossEncoderConfig holds configuration for the cross-encoder reranker.
+type CrossEncoderConfig struct {
+	// URL is the rerank endpoint, e.g. http://localhost:8080/v1/rerank.
+	// Required: there is no standard rerank endpoint to default to.
+	URL string
+	// Model is the cross-encoder model the server should use.
+	Model string
+	// BatchSize is the number of candidates scored per request.
+	BatchSize int
+	// Timeout bounds each request.
+	Timeout time.Duration
+}
+
+// DefaultCrossEncoderConfig returns the default cross-encoder configuration.
+// It has no URL, which must be set.
+func DefaultCrossEncoderConfig() CrossEncoderConfig {
+	return CrossEncoderConfig{
+		BatchSize: 16,
+		Timeout:   10 * time.Second,
+	}
+}
+
+// CrossEncoderReranker scores (query, chunk) pairs with a cross-encoder model
+// served over a /rerank JSON API, as offered by llama.cpp and
+// text-embeddings-inference style servers.
+type CrossEncoderReranker struct {
+	server    *modelServer
+	model     string
+	batchSize int
+}
+
+// crossEncoderRequest is the body posted to the rerank endpoint.
+type crossEncoderRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+// crossEncoderResponse is the rerank endpoint's reply. Results refer to
+// documents by their index in the request.
+type crossEncoderResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// NewCrossEncoderReranker creates a cross-encoder reranker. It returns an
+// error if cfg has no URL.
+func NewCrossEncoderReranker(cfg CrossEncoderConfig) (*CrossEncoderReranker, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("cross-encoder reranker requires a rerank endpoint url")
+	}
+	defaults := DefaultCrossEncoderConfig()
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+
+	return &CrossEncoderReranker{
+		server:    newModelServer("rerank", cfg.URL, cfg.Timeout),
+		model:     cfg.Model,
+		batchSize: cfg.BatchSize,
+	}, nil
+}
+
+// Rerank scores candidates with the cross-encoder and returns them sorted.
+// The final score blends the cross-encoder's relevance with the hybrid
+// search score, which keeps ties in the model's judgement in search order.
+func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, candidates []Candidate) ([]RankedCandidate, error) {
+	if len(candidates) == 0 {
+		return []RankedCandidate{}, nil
+	}
+
+	scores := make([]float64, len(candidates))
+	for start := 0; start < len(candidates); start += r.batchSize {
+		end := start + r.batchSize
+		if end > len(candidates) {
+			end = len(candidates)
+		}
+		if err := r.scoreBatch(ctx, query, candidates[start:end], scores[start:end]); err != nil {
+			r.server.fail(ctx)
+			return nil, err
+		}
+	}
+	normalizeScores(scores)
+
+	results := make([]RankedCandidate, len(candidates))
+	for i, c := range candidates {
+		results[i] = RankedCandidate{
+			Candidate:     c,
+			FinalScore:    crossEncoderWeight*scores[i] + (1-crossEncoderWeight)*c.BaseScore,
+			RerankerScore: scores[i],
+			ModelScore:    true,
+			SignalScores:  map[string]float64{"cross_encoder": scores[i]},
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].FinalScore != results[j].FinalScore {
+			return results[i].FinalScore > results[j].FinalScore
+		}
+		return results[i].ChunkID < results[j].ChunkID
+	})
+
+	return results, nil
+}
+
+// scoreBatch scores one batch of candidates, writing each score to scores.
+func (r *CrossEncoderReranker) scoreBatch(ctx context.Context, query string, batch []Candidate, scores []float64) error {
+	documents := make([]string, len(batch))
+	for i, c := range batch {
+		documents[i] = candidateDocument(c)
+	}
+
+	var parsed crossEncoderResponse
+	err := r.server.post(ctx, crossEncoderRequest{
+		Model:     r.model,
+		Query:     query,
+		Documents: documents,
+		TopN:      len(documents),
+	}, &parsed)
+	if err != nil {
+		return err
+	}
+	if len(parsed.Results) != len(batch) {
+		return fmt.Errorf("rerank server scored %d of %d documents", len(parsed.Results), len(batch))
+	}
+
+	seen := make([]bool, len(batch))
+	for _, result := range parsed.Results {
+		if result.Index < 0 || result.Index >= len(batch) || seen[result.Index] {
+			return fmt.Errorf("rerank server returned invalid document index %d", result.Index)
+		}
+		seen[result.Index] = true
+		scores[result.Index] = result.RelevanceScore
+	}
+	return nil
+}
+
+// candidateDocument is the text the cross-encoder reads for a candidate: its
+// file path, which often names the concept, then its content.
+func candidateDocument(c Candidate) string {
+	content := truncateDocument(c.Content)
+	if c.FilePath == "" {
+		return content
+	}
+	return c.FilePath + "\n" + content
+}
+
+// normalizeScores maps raw logits to 0..1 with a sigmoid. Servers that
+// already return probabilities are left alone; a response is taken as logits
+// if any score falls outside 0..1.
+func normalizeScores(scores []float64) {
+	for _, s := range scores {
+		if s < 0 || s > 1 {
+			for i := range scores {
+				scores[i] = 1 / (1 + math.Exp(-scores[i]))
+			}
+			return
+		}
+	}
+}
+
+// Name returns the reranker identifier
+func (r *CrossEncoderReranker) Name() string {
+	return "cross-encoder"
+}
+
+// Available returns false for a short while after a request fails, so a
+// FallbackReranker goes straight to its fallback instead of timing out again.
+func (r *CrossEncoderReranker) Available(ctx context.Context) bool {
+	return r.server.ready()
+}
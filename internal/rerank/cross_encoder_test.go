@@ -0,0 +1,200 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRerankServer starts a stand-in rerank server that scores each document
+// with score, and counts the requests it receives.
+func newRerankServer(t *testing.T, score func(query, document string) float64) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var req crossEncoderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var resp crossEncoderResponse
+		// Reply in reverse order, as servers sort results by relevance
+		for i := len(req.Documents) - 1; i >= 0; i-- {
+			resp.Results = append(resp.Results, struct {
+				Index          int     `json:"index"`
+				RelevanceScore float64 `json:"relevance_score"`
+			}{i, score(req.Query, req.Documents[i])})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// newCrossEncoder creates a cross-encoder reranker, failing the test on error.
+func newCrossEncoder(t *testing.T, cfg CrossEncoderConfig) *CrossEncoderReranker {
+	t.Helper()
+	r, err := NewCrossEncoderReranker(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return r
+}
+
+func TestCrossEncoderReranker_Name(t *testing.T) {
+	r := newCrossEncoder(t, CrossEncoderConfig{URL: "http://localhost:8080/v1/rerank"})
+	if r.Name() != "cross-encoder" {
+		t.Errorf("Expected name 'cross-encoder', got '%s'", r.Name())
+	}
+}
+
+func TestNewCrossEncoderReranker_RequiresURL(t *testing.T) {
+	if _, err := NewCrossEncoderReranker(CrossEncoderConfig{Model: "bge-reranker-v2-m3"}); err == nil {
+		t.Error("Expected an error without a url")
+	}
+	if url := DefaultCrossEncoderConfig().URL; url != "" {
+		t.Errorf("Expected no default url, got %q", url)
+	}
+}
+
+func TestCrossEncoderReranker_ScoresInBatches(t *testing.T) {
+	server, requests := newRerankServer(t, func(query, document string) float64 {
+		if strings.Contains(document, "retry") {
+			return 0.9
+		}
+		return 0.1
+	})
+	r := newCrossEncoder(t, CrossEncoderConfig{URL: server.URL, Model: "test-model", BatchSize: 2})
+
+	candidates := []Candidate{
+		{ChunkID: "a", Content: "func parse() {}", BaseScore: 0.9},
+		{ChunkID: "b", Content: "func load() {}", BaseScore: 0.8},
+		{ChunkID: "c", Content: "func retry() {}", BaseScore: 0.5},
+		{ChunkID: "d", Content: "func save() {}", BaseScore: 0.4},
+		{ChunkID: "e", Content: "func close() {}", BaseScore: 0.3},
+	}
+	results, err := r.Rerank(context.Background(), "retry logic", candidates)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("Expected 3 batched requests, got %d", got)
+	}
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(results))
+	}
+	if results[0].ChunkID != "c" {
+		t.Errorf("Expected the cross-encoder's best match first, got %s", results[0].ChunkID)
+	}
+	if results[0].RerankerScore != 0.9 {
+		t.Errorf("Expected reranker score 0.9, got %f", results[0].RerankerScore)
+	}
+	if !results[0].ModelScore {
+		t.Error("Expected the cross-encoder's score to be flagged as a model score")
+	}
+	if results[0].SignalScores["cross_encoder"] != 0.9 {
+		t.Error("cross_encoder signal should be populated")
+	}
+	// Equal cross-encoder scores keep the hybrid search order
+	if results[1].ChunkID != "a" || results[2].ChunkID != "b" {
+		t.Errorf("Expected ties ordered by base score, got %s, %s", results[1].ChunkID, results[2].ChunkID)
+	}
+}
+
+func TestCrossEncoderReranker_NormalizesLogits(t *testing.T) {
+	server, _ := newRerankServer(t, func(query, document string) float64 {
+		if strings.Contains(document, "match") {
+			return 4
+		}
+		return -4
+	})
+	r := newCrossEncoder(t, CrossEncoderConfig{URL: server.URL})
+
+	results, err := r.Rerank(context.Background(), "query", []Candidate{
+		{ChunkID: "miss", Content: "other"},
+		{ChunkID: "hit", Content: "match"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := 1 / (1 + math.Exp(-4))
+	if results[0].ChunkID != "hit" || math.Abs(results[0].RerankerScore-want) > 1e-9 {
+		t.Errorf("Expected hit with sigmoid score %f, got %s with %f", want, results[0].ChunkID, results[0].RerankerScore)
+	}
+	if results[1].RerankerScore <= 0 || results[1].RerankerScore >= 0.5 {
+		t.Errorf("Expected a negative logit to map below 0.5, got %f", results[1].RerankerScore)
+	}
+}
+
+func TestCrossEncoderReranker_ServerErrorMakesUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	r := newCrossEncoder(t, CrossEncoderConfig{URL: server.URL})
+
+	if !r.Available(context.Background()) {
+		t.Fatal("Reranker should start available")
+	}
+	_, err := r.Rerank(context.Background(), "query", []Candidate{{ChunkID: "a", Content: "x"}})
+	if err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Fatalf("Expected a status error, got %v", err)
+	}
+	if r.Available(context.Background()) {
+		t.Error("Reranker should be unavailable after a failed request")
+	}
+}
+
+func TestCrossEncoderReranker_RejectsIncompleteResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[{"index":0,"relevance_score":0.5}]}`))
+	}))
+	defer server.Close()
+	r := newCrossEncoder(t, CrossEncoderConfig{URL: server.URL})
+
+	_, err := r.Rerank(context.Background(), "query", []Candidate{{ChunkID: "a"}, {ChunkID: "b"}})
+	if err == nil {
+		t.Error("Expected an error when documents are left unscored")
+	}
+}
+
+func TestCrossEncoderReranker_FallsBackToHeuristic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	primary := newCrossEncoder(t, CrossEncoderConfig{URL: server.URL})
+	r := NewFallbackReranker(primary, NewHeuristicReranker(), 20*time.Millisecond)
+
+	results, err := r.Rerank(context.Background(), "parse", []Candidate{{ChunkID: "a", Content: "parse", BaseScore: 0.5}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := results[0].SignalScores["name_match"]; !ok {
+		t.Error("Expected heuristic scores after the cross-encoder timed out")
+	}
+	if primary.Available(context.Background()) {
+		t.Error("A timed-out cross-encoder should be unavailable for a while")
+	}
+}
+
+func TestCandidateDocument_TruncatesContent(t *testing.T) {
+	doc := candidateDocument(Candidate{FilePath: "a.go", Content: strings.Repeat("x", maxDocumentBytes*2)})
+	if !strings.HasPrefix(doc, "a.go\n") {
+		t.Error("Document should start with the file path")
+	}
+	if len(doc) != len("a.go\n")+maxDocumentBytes {
+		t.Errorf("Expected content truncated to %d bytes, got %d", maxDocumentBytes, len(doc)-len("a.go\n"))
+	}
+}
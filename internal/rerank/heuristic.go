@@ -8,11 +8,19 @@ import (
 )
 
 // HeuristicReranker re-ranks candidates using code-aware heuristic signals
-type HeuristicReranker struct{}
+type HeuristicReranker struct {
+	signals *signalSet
+}
 
-// NewHeuristicReranker creates a new heuristic reranker
+// NewHeuristicReranker creates a new heuristic reranker with the default signals
 func NewHeuristicReranker() *HeuristicReranker {
-	return &HeuristicReranker{}
+	return NewHeuristicRerankerWithConfig(DefaultSignalConfig())
+}
+
+// NewHeuristicRerankerWithConfig creates a heuristic reranker with the given
+// signal weights, test and mock patterns, and path boosts
+func NewHeuristicRerankerWithConfig(cfg SignalConfig) *HeuristicReranker {
+	return &HeuristicReranker{signals: newSignalSet(cfg)}
 }
 
 // Rerank scores candidates using heuristic signals and returns them sorted
@@ -38,26 +46,19 @@ func (r *HeuristicReranker) Rerank(ctx context.Context, query string, candidates
 		signals := make(map[string]float64)
 
 		// Apply all signals
-		nameScore := NameMatchSignal(c.Name, queryTerms)
-		signals["name_match"] = nameScore
-
-		phraseScore := ExactPhraseSignal(c.Content, query)
-		signals["exact_phrase"] = phraseScore
-
-		pathScore := PathMatchSignal(c.FilePath, queryTerms)
-		signals["path_match"] = pathScore
-
-		testPenalty := TestFilePenalty(c.FilePath)
-		signals["test_penalty"] = testPenalty
-
-		recencyScore := RecencyBoost(c.ModTime, now)
-		signals["recency"] = recencyScore
-
-		typeScore := ChunkTypeSignal(c.ChunkType, query)
-		signals["chunk_type"] = typeScore
+		signals["name_match"] = nameMatchSignal(c.Name, queryTerms, r.signals.NameMatch)
+		signals["exact_phrase"] = exactPhraseSignal(c.Content, query, r.signals.ExactPhrase)
+		signals["path_match"] = pathMatchSignal(c.FilePath, queryTerms, r.signals.PathMatch)
+		signals["test_penalty"] = r.signals.testPenalty(c.FilePath, c.IsTest)
+		signals["mock_penalty"] = r.signals.mockPenalty(c.FilePath, c.IsTest)
+		signals["recency"] = recencyBoost(c.ModTime, now, r.signals.Recency)
+		signals["chunk_type"] = chunkTypeSignal(c.ChunkType, query, r.signals.ChunkType)
+		signals["path_boost"] = r.signals.pathBoost(c.FilePath)
 
 		// Calculate total signal contribution
-		rerankerScore := nameScore + phraseScore + pathScore + testPenalty + recencyScore + typeScore
+		rerankerScore := signals["name_match"] + signals["exact_phrase"] + signals["path_match"] +
+			signals["test_penalty"] + signals["mock_penalty"] + signals["recency"] +
+			signals["chunk_type"] + signals["path_boost"]
 
 		// Combine with base score
 		// Base score is weighted higher (0.7), reranker signals add adjustment
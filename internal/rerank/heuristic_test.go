@@ -2,6 +2,7 @@ package rerank
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 )
@@ -301,6 +302,123 @@ func TestHeuristicReranker_VeryLongContent(t *testing.T) {
 	}
 }
 
+func TestHeuristicReranker_SignalScoresByName(t *testing.T) {
+	r := NewHeuristicReranker()
+	results, err := r.Rerank(context.Background(), "parse", []Candidate{{ChunkID: "a", FilePath: "mock_parser.go", BaseScore: 0.5}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"name_match", "exact_phrase", "path_match", "test_penalty", "mock_penalty", "recency", "chunk_type", "path_boost"} {
+		if _, ok := results[0].SignalScores[name]; !ok {
+			t.Errorf("Expected signal %q in SignalScores", name)
+		}
+	}
+	if results[0].SignalScores["mock_penalty"] >= 0 || results[0].SignalScores["test_penalty"] != 0 {
+		t.Errorf("Expected only the mock penalty for a mock file, got %v", results[0].SignalScores)
+	}
+}
+
+func TestHeuristicReranker_CustomWeights(t *testing.T) {
+	cfg := DefaultSignalConfig()
+	cfg.NameMatch = 0
+	cfg.TestPenalty = 0.5
+	r := NewHeuristicRerankerWithConfig(cfg)
+
+	results, err := r.Rerank(context.Background(), "parse", []Candidate{
+		{ChunkID: "a", Name: "parse", FilePath: "parse_test.go", BaseScore: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].SignalScores["name_match"] != 0 {
+		t.Errorf("A zero weight should turn the signal off, got %f", results[0].SignalScores["name_match"])
+	}
+	if results[0].SignalScores["test_penalty"] != -0.5 {
+		t.Errorf("Expected test penalty -0.5, got %f", results[0].SignalScores["test_penalty"])
+	}
+}
+
+func TestHeuristicReranker_CustomTestPatterns(t *testing.T) {
+	cfg := DefaultSignalConfig()
+	cfg.TestPatterns = []string{"*Spec.scala", "fixtures/"}
+	r := NewHeuristicRerankerWithConfig(cfg)
+
+	tests := map[string]bool{
+		"src/ParserSpec.scala":     true,
+		"testdata/fixtures/a.json": true,
+		"parser_test.go":           false,
+	}
+	for path, isTest := range tests {
+		results, err := r.Rerank(context.Background(), "parse", []Candidate{{ChunkID: "a", FilePath: path, BaseScore: 0.5}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := results[0].SignalScores["test_penalty"] < 0; got != isTest {
+			t.Errorf("%s: expected test file %v, got %v", path, isTest, got)
+		}
+	}
+}
+
+func TestHeuristicReranker_FlaggedTestFiles(t *testing.T) {
+	r := NewHeuristicReranker()
+
+	// conftest.py matches no default pattern, but was flagged at index time
+	results, err := r.Rerank(context.Background(), "fixture", []Candidate{
+		{ChunkID: "a", FilePath: "conftest.py", BaseScore: 0.5, IsTest: true},
+		{ChunkID: "b", FilePath: "mock_client.py", BaseScore: 0.5, IsTest: true},
+		{ChunkID: "c", FilePath: "client.py", BaseScore: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	byID := make(map[string]RankedCandidate, len(results))
+	for _, r := range results {
+		byID[r.ChunkID] = r
+	}
+	if got := byID["a"].SignalScores["test_penalty"]; got != -defaultTestPenalty {
+		t.Errorf("expected flagged file to get the test penalty, got %v", got)
+	}
+	if got := byID["b"].SignalScores["mock_penalty"]; got != 0 {
+		t.Errorf("expected no mock penalty for a flagged test file, got %v", got)
+	}
+	if got := byID["c"].SignalScores["test_penalty"]; got != 0 {
+		t.Errorf("expected no test penalty for an unflagged file, got %v", got)
+	}
+}
+
+func TestHeuristicReranker_PathBoosts(t *testing.T) {
+	cfg := DefaultSignalConfig()
+	cfg.PathBoosts = []PathBoost{
+		{Pattern: "legacy/**", Boost: -0.3},
+		{Pattern: "core/", Boost: 0.1},
+		{Pattern: "*.go", Boost: 0.05},
+	}
+	r := NewHeuristicRerankerWithConfig(cfg)
+
+	candidates := []Candidate{
+		{ChunkID: "legacy", Content: "x", FilePath: "legacy/handler.go", BaseScore: 0.82},
+		{ChunkID: "core", Content: "x", FilePath: "internal/core/handler.go", BaseScore: 0.8},
+		{ChunkID: "other", Content: "x", FilePath: "docs/handler.md", BaseScore: 0.8},
+	}
+	results, err := r.Rerank(context.Background(), "unrelated", candidates)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].ChunkID != "core" || results[2].ChunkID != "legacy" {
+		t.Errorf("Expected core boosted and legacy demoted, got %s, %s, %s", results[0].ChunkID, results[1].ChunkID, results[2].ChunkID)
+	}
+	boosts := map[string]float64{}
+	for _, res := range results {
+		boosts[res.ChunkID] = res.SignalScores["path_boost"]
+	}
+	if math.Abs(boosts["core"]-0.15) > 1e-9 || math.Abs(boosts["legacy"]+0.25) > 1e-9 || boosts["other"] != 0 {
+		t.Errorf("Expected matching boosts to add up, got %v", boosts)
+	}
+}
+
 func TestExtractQueryTerms(t *testing.T) {
 	tests := []struct {
 		query    string
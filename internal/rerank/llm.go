@@ -0,0 +1,341 @@
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// llmWeight is the share of the final score taken from the LLM's grade;
+	// the rest comes from the hybrid search score, which orders results
+	// within a grade.
+	llmWeight = 0.7
+	// maxGrade is the top of the relevance scale the LLM grades on.
+	maxGrade = 3
+)
+
+// DefaultLLMPrompt is the prompt template used when none is configured. It is
+// a text/template with .Query, .Candidates (the numbered candidate list) and
+// .Count (the number of candidates).
+const DefaultLLMPrompt = `You are grading the results of a code search.
+
+Query: {{.Query}}
+
+Grade how relevant each numbered code snippet below is to the query, from 0 (unrelated) to 3 (exactly what was asked for).
+
+{{.Candidates}}
+Reply with only a JSON array of {{.Count}} integer grades, one per snippet in order, for example [3, 0, 1].`
+
+// LLMConfig holds configuration for the LLM reranker.
+type LLMConfig struct {
+	// URL is the chat completions endpoint, e.g. http://localhost:8080/v1/chat/completions.
+	URL string
+	// Model is the chat model to ask.
+	Model string
+	// Prompt is the prompt template (default: DefaultLLMPrompt).
+	Prompt string
+	// MaxLines is the number of content lines sent per candidate.
+	MaxLines int
+	// MaxCandidates is the number of candidates graded in one prompt, which
+	// with MaxLines and maxDocumentBytes bounds the prompt's size.
+	MaxCandidates int
+	// Timeout bounds each request.
+	Timeout time.Duration
+}
+
+// DefaultLLMConfig returns the default LLM reranker configuration.
+func DefaultLLMConfig() LLMConfig {
+	return LLMConfig{
+		URL:           "http://localhost:8080/v1/chat/completions",
+		Prompt:        DefaultLLMPrompt,
+		MaxLines:      20,
+		MaxCandidates: 20,
+		Timeout:       30 * time.Second,
+	}
+}
+
+// LLMReranker grades candidates by asking a chat model served over an
+// OpenAI-compatible chat completions API, such as llama.cpp or vLLM.
+type LLMReranker struct {
+	server        *modelServer
+	model         string
+	prompt        *template.Template
+	maxLines      int
+	maxCandidates int
+}
+
+// llmPromptData is the data the prompt template is executed with.
+type llmPromptData struct {
+	Query      string
+	Candidates string
+	Count      int
+}
+
+// chatMessage is one message of a chat completions request.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest is the body posted to the chat completions endpoint.
+type chatRequest struct {
+	Model       string        `json:"model,omitempty"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	Stream      bool          `json:"stream"`
+}
+
+// chatResponse is the chat completions endpoint's reply.
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// NewLLMReranker creates an LLM reranker. It returns an error if the prompt
+// template does not parse.
+func NewLLMReranker(cfg LLMConfig) (*LLMReranker, error) {
+	defaults := DefaultLLMConfig()
+	if cfg.URL == "" {
+		cfg.URL = defaults.URL
+	}
+	if cfg.Prompt == "" {
+		cfg.Prompt = defaults.Prompt
+	}
+	if cfg.MaxLines <= 0 {
+		cfg.MaxLines = defaults.MaxLines
+	}
+	if cfg.MaxCandidates <= 0 {
+		cfg.MaxCandidates = defaults.MaxCandidates
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+
+	prompt, err := template.New("prompt").Parse(cfg.Prompt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reranker prompt template: %w", err)
+	}
+
+	return &LLMReranker{
+		server:        newModelServer("chat", cfg.URL, cfg.Timeout),
+		model:         cfg.Model,
+		prompt:        prompt,
+		maxLines:      cfg.MaxLines,
+		maxCandidates: cfg.MaxCandidates,
+	}, nil
+}
+
+// Rerank asks the model to grade the first maxCandidates candidates in one
+// request and returns them sorted. Candidates past the cap are scored as if
+// graded 0. A reply without a usable grade for each candidate sent is an
+// error.
+func (r *LLMReranker) Rerank(ctx context.Context, query string, candidates []Candidate) ([]RankedCandidate, error) {
+	if len(candidates) == 0 {
+		return []RankedCandidate{}, nil
+	}
+	var ungraded []Candidate
+	if len(candidates) > r.maxCandidates {
+		candidates, ungraded = candidates[:r.maxCandidates], candidates[r.maxCandidates:]
+	}
+
+	var prompt bytes.Buffer
+	data := llmPromptData{Query: query, Candidates: r.formatCandidates(candidates), Count: len(candidates)}
+	if err := r.prompt.Execute(&prompt, data); err != nil {
+		return nil, fmt.Errorf("failed to render reranker prompt: %w", err)
+	}
+
+	reply, err := r.complete(ctx, prompt.String())
+	if err != nil {
+		r.server.fail(ctx)
+		return nil, err
+	}
+
+	grades, err := parseGrades(reply, len(candidates))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RankedCandidate, 0, len(candidates)+len(ungraded))
+	for i, c := range candidates {
+		relevance := grades[i] / maxGrade
+		results = append(results, RankedCandidate{
+			Candidate:     c,
+			FinalScore:    llmWeight*relevance + (1-llmWeight)*c.BaseScore,
+			RerankerScore: relevance,
+			ModelScore:    true,
+			SignalScores:  map[string]float64{"llm_grade": relevance},
+		})
+	}
+	for _, c := range ungraded {
+		results = append(results, RankedCandidate{Candidate: c, FinalScore: (1 - llmWeight) * c.BaseScore})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].FinalScore != results[j].FinalScore {
+			return results[i].FinalScore > results[j].FinalScore
+		}
+		return results[i].ChunkID < results[j].ChunkID
+	})
+
+	return results, nil
+}
+
+// formatCandidates renders the numbered candidate list for the prompt: each
+// candidate's path, name, signature and first lines.
+func (r *LLMReranker) formatCandidates(candidates []Candidate) string {
+	var sb strings.Builder
+	for i, c := range candidates {
+		fmt.Fprintf(&sb, "[%d] %s", i+1, c.FilePath)
+		if c.Name != "" {
+			fmt.Fprintf(&sb, " %s", c.Name)
+		}
+		if c.ChunkType != "" {
+			fmt.Fprintf(&sb, " (%s)", c.ChunkType)
+		}
+		sb.WriteString("\n")
+		if c.Signature != "" {
+			sb.WriteString(c.Signature)
+			sb.WriteString("\n")
+		}
+
+		lines := strings.Split(truncateDocument(c.Content), "\n")
+		if len(lines) > r.maxLines {
+			lines = append(lines[:r.maxLines], "...")
+		}
+		sb.WriteString("```\n")
+		sb.WriteString(strings.Join(lines, "\n"))
+		sb.WriteString("\n```\n\n")
+	}
+	return sb.String()
+}
+
+// complete sends prompt to the chat model and returns its reply.
+func (r *LLMReranker) complete(ctx context.Context, prompt string) (string, error) {
+	var parsed chatResponse
+	err := r.server.post(ctx, chatRequest{
+		Model:    r.model,
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+	}, &parsed)
+	if err != nil {
+		return "", err
+	}
+	if len(parsed.Choices) == 0 {
+		return "", errors.New("chat server returned no choices")
+	}
+	return parsed.Choices[0].Message.Content, nil
+}
+
+// thinkBlock matches the reasoning some models emit before their answer.
+var thinkBlock = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+// parseGrades finds the JSON array of grades in a model's reply. Models wrap
+// answers in prose or code fences, so the first array with one grade per
+// candidate is used. Grades may be numbers, numeric strings, or objects with
+// a "grade", "score" or "relevance" and an optional 1-based "id" or "index".
+// Grades are clamped to 0..maxGrade.
+func parseGrades(reply string, count int) ([]float64, error) {
+	reply = thinkBlock.ReplaceAllString(reply, "")
+
+	for i := strings.IndexByte(reply, '['); i >= 0; {
+		var items []json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(reply[i:])).Decode(&items); err == nil && len(items) == count {
+			if grades, ok := gradeItems(items); ok {
+				return grades, nil
+			}
+		}
+		next := strings.IndexByte(reply[i+1:], '[')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return nil, fmt.Errorf("reranker reply has no JSON array of %d grades", count)
+}
+
+// gradeItems converts decoded array items to grades, in candidate order.
+func gradeItems(items []json.RawMessage) ([]float64, bool) {
+	grades := make([]float64, len(items))
+	placed := make([]bool, len(items))
+
+	for i, item := range items {
+		pos := i
+		grade, ok := gradeValue(item)
+		if !ok {
+			var obj map[string]json.RawMessage
+			if json.Unmarshal(item, &obj) != nil {
+				return nil, false
+			}
+			for _, key := range []string{"grade", "score", "relevance"} {
+				if v, found := obj[key]; found {
+					grade, ok = gradeValue(v)
+					break
+				}
+			}
+			if !ok {
+				return nil, false
+			}
+			for _, key := range []string{"id", "index"} {
+				if v, found := obj[key]; found {
+					id, idOK := gradeValue(v)
+					if !idOK || id < 1 || int(id) > len(items) {
+						return nil, false
+					}
+					pos = int(id) - 1
+					break
+				}
+			}
+		}
+		if placed[pos] {
+			return nil, false
+		}
+		placed[pos] = true
+		grades[pos] = clampGrade(grade)
+	}
+	return grades, true
+}
+
+// gradeValue decodes a JSON number or numeric string.
+func gradeValue(raw json.RawMessage) (float64, bool) {
+	var n float64
+	if json.Unmarshal(raw, &n) == nil {
+		return n, true
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		if n, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func clampGrade(g float64) float64 {
+	if g < 0 {
+		return 0
+	}
+	if g > maxGrade {
+		return maxGrade
+	}
+	return g
+}
+
+// Name returns the reranker identifier
+func (r *LLMReranker) Name() string {
+	return "llm"
+}
+
+// Available returns false for a short while after a request fails, so a
+// FallbackReranker goes straight to its fallback instead of timing out again.
+func (r *LLMReranker) Available(ctx context.Context) bool {
+	return r.server.ready()
+}
@@ -0,0 +1,202 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newChatServer starts a stand-in chat completions server that answers every
+// request with reply, and records the last prompt it was sent.
+func newChatServer(t *testing.T, reply string) (*httptest.Server, *string) {
+	t.Helper()
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Messages) == 0 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		prompt = req.Messages[0].Content
+
+		var resp chatResponse
+		resp.Choices = append(resp.Choices, struct {
+			Message chatMessage `json:"message"`
+		}{chatMessage{Role: "assistant", Content: reply}})
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, &prompt
+}
+
+func newTestLLMReranker(t *testing.T, cfg LLMConfig) *LLMReranker {
+	t.Helper()
+	r, err := NewLLMReranker(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return r
+}
+
+func TestLLMReranker_Name(t *testing.T) {
+	r := newTestLLMReranker(t, LLMConfig{})
+	if r.Name() != "llm" {
+		t.Errorf("Expected name 'llm', got '%s'", r.Name())
+	}
+}
+
+func TestLLMReranker_RanksByGrade(t *testing.T) {
+	server, prompt := newChatServer(t, "Here are the grades:\n```json\n[0, 3, 1]\n```")
+	r := newTestLLMReranker(t, LLMConfig{URL: server.URL, Model: "qwen2.5-coder", MaxLines: 2})
+
+	candidates := []Candidate{
+		{ChunkID: "a", Name: "parse", FilePath: "parse.go", Content: "func parse() {}", BaseScore: 0.9},
+		{ChunkID: "b", Name: "Retry", FilePath: "retry.go", ChunkType: "method", Signature: "func Retry(fn func() error) error", Content: "line 1\nline 2\nline 3", BaseScore: 0.5},
+		{ChunkID: "c", Name: "load", FilePath: "load.go", Content: "func load() {}", BaseScore: 0.7},
+	}
+	results, err := r.Rerank(context.Background(), "retry with backoff", candidates)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	order := []string{results[0].ChunkID, results[1].ChunkID, results[2].ChunkID}
+	if strings.Join(order, ",") != "b,c,a" {
+		t.Errorf("Expected order b,c,a, got %v", order)
+	}
+	if results[0].RerankerScore != 1 {
+		t.Errorf("Expected top grade to score 1, got %f", results[0].RerankerScore)
+	}
+	if !results[0].ModelScore {
+		t.Error("Expected the grade to be flagged as a model score")
+	}
+	if _, ok := results[0].SignalScores["llm_grade"]; !ok {
+		t.Error("llm_grade signal should be populated")
+	}
+
+	for _, want := range []string{"retry with backoff", "[2] retry.go Retry (method)", "func Retry(fn func() error) error", "line 2\n...", "3 integer grades"} {
+		if !strings.Contains(*prompt, want) {
+			t.Errorf("Prompt should contain %q, got:\n%s", want, *prompt)
+		}
+	}
+	if strings.Contains(*prompt, "line 3") {
+		t.Error("Prompt should only include the first MaxLines lines")
+	}
+}
+
+func TestLLMReranker_CustomPrompt(t *testing.T) {
+	server, prompt := newChatServer(t, "[2]")
+	r := newTestLLMReranker(t, LLMConfig{URL: server.URL, Prompt: "Q={{.Query}} N={{.Count}}"})
+
+	if _, err := r.Rerank(context.Background(), "find config", []Candidate{{ChunkID: "a"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *prompt != "Q=find config N=1" {
+		t.Errorf("Unexpected prompt %q", *prompt)
+	}
+
+	if _, err := NewLLMReranker(LLMConfig{Prompt: "{{.Query"}); err == nil {
+		t.Error("Expected an error for a prompt template that does not parse")
+	}
+}
+
+func TestLLMReranker_CapsCandidatesPerPrompt(t *testing.T) {
+	server, prompt := newChatServer(t, "[1, 2]")
+	r := newTestLLMReranker(t, LLMConfig{URL: server.URL, MaxCandidates: 2})
+
+	results, err := r.Rerank(context.Background(), "parse", []Candidate{
+		{ChunkID: "a", FilePath: "a.go", BaseScore: 0.9},
+		{ChunkID: "b", FilePath: "b.go", BaseScore: 0.8},
+		{ChunkID: "c", FilePath: "c.go", BaseScore: 0.7},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(*prompt, "c.go") || !strings.Contains(*prompt, "2 integer grades") {
+		t.Errorf("Prompt should hold only the first 2 candidates, got:\n%s", *prompt)
+	}
+	if len(results) != 3 || results[2].ChunkID != "c" {
+		t.Fatalf("Expected the ungraded candidate last, got %v", results)
+	}
+	if results[2].ModelScore {
+		t.Error("An ungraded candidate has no model score")
+	}
+}
+
+func TestLLMReranker_MalformedReplyFallsBack(t *testing.T) {
+	server, _ := newChatServer(t, "I think the second one is best.")
+	primary := newTestLLMReranker(t, LLMConfig{URL: server.URL})
+	r := NewFallbackReranker(primary, NewHeuristicReranker(), time.Second)
+
+	results, err := r.Rerank(context.Background(), "parse", []Candidate{{ChunkID: "a", Content: "parse"}, {ChunkID: "b"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := results[0].SignalScores["name_match"]; !ok {
+		t.Error("Expected heuristic scores after a malformed reply")
+	}
+	if !primary.Available(context.Background()) {
+		t.Error("A malformed reply should not make the server unavailable")
+	}
+}
+
+func TestLLMReranker_SlowReplyFallsBack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+	primary := newTestLLMReranker(t, LLMConfig{URL: server.URL})
+	r := NewFallbackReranker(primary, NewHeuristicReranker(), 20*time.Millisecond)
+
+	results, err := r.Rerank(context.Background(), "parse", []Candidate{{ChunkID: "a", Content: "parse"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := results[0].SignalScores["name_match"]; !ok {
+		t.Error("Expected heuristic scores after a slow reply")
+	}
+	if primary.Available(context.Background()) {
+		t.Error("A timed-out server should be unavailable for a while")
+	}
+}
+
+func TestParseGrades(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		count int
+		want  []float64
+	}{
+		{"bare array", "[3, 0, 1]", 3, []float64{3, 0, 1}},
+		{"wrapped in prose", "Grades: [1, 2] as requested.", 2, []float64{1, 2}},
+		{"code fence", "```json\n[2,2]\n```", 2, []float64{2, 2}},
+		{"numeric strings", `["3", "1"]`, 2, []float64{3, 1}},
+		{"objects by id", `[{"id": 2, "grade": 3}, {"id": 1, "grade": 0}]`, 2, []float64{0, 3}},
+		{"objects in order", `{"grades": [{"score": 1}, {"relevance": 2}]}`, 2, []float64{1, 2}},
+		{"clamped", "[5, -1]", 2, []float64{3, 0}},
+		{"skips wrong-length arrays", "Snippets [1] and [2]: [0, 3]", 2, []float64{0, 3}},
+		{"skips reasoning", "<think>maybe [1, 1]? no</think>[2, 0]", 2, []float64{2, 0}},
+	}
+	for _, tt := range tests {
+		got, err := parseGrades(tt.reply, tt.count)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+				break
+			}
+		}
+	}
+
+	for _, reply := range []string{"no grades", "[1, 2, 3]", `["high", "low"]`, `[{"id": 1, "grade": 1}, {"id": 1, "grade": 2}]`, "[1, 2"} {
+		if _, err := parseGrades(reply, 2); err == nil {
+			t.Errorf("Expected an error for reply %q", reply)
+		}
+	}
+}
@@ -1,8 +1,17 @@
 package rerank
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // Reranker scores and reorders search candidates
@@ -24,8 +33,10 @@ type Candidate struct {
 	Name      string    // Function/class name
 	FilePath  string    // Path to the file
 	ChunkType string    // "function", "class", "file", etc.
+	Signature string    // Function/class signature, if any
 	BaseScore float64   // Score from hybrid search
 	ModTime   time.Time // Last modification time
+	IsTest    bool      // Whether the file was flagged as a test file at index time
 }
 
 // RankedCandidate is a candidate with final scoring information
@@ -33,6 +44,7 @@ type RankedCandidate struct {
 	Candidate
 	FinalScore    float64            // Combined final score
 	RerankerScore float64            // Score from reranker alone
+	ModelScore    bool               // RerankerScore is a model's relevance from 0 to 1, not an adjustment
 	SignalScores  map[string]float64 // Individual signal contributions
 }
 
@@ -82,3 +94,114 @@ func (r *FallbackReranker) Name() string {
 func (r *FallbackReranker) Available(ctx context.Context) bool {
 	return r.primary.Available(ctx) || r.secondary.Available(ctx)
 }
+
+// maxDocumentBytes caps the content sent to a model for one candidate. Rerank
+// models read a few hundred tokens at most, so the rest would be truncated
+// anyway.
+const maxDocumentBytes = 4096
+
+// truncateDocument cuts content to at most maxDocumentBytes, on a rune
+// boundary so that no partial UTF-8 sequence is sent.
+func truncateDocument(content string) string {
+	if len(content) <= maxDocumentBytes {
+		return content
+	}
+	end := maxDocumentBytes
+	for end > 0 && !utf8.RuneStart(content[end]) {
+		end--
+	}
+	return content[:end]
+}
+
+// modelServer is the endpoint a model-based reranker posts JSON requests to.
+// It remembers failed requests so the reranker can report itself unavailable
+// for a while after one.
+type modelServer struct {
+	kind       string // Kind of server for error messages, e.g. "rerank"
+	url        string
+	httpClient *http.Client
+	cooldown   cooldown
+}
+
+// newModelServer creates a model server client for url.
+func newModelServer(kind, url string, timeout time.Duration) *modelServer {
+	return &modelServer{
+		kind:       kind,
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// post sends request as JSON and decodes the server's reply into response.
+func (s *modelServer) post(ctx context.Context, request, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", s.kind, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", s.kind, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("cannot connect to %s server at %s: %w", s.kind, s.url, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", s.kind, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s server returned status %d: %s", s.kind, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if err := json.Unmarshal(respBody, response); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", s.kind, err)
+	}
+	return nil
+}
+
+// fail records a failed request, starting the cooldown. A slow server counts
+// as a failure; a caller giving up does not.
+func (s *modelServer) fail(ctx context.Context) {
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		s.cooldown.fail()
+	}
+}
+
+// ready reports whether the cooldown after the last failure has passed.
+func (s *modelServer) ready() bool {
+	return s.cooldown.ready()
+}
+
+// unavailableCooldown is how long a model-based reranker reports itself
+// unavailable after a failed request, so searches don't each wait on a
+// server that is down.
+const unavailableCooldown = 30 * time.Second
+
+// cooldown tracks when a model-based reranker last failed.
+type cooldown struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// fail starts the cooldown.
+func (c *cooldown) fail() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.until = time.Now().Add(unavailableCooldown)
+}
+
+// ready reports whether the cooldown has passed.
+func (c *cooldown) ready() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().After(c.until)
+}
@@ -3,8 +3,12 @@ package rerank
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // ============================================================================
@@ -231,3 +235,74 @@ func TestFallbackReranker_ContextPropagated(t *testing.T) {
 
 	// The test passes if it doesn't hang - context cancellation is propagated
 }
+
+// ============================================================================
+// Model Server Tests
+// ============================================================================
+
+func TestModelServer_PostDecodesReply(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected JSON request, got %q", r.Header.Get("Content-Type"))
+		}
+		_, _ = w.Write([]byte(`{"answer": 42}`))
+	}))
+	defer server.Close()
+
+	var reply struct{ Answer int }
+	s := newModelServer("test", server.URL, time.Second)
+	if err := s.post(context.Background(), map[string]string{"q": "x"}, &reply); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reply.Answer != 42 {
+		t.Errorf("Expected answer 42, got %d", reply.Answer)
+	}
+}
+
+func TestModelServer_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var reply struct{}
+	err := newModelServer("test", server.URL, time.Second).post(context.Background(), struct{}{}, &reply)
+	if err == nil || !strings.Contains(err.Error(), "test server returned status 503: model not loaded") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+}
+
+func TestModelServer_FailStartsCooldownUnlessCanceled(t *testing.T) {
+	s := newModelServer("test", "http://localhost", time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.fail(ctx)
+	if !s.ready() {
+		t.Error("A caller giving up should not start the cooldown")
+	}
+
+	timedOut, cancelTimeout := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancelTimeout()
+	<-timedOut.Done()
+	s.fail(timedOut)
+	if s.ready() {
+		t.Error("A slow server should start the cooldown")
+	}
+}
+
+func TestTruncateDocument_KeepsRunesWhole(t *testing.T) {
+	// Each é is two bytes, so the cap falls in the middle of one
+	content := "x" + strings.Repeat("é", maxDocumentBytes)
+
+	truncated := truncateDocument(content)
+	if !utf8.ValidString(truncated) {
+		t.Error("Expected valid UTF-8 after truncation")
+	}
+	if len(truncated) != maxDocumentBytes-1 {
+		t.Errorf("Expected %d bytes, got %d", maxDocumentBytes-1, len(truncated))
+	}
+	if short := "héllo"; truncateDocument(short) != short {
+		t.Error("Expected short content to be left alone")
+	}
+}
@@ -3,12 +3,143 @@ package rerank
 import (
 	"strings"
 	"time"
+
+	"github.com/pommel-dev/pommel/internal/pathutil"
+)
+
+// Default signal weights: the largest boost or penalty each signal gives.
+const (
+	defaultNameMatchWeight   = 0.2
+	defaultExactPhraseWeight = 0.15
+	defaultPathMatchWeight   = 0.15
+	defaultTestPenalty       = 0.15
+	defaultMockPenalty       = 0.1
+	defaultRecencyWeight     = 0.1
+	defaultChunkTypeWeight   = 0.05
 )
 
-// Signal functions return a score boost/penalty in range [-0.2, 0.2]
+// SignalConfig sets how much each heuristic signal counts and which paths
+// the path-based signals apply to. Weights are the largest boost (or, for
+// penalties, the largest reduction) a signal gives; 0 turns a signal off.
+// Patterns are gitignore-style and matched against the path relative to the
+// project root.
+type SignalConfig struct {
+	NameMatch   float64
+	ExactPhrase float64
+	PathMatch   float64
+	TestPenalty float64
+	MockPenalty float64
+	Recency     float64
+	ChunkType   float64
+
+	TestPatterns []string
+	MockPatterns []string
+	PathBoosts   []PathBoost
+}
+
+// PathBoost adds Boost to results in files matching Pattern. A negative
+// boost demotes them.
+type PathBoost struct {
+	Pattern string
+	Boost   float64
+}
+
+// DefaultSignalConfig returns the default signal weights and patterns.
+func DefaultSignalConfig() SignalConfig {
+	return SignalConfig{
+		NameMatch:   defaultNameMatchWeight,
+		ExactPhrase: defaultExactPhraseWeight,
+		PathMatch:   defaultPathMatchWeight,
+		TestPenalty: defaultTestPenalty,
+		MockPenalty: defaultMockPenalty,
+		Recency:     defaultRecencyWeight,
+		ChunkType:   defaultChunkTypeWeight,
+		TestPatterns: []string{
+			"*_test.go",
+			"*.test.js", "*.test.ts",
+			"*.spec.js", "*.spec.ts", "*.spec.tsx", "*.spec.jsx",
+			"*_test.py", "test_*",
+			"*Test.java", "*Tests.java", "*Test.kt", "*Tests.kt",
+			"test/", "tests/",
+		},
+		MockPatterns: []string{"mock_*", "*_mock.*"},
+	}
+}
+
+// signalSet is a SignalConfig with its patterns parsed.
+type signalSet struct {
+	SignalConfig
+	testPatterns []pathutil.Pattern
+	mockPatterns []pathutil.Pattern
+	boosts       []pathutil.Pattern
+}
+
+// newSignalSet parses the patterns of cfg.
+func newSignalSet(cfg SignalConfig) *signalSet {
+	s := &signalSet{
+		SignalConfig: cfg,
+		testPatterns: pathutil.ParsePatterns(cfg.TestPatterns),
+		mockPatterns: pathutil.ParsePatterns(cfg.MockPatterns),
+		boosts:       make([]pathutil.Pattern, len(cfg.PathBoosts)),
+	}
+	for i, boost := range cfg.PathBoosts {
+		s.boosts[i] = pathutil.ParsePattern(boost.Pattern)
+	}
+	return s
+}
+
+// defaultSignals backs the package-level signal functions.
+var defaultSignals = newSignalSet(DefaultSignalConfig())
+
+// isTestFile reports whether a file holds tests: it was flagged as a test
+// file at index time, or it matches one of the test patterns.
+func (s *signalSet) isTestFile(filePath string, flagged bool) bool {
+	return flagged || (filePath != "" && pathutil.MatchPatterns(s.testPatterns, filePath))
+}
+
+// testPenalty returns the penalty for a test file.
+func (s *signalSet) testPenalty(filePath string, flagged bool) float64 {
+	if s.isTestFile(filePath, flagged) {
+		return -s.TestPenalty
+	}
+	return 0
+}
+
+// mockPenalty returns the penalty for a mock file that is not also a test file.
+func (s *signalSet) mockPenalty(filePath string, flagged bool) float64 {
+	if filePath == "" || s.isTestFile(filePath, flagged) {
+		return 0
+	}
+	if pathutil.MatchPatterns(s.mockPatterns, filePath) {
+		return -s.MockPenalty
+	}
+	return 0
+}
+
+// pathBoost returns the sum of the boosts whose pattern matches filePath.
+func (s *signalSet) pathBoost(filePath string) float64 {
+	if filePath == "" {
+		return 0
+	}
+	var boost float64
+	for i, pattern := range s.boosts {
+		if pattern.Match(filePath) {
+			boost += s.PathBoosts[i].Boost
+		}
+	}
+	return boost
+}
+
+// The package-level signal functions use the default weights and return a
+// score boost/penalty in range [-0.2, 0.2]
 
 // NameMatchSignal boosts results where name contains query terms
 func NameMatchSignal(name string, queryTerms []string) float64 {
+	return nameMatchSignal(name, queryTerms, defaultNameMatchWeight)
+}
+
+// nameMatchSignal gives half of weight per matching term, up to weight.
+func nameMatchSignal(name string, queryTerms []string, weight float64) float64 {
 	if name == "" || len(queryTerms) == 0 {
 		return 0
 	}
@@ -26,16 +157,20 @@ func NameMatchSignal(name string, queryTerms []string) float64 {
 		return 0
 	}
 
-	// More matches = higher boost, capped at 0.2
-	boost := float64(matches) * 0.1
-	if boost > 0.2 {
-		boost = 0.2
+	// More matches = higher boost, capped at weight
+	boost := float64(matches) * weight / 2
+	if boost > weight {
+		boost = weight
 	}
 	return boost
 }
 
 // ExactPhraseSignal boosts results containing exact query phrase
 func ExactPhraseSignal(content string, query string) float64 {
+	return exactPhraseSignal(content, query, defaultExactPhraseWeight)
+}
+
+func exactPhraseSignal(content string, query string, weight float64) float64 {
 	if query == "" {
 		return 0
 	}
@@ -44,13 +179,18 @@ func ExactPhraseSignal(content string, query string) float64 {
 	queryLower := strings.ToLower(query)
 
 	if strings.Contains(contentLower, queryLower) {
-		return 0.15 // Significant boost for exact phrase
+		return weight // Significant boost for exact phrase
 	}
 	return 0
 }
 
 // PathMatchSignal boosts results where file path contains query terms
 func PathMatchSignal(filePath string, queryTerms []string) float64 {
+	return pathMatchSignal(filePath, queryTerms, defaultPathMatchWeight)
+}
+
+// pathMatchSignal gives half of weight per matching term, up to weight.
+func pathMatchSignal(filePath string, queryTerms []string, weight float64) float64 {
 	if filePath == "" || len(queryTerms) == 0 {
 		return 0
 	}
@@ -68,62 +208,30 @@ func PathMatchSignal(filePath string, queryTerms []string) float64 {
 		return 0
 	}
 
-	// More matches = higher boost, capped at 0.15
-	boost := float64(matches) * 0.075
-	if boost > 0.15 {
-		boost = 0.15
+	// More matches = higher boost, capped at weight
+	boost := float64(matches) * weight / 2
+	if boost > weight {
+		boost = weight
 	}
 	return boost
 }
 
-// TestFilePenalty reduces score for test files
+// TestFilePenalty reduces score for test files, and by less for mock files
 func TestFilePenalty(filePath string) float64 {
-	pathLower := strings.ToLower(filePath)
-
-	// Check for test file patterns
-	testPatterns := []string{
-		"_test.go",
-		".test.js",
-		".test.ts",
-		".spec.js",
-		".spec.ts",
-		".spec.tsx",
-		".spec.jsx",
-		"_test.py",
-		"test_",
-	}
-
-	for _, pattern := range testPatterns {
-		if strings.Contains(pathLower, pattern) {
-			return -0.15 // Test file penalty
-		}
-	}
-
-	// Check for test directory
-	testDirs := []string{"/test/", "/tests/", "\\test\\", "\\tests\\"}
-	for _, dir := range testDirs {
-		if strings.Contains(pathLower, dir) {
-			return -0.15 // Test directory penalty
-		}
-	}
-	// Also check if path starts with test/
-	if strings.HasPrefix(pathLower, "test/") || strings.HasPrefix(pathLower, "tests/") {
-		return -0.15
-	}
-
-	// Check for mock files (smaller penalty)
-	mockPatterns := []string{"mock_", "_mock."}
-	for _, pattern := range mockPatterns {
-		if strings.Contains(pathLower, pattern) {
-			return -0.1 // Mock file penalty (smaller)
-		}
+	if penalty := defaultSignals.testPenalty(filePath, false); penalty != 0 {
+		return penalty
 	}
-
-	return 0
+	return defaultSignals.mockPenalty(filePath, false)
 }
 
 // RecencyBoost slightly boosts recently modified files
 func RecencyBoost(modTime time.Time, now time.Time) float64 {
+	return recencyBoost(modTime, now, defaultRecencyWeight)
+}
+
+// recencyBoost gives weight for files changed in the last day, and less for
+// the last week and month.
+func recencyBoost(modTime time.Time, now time.Time, weight float64) float64 {
 	if modTime.IsZero() {
 		return 0
 	}
@@ -137,17 +245,17 @@ func RecencyBoost(modTime time.Time, now time.Time) float64 {
 
 	// Very recent (< 1 day): max boost
 	if daysSince < 1 {
-		return 0.1
+		return weight
 	}
 
 	// Recent (< 7 days): medium boost
 	if daysSince < 7 {
-		return 0.05
+		return weight / 2
 	}
 
 	// Moderately recent (< 30 days): small boost
 	if daysSince < 30 {
-		return 0.02
+		return weight / 5
 	}
 
 	// Old file: no boost
@@ -156,6 +264,10 @@ func RecencyBoost(modTime time.Time, now time.Time) float64 {
 
 // ChunkTypeSignal adjusts based on chunk type relevance to query
 func ChunkTypeSignal(chunkType string, query string) float64 {
+	return chunkTypeSignal(chunkType, query, defaultChunkTypeWeight)
+}
+
+func chunkTypeSignal(chunkType string, query string, weight float64) float64 {
 	queryLower := strings.ToLower(query)
 	typeLower := strings.ToLower(chunkType)
 
@@ -192,11 +304,11 @@ func ChunkTypeSignal(chunkType string, query string) float64 {
 
 	// Adjust score based on match between query type and chunk type
 	if isVerbLike && (typeLower == "function" || typeLower == "method") {
-		return 0.05 // Boost functions for verb-like queries
+		return weight // Boost functions for verb-like queries
 	}
 
 	if isNounLike && (typeLower == "class" || typeLower == "struct" || typeLower == "type") {
-		return 0.05 // Boost classes for noun-like queries
+		return weight // Boost classes for noun-like queries
 	}
 
 	return 0 // Neutral for ambiguous cases
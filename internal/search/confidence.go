@@ -0,0 +1,106 @@
+package search
+
+import "sort"
+
+// Confidence levels reported in Response.Confidence.
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+const (
+	// highConfidenceRelevance is the top relevance a search needs for high
+	// confidence. For vector similarity it is a cosine similarity of about
+	// 0.78 between the query and the chunk.
+	highConfidenceRelevance = 0.6
+	// mediumConfidenceRelevance is the top relevance a search needs for
+	// medium confidence, a cosine similarity of about 0.5. Unrelated
+	// embeddings score about 0.41.
+	mediumConfidenceRelevance = 0.5
+	// confidenceMargin is how far the top relevance must stand above the
+	// mean of the next results for high confidence, unless both legs found it.
+	confidenceMargin = 0.05
+	// confidenceWindow is the number of results the top relevance is
+	// compared with.
+	confidenceWindow = 9
+)
+
+// relevance returns a result's ScoreDetails.Relevance, or 0 if it has none.
+func relevance(r Result) float64 {
+	if r.ScoreDetails == nil {
+		return 0
+	}
+	return r.ScoreDetails.Relevance
+}
+
+// assessConfidence rates how likely a ranking is to contain what was asked
+// for, from the absolute relevance of its results rather than their fused
+// scores, which are relative to the ranking: with RRF the top result always
+// scores the same whatever was asked. A highly relevant top result that
+// stands out from the rest, or that both the vector and keyword legs agree
+// on, is high confidence. A ranking of nothing but weakly related results is
+// what an unrelated query produces, and is low confidence.
+func assessConfidence(results []Result) string {
+	if len(results) == 0 {
+		return ConfidenceLow
+	}
+
+	scores := make([]float64, len(results))
+	top := 0
+	for i, r := range results {
+		scores[i] = relevance(r)
+		if scores[i] > scores[top] {
+			top = i
+		}
+	}
+	best := scores[top]
+	sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+
+	rest := scores[1:]
+	if len(rest) > confidenceWindow {
+		rest = rest[:confidenceWindow]
+	}
+	standsOut := len(rest) == 0
+	if len(rest) > 0 {
+		var sum float64
+		for _, s := range rest {
+			sum += s
+		}
+		standsOut = best-sum/float64(len(rest)) >= confidenceMargin
+	}
+
+	switch {
+	case best >= highConfidenceRelevance && (standsOut || results[top].MatchSource == "both"):
+		return ConfidenceHigh
+	case best >= mediumConfidenceRelevance:
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
+}
+
+// applyScoreCutoff drops results whose relevance is below minScore, or whose
+// score is below minRelative times the top score. Zero turns either cutoff
+// off.
+func applyScoreCutoff(results []Result, minScore, minRelative float64) []Result {
+	if len(results) == 0 || (minScore <= 0 && minRelative <= 0) {
+		return results
+	}
+
+	var best float64
+	for _, r := range results {
+		if float64(r.Score) > best {
+			best = float64(r.Score)
+		}
+	}
+	relativeCutoff := minRelative * best
+
+	kept := make([]Result, 0, len(results))
+	for _, r := range results {
+		if relevance(r) >= minScore && float64(r.Score) >= relativeCutoff {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,169 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scoredResults returns results with the given scores, as both their score
+// and relevance, each in its own file.
+func scoredResults(scores ...float32) []Result {
+	results := make([]Result, len(scores))
+	for i, score := range scores {
+		results[i] = Result{
+			Chunk:        &models.Chunk{ID: string(rune('a' + i))},
+			Score:        score,
+			ScoreDetails: &ScoreDetails{VectorScore: float64(score), Relevance: float64(score)},
+			MatchSource:  "vector",
+		}
+	}
+	return results
+}
+
+// axisEmbedder embeds each distinct text as a unit vector along an axis of
+// its own, so a query is either identical to a chunk or unrelated to it.
+type axisEmbedder struct {
+	*embedder.MockEmbedder
+	axes map[string]int
+}
+
+func newAxisEmbedder() *axisEmbedder {
+	return &axisEmbedder{MockEmbedder: embedder.NewMockEmbedder(), axes: make(map[string]int)}
+}
+
+func (e *axisEmbedder) embed(text string) []float32 {
+	axis, ok := e.axes[text]
+	if !ok {
+		axis = len(e.axes)
+		e.axes[text] = axis
+	}
+	embedding := make([]float32, e.Dimensions())
+	embedding[axis] = 1
+	return embedding
+}
+
+func (e *axisEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return e.embed(query), nil
+}
+
+func (e *axisEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = e.embed(text)
+	}
+	return embeddings, nil
+}
+
+func TestAssessConfidence(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []Result
+		want    string
+	}{
+		{"no results", nil, ConfidenceLow},
+		{"top stands out", scoredResults(0.9, 0.6, 0.55, 0.5), ConfidenceHigh},
+		{"single strong result", scoredResults(0.8), ConfidenceHigh},
+		{"flat high scores", scoredResults(0.75, 0.74, 0.73, 0.72), ConfidenceMedium},
+		{"middling top score", scoredResults(0.55, 0.3, 0.2), ConfidenceMedium},
+		{"weak scores", scoredResults(0.4, 0.38, 0.35), ConfidenceLow},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, assessConfidence(tt.results), tt.name)
+	}
+
+	// Both legs agreeing on the top result makes a flat ranking high confidence
+	agreed := scoredResults(0.75, 0.74, 0.73)
+	agreed[0].MatchSource = "both"
+	assert.Equal(t, ConfidenceHigh, assessConfidence(agreed))
+
+	// A top fused score says nothing about how relevant the results are
+	unrelated := scoredResults(1, 0.9, 0.8)
+	for i := range unrelated {
+		unrelated[i].ScoreDetails.Relevance = 0.41
+	}
+	assert.Equal(t, ConfidenceLow, assessConfidence(unrelated))
+}
+
+func TestApplyScoreCutoff(t *testing.T) {
+	results := scoredResults(0.9, 0.6, 0.5, 0.2)
+
+	assert.Len(t, applyScoreCutoff(results, 0, 0), 4, "no cutoff")
+	assert.Equal(t, []string{"a", "b", "c"}, resultIDs(applyScoreCutoff(results, 0.3, 0)))
+	assert.Equal(t, []string{"a", "b"}, resultIDs(applyScoreCutoff(results, 0, 0.6)), "60% of the top score is 0.54")
+	assert.Equal(t, []string{"a"}, resultIDs(applyScoreCutoff(results, 0.7, 0.5)), "the stricter cutoff wins")
+
+	results[0].ScoreDetails.Relevance = 0.1
+	assert.Equal(t, []string{"b", "c"}, resultIDs(applyScoreCutoff(results, 0.3, 0)), "min_score goes by relevance, not the fused score")
+}
+
+func TestSearch_UnrelatedQueryIsLowConfidence(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	emb := newAxisEmbedder()
+	for i := 0; i < 6; i++ {
+		insertIndexedChunk(t, ctx, database, emb, &models.Chunk{
+			FilePath: fmt.Sprintf("/project/handlers/h%02d.go", i), Language: "go",
+			Level: models.ChunkLevelMethod, StartLine: 1, EndLine: 3,
+			Name: fmt.Sprintf("Handle%d", i), Content: fmt.Sprintf("func Handle%d() { handle request %d }", i, i),
+		})
+	}
+	svc := NewServiceWithOptions(database, emb, ServiceOptions{Hybrid: DefaultHybridConfig(), RerankEnabled: true})
+
+	related, err := svc.Search(ctx, Query{Text: "func Handle0() { handle request 0 }"})
+	require.NoError(t, err)
+	require.NotEmpty(t, related.Results)
+	assert.Equal(t, ConfidenceHigh, related.Confidence)
+
+	unrelated, err := svc.Search(ctx, Query{Text: "quantum entanglement"})
+	require.NoError(t, err)
+	require.NotEmpty(t, unrelated.Results, "the nearest chunks are still returned")
+	assert.Equal(t, ConfidenceLow, unrelated.Confidence)
+
+	minScore := 0.5
+	cut, err := svc.Search(ctx, Query{Text: "quantum entanglement", MinScore: &minScore})
+	require.NoError(t, err)
+	assert.Empty(t, cut.Results, "min_score drops results that are only ranked first")
+	assert.Equal(t, ConfidenceLow, cut.Confidence, "confidence is rated before the cutoff")
+
+	kept, err := svc.Search(ctx, Query{Text: "func Handle0() { handle request 0 }", MinScore: &minScore})
+	require.NoError(t, err)
+	require.Len(t, kept.Results, 1)
+	assert.Equal(t, related.Results[0].Chunk.ID, kept.Results[0].Chunk.ID)
+}
+
+func TestSearch_MinRelativeScore(t *testing.T) {
+	svc := setupPageTest(t, 6)
+	ctx := context.Background()
+
+	all, err := svc.Search(ctx, Query{Text: "handle request"})
+	require.NoError(t, err)
+	require.Len(t, all.Results, 6)
+
+	relative := 1.0
+	best, err := svc.Search(ctx, Query{Text: "handle request", MinRelativeScore: &relative})
+	require.NoError(t, err)
+	require.NotEmpty(t, best.Results)
+	assert.Equal(t, all.Results[0].Chunk.ID, best.Results[0].Chunk.ID)
+	assert.Less(t, len(best.Results), len(all.Results))
+}
+
+func TestSearch_DefaultMinScore(t *testing.T) {
+	svc := setupPageTest(t, 4)
+	svc.options.MinScore = 10
+	ctx := context.Background()
+
+	resp, err := svc.Search(ctx, Query{Text: "handle request"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results, "the service default cuts every result")
+
+	off := 0.0
+	resp, err = svc.Search(ctx, Query{Text: "handle request", MinScore: &off})
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 4, "a request can turn the cutoff off")
+}
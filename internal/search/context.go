@@ -0,0 +1,161 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pommel-dev/pommel/internal/models"
+)
+
+const (
+	// MaxContextLines is the most lines read before and after a result.
+	MaxContextLines = 50
+	// maxParentHeaderLines is the most lines of a parent chunk returned as
+	// its header.
+	maxParentHeaderLines = 10
+)
+
+// ResultContext is the code around a result, kept apart from its content.
+type ResultContext struct {
+	// Before and After are the lines around the chunk, read from disk.
+	Before *SourceLines
+	After  *SourceLines
+	// Parent is the chunk's enclosing class or file.
+	Parent *ParentContext
+	// Imports is the import block of the chunk's file.
+	Imports *SourceLines
+}
+
+// SourceLines is a range of lines from a source file.
+type SourceLines struct {
+	StartLine int
+	EndLine   int
+	Content   string
+}
+
+// ParentContext describes a result's parent chunk.
+type ParentContext struct {
+	ID        string
+	Name      string
+	Level     string
+	Signature string
+	// Header is the start of the parent, up to the chunk or
+	// maxParentHeaderLines lines, such as a class declaration and its fields.
+	Header *SourceLines
+}
+
+// expandContext adds the context the query asks for to a page of results.
+// It works on a copy, since the page is a slice of a cached snapshot. Context
+// is best effort: a file that has gone from disk just has none.
+func (s *Service) expandContext(ctx context.Context, results []Result, query Query) []Result {
+	contextLines := query.ContextLines
+	if contextLines > MaxContextLines {
+		contextLines = MaxContextLines
+	}
+	if len(results) == 0 || (contextLines <= 0 && !query.IncludeParent && !query.IncludeImports) {
+		return results
+	}
+
+	expanded := make([]Result, len(results))
+	copy(expanded, results)
+
+	files := make(map[string][]byte)
+	readFile := func(path string) []byte {
+		if content, ok := files[path]; ok {
+			return content
+		}
+		content, err := os.ReadFile(s.diskPath(path))
+		if err != nil {
+			content = nil
+		}
+		files[path] = content
+		return content
+	}
+
+	for i := range expanded {
+		chunk := expanded[i].Chunk
+		rc := &ResultContext{}
+
+		if contextLines > 0 {
+			if content := readFile(chunk.FilePath); content != nil {
+				lines := strings.Split(string(content), "\n")
+				rc.Before = lineRange(lines, chunk.StartLine-contextLines, chunk.StartLine-1)
+				rc.After = lineRange(lines, chunk.EndLine+1, chunk.EndLine+contextLines)
+			}
+		}
+
+		if query.IncludeParent && chunk.ParentID != nil {
+			parent, err := s.db.GetChunk(ctx, *chunk.ParentID)
+			if err == nil && parent != nil {
+				rc.Parent = parentContext(parent, chunk)
+			}
+		}
+
+		// A file-level chunk already starts with its imports
+		if query.IncludeImports && s.options.Imports != nil && chunk.Level != models.ChunkLevelFile {
+			if content := readFile(chunk.FilePath); content != nil {
+				block, err := s.options.Imports(ctx, chunk.FilePath, content)
+				if err == nil && block != nil {
+					rc.Imports = &SourceLines{StartLine: block.StartLine, EndLine: block.EndLine, Content: block.Content}
+				}
+			}
+		}
+
+		if rc.Before != nil || rc.After != nil || rc.Parent != nil || rc.Imports != nil {
+			expanded[i].Context = rc
+		}
+	}
+
+	return expanded
+}
+
+// diskPath returns where an indexed file is on disk.
+func (s *Service) diskPath(path string) string {
+	if filepath.IsAbs(path) || s.options.ProjectRoot == "" {
+		return path
+	}
+	return filepath.Join(s.options.ProjectRoot, path)
+}
+
+// parentContext describes parent as the enclosing chunk of child.
+func parentContext(parent, child *models.Chunk) *ParentContext {
+	pc := &ParentContext{
+		ID:        parent.ID,
+		Name:      parent.Name,
+		Level:     string(parent.Level),
+		Signature: parent.Signature,
+	}
+
+	headerEnd := parent.StartLine + maxParentHeaderLines - 1
+	if child.StartLine > parent.StartLine && child.StartLine-1 < headerEnd {
+		headerEnd = child.StartLine - 1
+	}
+	lines := strings.Split(parent.Content, "\n")
+	header := lineRange(lines, 1, headerEnd-parent.StartLine+1)
+	if header != nil {
+		header.StartLine += parent.StartLine - 1
+		header.EndLine += parent.StartLine - 1
+		pc.Header = header
+	}
+	return pc
+}
+
+// lineRange returns lines start to end (1-based, inclusive), clamped to the
+// file, with trailing blank lines dropped. It returns nil for an empty range.
+func lineRange(lines []string, start, end int) *SourceLines {
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for end >= start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	if end < start {
+		return nil
+	}
+	return &SourceLines{StartLine: start, EndLine: end, Content: strings.Join(lines[start-1:end], "\n")}
+}
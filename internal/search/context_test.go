@@ -0,0 +1,156 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pommel-dev/pommel/internal/chunker"
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const contextTestSource = `package billing
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Invoice is a customer invoice.
+type Invoice struct {
+	Total int
+}
+
+// Validate checks the invoice total.
+func (i *Invoice) Validate() error {
+	if i.Total < 0 {
+		return errors.New("negative total")
+	}
+	return nil
+}
+
+func (i *Invoice) String() string { return fmt.Sprint(i.Total) }
+`
+
+// setupContextTest writes invoice.go to disk and indexes its file chunk and
+// its Validate method. It returns the service and the file's path.
+func setupContextTest(t *testing.T) (*Service, string) {
+	t.Helper()
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	path := filepath.Join(t.TempDir(), "invoice.go")
+	require.NoError(t, os.WriteFile(path, []byte(contextTestSource), 0644))
+
+	file := &models.Chunk{
+		FilePath: path, Language: "go", Level: models.ChunkLevelFile,
+		StartLine: 1, EndLine: 22, Name: path, Content: contextTestSource,
+	}
+	insertIndexedChunk(t, ctx, database, mockEmb, file)
+	insertIndexedChunk(t, ctx, database, mockEmb, &models.Chunk{
+		FilePath: path, Language: "go", Level: models.ChunkLevelMethod,
+		StartLine: 14, EndLine: 19, ParentID: &file.ID, Name: "Validate",
+		Signature: "func (i *Invoice) Validate() error",
+		Content:   "func (i *Invoice) Validate() error {\n\tif i.Total < 0 {\n\t\treturn errors.New(\"negative total\")\n\t}\n\treturn nil\n}",
+	})
+
+	registry, err := chunker.NewChunkerRegistry()
+	require.NoError(t, err)
+
+	svc := NewServiceWithOptions(database, mockEmb, ServiceOptions{
+		Hybrid:  DefaultHybridConfig(),
+		Imports: registry.ExtractImports,
+	})
+	return svc, path
+}
+
+// findResult returns the result for the chunk with the given name.
+func findResult(t *testing.T, results []Result, name string) Result {
+	t.Helper()
+	for _, r := range results {
+		if r.Chunk.Name == name {
+			return r
+		}
+	}
+	require.Failf(t, "result not found", "no result named %s", name)
+	return Result{}
+}
+
+func TestSearch_ContextLines(t *testing.T) {
+	svc, _ := setupContextTest(t)
+
+	resp, err := svc.Search(context.Background(), Query{Text: "validate invoice total", ContextLines: 2})
+	require.NoError(t, err)
+
+	result := findResult(t, resp.Results, "Validate")
+	require.NotNil(t, result.Context)
+	assert.Equal(t, &SourceLines{StartLine: 12, EndLine: 13, Content: "\n// Validate checks the invoice total."}, result.Context.Before)
+	assert.Equal(t, &SourceLines{StartLine: 20, EndLine: 21, Content: "\nfunc (i *Invoice) String() string { return fmt.Sprint(i.Total) }"}, result.Context.After)
+	assert.Nil(t, result.Context.Parent)
+	assert.Nil(t, result.Context.Imports)
+	assert.NotContains(t, result.Chunk.Content, "Validate checks", "context should not be mixed into the content")
+}
+
+func TestSearch_IncludeParentAndImports(t *testing.T) {
+	svc, path := setupContextTest(t)
+
+	resp, err := svc.Search(context.Background(), Query{Text: "validate invoice total", IncludeParent: true, IncludeImports: true})
+	require.NoError(t, err)
+
+	result := findResult(t, resp.Results, "Validate")
+	require.NotNil(t, result.Context)
+	assert.Nil(t, result.Context.Before)
+
+	parent := result.Context.Parent
+	require.NotNil(t, parent)
+	assert.Equal(t, path, parent.Name)
+	assert.Equal(t, "file", parent.Level)
+	require.NotNil(t, parent.Header)
+	assert.Equal(t, 1, parent.Header.StartLine)
+	assert.Equal(t, maxParentHeaderLines, parent.Header.EndLine)
+
+	assert.Equal(t, &SourceLines{StartLine: 3, EndLine: 6, Content: "import (\n\t\"errors\"\n\t\"fmt\"\n)"}, result.Context.Imports)
+
+	// The file chunk holds its own imports and has no parent
+	assert.Nil(t, findResult(t, resp.Results, path).Context)
+}
+
+func TestSearch_ContextDoesNotLeakIntoCachedResults(t *testing.T) {
+	svc, _ := setupContextTest(t)
+	ctx := context.Background()
+
+	_, err := svc.Search(ctx, Query{Text: "validate invoice total", ContextLines: 3, IncludeParent: true})
+	require.NoError(t, err)
+
+	resp, err := svc.Search(ctx, Query{Text: "validate invoice total"})
+	require.NoError(t, err)
+	assert.Nil(t, findResult(t, resp.Results, "Validate").Context)
+}
+
+func TestSearch_ContextFileGoneFromDisk(t *testing.T) {
+	svc, path := setupContextTest(t)
+	require.NoError(t, os.Remove(path))
+
+	resp, err := svc.Search(context.Background(), Query{Text: "validate invoice total", ContextLines: 2, IncludeParent: true, IncludeImports: true})
+	require.NoError(t, err)
+
+	result := findResult(t, resp.Results, "Validate")
+	require.NotNil(t, result.Context)
+	assert.Nil(t, result.Context.Before)
+	assert.Nil(t, result.Context.Imports)
+	assert.NotNil(t, result.Context.Parent, "the parent comes from the index")
+}
+
+func TestLineRange(t *testing.T) {
+	lines := []string{"a", "b", "", "c", ""}
+
+	assert.Equal(t, &SourceLines{StartLine: 1, EndLine: 2, Content: "a\nb"}, lineRange(lines, -3, 3))
+	assert.Equal(t, &SourceLines{StartLine: 4, EndLine: 4, Content: "c"}, lineRange(lines, 4, 10))
+	assert.Nil(t, lineRange(lines, 5, 5))
+	assert.Nil(t, lineRange(lines, 3, 2))
+}
@@ -0,0 +1,217 @@
+package search
+
+import (
+	"context"
+	"math"
+)
+
+const (
+	// sameFileRedundancy is added to the similarity of two results from the
+	// same file, so a file's chunks don't crowd out other files.
+	sameFileRedundancy = 0.2
+	// parentRedundancy is added to the similarity of a chunk and its parent,
+	// or of two chunks with the same parent, such as a class and its methods.
+	parentRedundancy = 0.3
+	// diversityPoolFactor is how many more candidates than results a page
+	// is picked from when diversifying, so other files have a chance to be
+	// picked.
+	diversityPoolFactor = 3
+)
+
+// arrange returns snap with at least n results moved from its pool onto
+// pages, or as many as the ranking holds. Without diversity or a per-file
+// cap the pool is placed in ranked order. Otherwise pages of pageSize
+// results are filled one at a time, each by arrangePage. short is set when
+// the pool ran out before the ranking did, so it needs to be ranked deeper.
+func (s *Service) arrange(ctx context.Context, snap *snapshot, query Query, n, pageSize int) (arranged *snapshot, short bool, err error) {
+	results := append([]Result(nil), snap.results...)
+	pool := snap.pool
+
+	if query.Diversity <= 0 && query.MaxPerFile <= 0 {
+		take := n - len(results)
+		if take > len(pool) {
+			if !snap.complete {
+				return snap, true, nil
+			}
+			take = len(pool)
+		}
+		if take > 0 {
+			results = append(results, pool[:take]...)
+			pool = pool[take:]
+		}
+		return snap.with(results, pool), false, nil
+	}
+
+	for len(results) < n && len(pool) > 0 {
+		var page []Result
+		page, pool, short, err = s.arrangePage(ctx, pool, query.Diversity, query.MaxPerFile, pageSize, snap.complete)
+		if err != nil || short {
+			return snap, short, err
+		}
+		results = append(results, page...)
+		if len(page) < pageSize {
+			// A short page can only be followed by results from files at
+			// their cap, and pages after it wouldn't line up with Limit
+			pool = nil
+		}
+	}
+	return snap.with(results, pool), false, nil
+}
+
+// arrangePage picks the next page of results from the ranked pool and
+// returns it with the rest of the pool. Candidates are the first pooled
+// results, skipping those from a file already at maxPerFile candidates:
+// pageSize of them, or pageSize*diversityPoolFactor for pickMMR to choose
+// from with diversity above 0. short is set when the pool holds fewer
+// candidates than that and the ranking isn't complete.
+func (s *Service) arrangePage(ctx context.Context, pool []Result, diversity float64, maxPerFile, pageSize int, complete bool) (page, rest []Result, short bool, err error) {
+	window := pageSize
+	if diversity > 0 {
+		window *= diversityPoolFactor
+	}
+	if diversity > 1 {
+		diversity = 1
+	}
+
+	perFile := make(map[string]int)
+	var candidates []int
+	for i, r := range pool {
+		if len(candidates) == window {
+			break
+		}
+		if maxPerFile > 0 && perFile[r.Chunk.FilePath] >= maxPerFile {
+			continue
+		}
+		perFile[r.Chunk.FilePath]++
+		candidates = append(candidates, i)
+	}
+	if len(candidates) < window && !complete {
+		return nil, pool, true, nil
+	}
+
+	var picks []int
+	if diversity > 0 {
+		picks, err = s.pickMMR(ctx, pool, candidates, diversity, pageSize)
+		if err != nil {
+			return nil, pool, false, err
+		}
+	} else {
+		picks = candidates
+		if len(picks) > pageSize {
+			picks = picks[:pageSize]
+		}
+	}
+
+	picked := make(map[int]bool, len(picks))
+	page = make([]Result, len(picks))
+	for j, i := range picks {
+		picked[i] = true
+		page[j] = pool[i]
+	}
+	rest = make([]Result, 0, len(pool)-len(picks))
+	for i, r := range pool {
+		if !picked[i] {
+			rest = append(rest, r)
+		}
+	}
+	return page, rest, false, nil
+}
+
+// pickMMR picks up to pageSize of the candidate pool indexes by maximal
+// marginal relevance (MMR), in pick order. Each pick maximizes
+// (1-diversity)*relevance - diversity*redundancy, where relevance is the
+// candidate's score relative to the best candidate and redundancy is its
+// highest similarity to a result already picked. Similarity is the cosine
+// of the stored chunk vectors plus a penalty for chunks of the same file or
+// parent. Embeddings are loaded for the candidates only.
+func (s *Service) pickMMR(ctx context.Context, pool []Result, candidates []int, diversity float64, pageSize int) ([]int, error) {
+	ids := make([]string, len(candidates))
+	var best float64
+	for j, i := range candidates {
+		ids[j] = pool[i].Chunk.ID
+		best = math.Max(best, float64(pool[i].Score))
+	}
+	embeddings, err := s.db.GetEmbeddings(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// redundancy[j] is candidate j's highest similarity to a picked result
+	redundancy := make([]float64, len(candidates))
+	picked := make([]bool, len(candidates))
+	picks := make([]int, 0, pageSize)
+
+	for len(picks) < pageSize && len(picks) < len(candidates) {
+		next, nextScore := -1, math.Inf(-1)
+		for j, i := range candidates {
+			if picked[j] {
+				continue
+			}
+			relevance := 0.0
+			if best > 0 {
+				relevance = float64(pool[i].Score) / best
+			}
+			score := (1-diversity)*relevance - diversity*redundancy[j]
+			if score > nextScore {
+				next, nextScore = j, score
+			}
+		}
+
+		picked[next] = true
+		picks = append(picks, candidates[next])
+		for j, i := range candidates {
+			if !picked[j] {
+				sim := similarity(pool[i], pool[candidates[next]], embeddings)
+				redundancy[j] = math.Max(redundancy[j], sim)
+			}
+		}
+	}
+
+	return picks, nil
+}
+
+// similarity returns how redundant two results are with each other, from 0
+// to 1.
+func similarity(a, b Result, embeddings map[string][]float32) float64 {
+	sim := cosineSimilarity(embeddings[a.Chunk.ID], embeddings[b.Chunk.ID])
+	if a.Chunk.FilePath == b.Chunk.FilePath {
+		sim += sameFileRedundancy
+	}
+	if related(a, b) {
+		sim += parentRedundancy
+	}
+	return math.Max(0, math.Min(sim, 1))
+}
+
+// related reports whether one result is the parent of the other, or both
+// have the same parent.
+func related(a, b Result) bool {
+	pa, pb := a.Chunk.ParentID, b.Chunk.ParentID
+	switch {
+	case pa != nil && *pa == b.Chunk.ID:
+		return true
+	case pb != nil && *pb == a.Chunk.ID:
+		return true
+	case pa != nil && pb != nil && *pa == *pb:
+		return true
+	}
+	return false
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors, or 0 if
+// either is missing.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDiversityTest indexes six retry methods of one class in retry.go and
+// one retry helper in each of two other files.
+func setupDiversityTest(t *testing.T) *Service {
+	t.Helper()
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	class := &models.Chunk{
+		FilePath: "/project/retry.go", Language: "go", Level: models.ChunkLevelClass,
+		StartLine: 1, EndLine: 60, Name: "Retrier", Content: "type Retrier struct { retry backoff }",
+	}
+	insertIndexedChunk(t, ctx, database, mockEmb, class)
+	for i := 0; i < 6; i++ {
+		insertIndexedChunk(t, ctx, database, mockEmb, &models.Chunk{
+			FilePath: "/project/retry.go", Language: "go", Level: models.ChunkLevelMethod,
+			StartLine: 10 * (i + 1), EndLine: 10*(i+1) + 5, ParentID: &class.ID,
+			Name: fmt.Sprintf("Retry%d", i), Content: fmt.Sprintf("func (r *Retrier) Retry%d() { retry backoff retry backoff }", i),
+		})
+	}
+	for _, name := range []string{"http", "queue"} {
+		insertIndexedChunk(t, ctx, database, mockEmb, &models.Chunk{
+			FilePath: "/project/" + name + ".go", Language: "go", Level: models.ChunkLevelMethod, StartLine: 1, EndLine: 5,
+			Name: name + "Retry", Content: fmt.Sprintf("func %sRetry() { retry %s }", name, name),
+		})
+	}
+
+	return NewServiceWithOptions(database, mockEmb, ServiceOptions{Hybrid: DefaultHybridConfig()})
+}
+
+func filesOf(results []Result) map[string]int {
+	files := make(map[string]int)
+	for _, r := range results {
+		files[r.Chunk.FilePath]++
+	}
+	return files
+}
+
+func TestSearch_MaxPerFile(t *testing.T) {
+	svc := setupDiversityTest(t)
+	ctx := context.Background()
+
+	resp, err := svc.Search(ctx, Query{Text: "retry backoff", Limit: 10, MaxPerFile: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"/project/retry.go": 2, "/project/http.go": 1, "/project/queue.go": 1}, filesOf(resp.Results))
+}
+
+func TestSearch_DiversityPromotesOtherFiles(t *testing.T) {
+	svc := setupDiversityTest(t)
+	ctx := context.Background()
+
+	plain, err := svc.Search(ctx, Query{Text: "retry backoff", Limit: 3})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"/project/retry.go": 3}, filesOf(plain.Results), "without diversity one file fills the page")
+
+	diverse, err := svc.Search(ctx, Query{Text: "retry backoff", Limit: 3, Diversity: 0.8})
+	require.NoError(t, err)
+	assert.Equal(t, plain.Results[0].Chunk.ID, diverse.Results[0].Chunk.ID, "the best result should stay first")
+	assert.Len(t, filesOf(diverse.Results), 3, "diversity should pick one result per file")
+}
+
+func TestSearch_MaxPerFileAppliesPerPage(t *testing.T) {
+	svc := setupDiversityTest(t)
+	ctx := context.Background()
+
+	first, err := svc.Search(ctx, Query{Text: "retry backoff", Limit: 2, MaxPerFile: 1})
+	require.NoError(t, err)
+	require.Len(t, first.Results, 2)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := svc.Search(ctx, Query{Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.Results, 2)
+
+	for _, page := range [][]Result{first.Results, second.Results} {
+		for file, n := range filesOf(page) {
+			assert.Equal(t, 1, n, "%s should have one result per page", file)
+		}
+	}
+	assert.Contains(t, filesOf(second.Results), "/project/retry.go", "results held back by the cap should move to the next page")
+	assert.NotContains(t, resultIDs(second.Results), first.Results[0].Chunk.ID)
+	assert.NotContains(t, resultIDs(second.Results), first.Results[1].Chunk.ID)
+}
+
+func TestArrangePage(t *testing.T) {
+	svc := setupDiversityTest(t)
+	ctx := context.Background()
+	pool := []Result{
+		{Chunk: &models.Chunk{ID: "a1", FilePath: "a.go"}},
+		{Chunk: &models.Chunk{ID: "a2", FilePath: "a.go"}},
+		{Chunk: &models.Chunk{ID: "b1", FilePath: "b.go"}},
+		{Chunk: &models.Chunk{ID: "a3", FilePath: "a.go"}},
+		{Chunk: &models.Chunk{ID: "c1", FilePath: "c.go"}},
+	}
+
+	page, rest, short, err := svc.arrangePage(ctx, pool, 0, 1, 2, false)
+	require.NoError(t, err)
+	assert.False(t, short)
+	assert.Equal(t, []string{"a1", "b1"}, resultIDs(page))
+	assert.Equal(t, []string{"a2", "a3", "c1"}, resultIDs(rest))
+
+	_, _, short, err = svc.arrangePage(ctx, pool, 0, 1, 4, false)
+	require.NoError(t, err)
+	assert.True(t, short, "too few candidates for a page should ask for a deeper ranking")
+
+	page, rest, short, err = svc.arrangePage(ctx, pool, 0, 1, 4, true)
+	require.NoError(t, err)
+	assert.False(t, short)
+	assert.Equal(t, []string{"a1", "b1", "c1"}, resultIDs(page))
+	assert.Equal(t, []string{"a2", "a3"}, resultIDs(rest))
+}
+
+func TestSimilarity_PenalizesSameFileAndParent(t *testing.T) {
+	parentID := "class"
+	class := Result{Chunk: &models.Chunk{ID: "class", FilePath: "a.go"}}
+	method := Result{Chunk: &models.Chunk{ID: "m1", FilePath: "a.go", ParentID: &parentID}}
+	sibling := Result{Chunk: &models.Chunk{ID: "m2", FilePath: "a.go", ParentID: &parentID}}
+	other := Result{Chunk: &models.Chunk{ID: "x", FilePath: "b.go"}}
+	embeddings := map[string][]float32{}
+
+	assert.InDelta(t, sameFileRedundancy+parentRedundancy, similarity(class, method, embeddings), 1e-9)
+	assert.InDelta(t, sameFileRedundancy+parentRedundancy, similarity(method, sibling, embeddings), 1e-9)
+	assert.Equal(t, 0.0, similarity(method, other, embeddings))
+
+	embeddings["m1"] = []float32{1, 0}
+	embeddings["x"] = []float32{1, 0}
+	assert.Equal(t, 1.0, similarity(method, other, embeddings))
+}
@@ -0,0 +1,304 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/pommel-dev/pommel/internal/pathutil"
+)
+
+// Test-code inclusion modes for Query.Tests.
+const (
+	// TestsInclude returns results from test and non-test files alike.
+	TestsInclude = "include"
+	// TestsExclude drops results from test files.
+	TestsExclude = "exclude"
+	// TestsOnly returns results from test files only.
+	TestsOnly = "only"
+)
+
+// ErrInvalidTests is returned when a query's test-code inclusion mode is
+// unknown.
+var ErrInvalidTests = errors.New("invalid tests mode")
+
+// searchFilter is the resolved form of a query's filters. The same filter is
+// applied to the vector leg, the keyword leg and the candidates passed to the
+// reranker, so every stage agrees on which chunks are eligible.
+type searchFilter struct {
+	levels     []string
+	languages  []string
+	subproject string
+	pathPrefix string
+	// testFiles restricts results to test files (true) or other files
+	// (false), or is nil if both are allowed.
+	testFiles *bool
+	// files is the set of files allowed by the extension and glob filters,
+	// or nil if there are none.
+	files *fileSet
+}
+
+// fileSet is the set of indexed files allowed by a query's extension and
+// glob filters. It is shared through fileSetCache and must not be modified.
+type fileSet struct {
+	paths   []string
+	allowed map[string]bool
+}
+
+// maxCachedFileSets is the number of resolved file sets kept in memory.
+const maxCachedFileSets = 32
+
+// fileSetCache keeps the file sets of recent filters for one index
+// generation, so that repeated searches don't list and match every indexed
+// file again. A new generation drops every entry.
+type fileSetCache struct {
+	mu         sync.Mutex
+	generation int64
+	entries    map[string]*fileSet
+	order      []string
+}
+
+func newFileSetCache() *fileSetCache {
+	return &fileSetCache{entries: make(map[string]*fileSet)}
+}
+
+// get returns the file set for key at generation, or nil if it isn't cached.
+func (c *fileSetCache) get(generation int64, key string) *fileSet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return nil
+	}
+	return c.entries[key]
+}
+
+// put stores a file set, dropping entries of other generations and the
+// oldest entry if the cache is full.
+func (c *fileSetCache) put(generation int64, key string, files *fileSet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		c.generation = generation
+		c.entries = make(map[string]*fileSet)
+		c.order = nil
+	}
+	if _, ok := c.entries[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = files
+
+	for len(c.order) > maxCachedFileSets {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// resolveFilter resolves a query's filters, within the resolved scope,
+// against the index.
+func (s *Service) resolveFilter(ctx context.Context, query Query, scope *ResolvedScope) (searchFilter, error) {
+	pathPrefix := scope.PathPrefix
+	filter := searchFilter{
+		levels:     query.Levels,
+		languages:  s.resolveLanguages(query.Languages),
+		subproject: scope.Subproject,
+		pathPrefix: pathPrefix,
+		testFiles:  testFilesFor(query.Tests),
+	}
+
+	if len(query.Extensions) == 0 && len(query.Include) == 0 && len(query.Exclude) == 0 {
+		return filter, nil
+	}
+
+	generation, err := s.db.IndexGeneration(ctx)
+	if err != nil {
+		return searchFilter{}, err
+	}
+	key, err := fileSetKey(query, pathPrefix)
+	if err != nil {
+		return searchFilter{}, err
+	}
+	if files := s.fileSets.get(generation, key); files != nil {
+		filter.files = files
+		return filter, nil
+	}
+
+	files, err := s.matchFiles(ctx, query, pathPrefix)
+	if err != nil {
+		return searchFilter{}, err
+	}
+	s.fileSets.put(generation, key, files)
+	filter.files = files
+	return filter, nil
+}
+
+// fileSetKey identifies the file set of a query's extension and glob filters
+// under a path prefix.
+func fileSetKey(query Query, pathPrefix string) (string, error) {
+	data, err := json.Marshal([]any{query.Extensions, query.Include, query.Exclude, pathPrefix})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode file filter: %w", err)
+	}
+	return string(data), nil
+}
+
+// matchFiles returns the indexed files under pathPrefix that pass a query's
+// extension and glob filters.
+func (s *Service) matchFiles(ctx context.Context, query Query, pathPrefix string) (*fileSet, error) {
+	extensions := make(map[string]bool, len(query.Extensions))
+	for _, ext := range query.Extensions {
+		extensions[normalizeExtension(ext)] = true
+	}
+	include := pathutil.ParsePatterns(query.Include)
+	exclude := pathutil.ParsePatterns(query.Exclude)
+
+	indexed, err := s.db.ListFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	files := &fileSet{allowed: make(map[string]bool)}
+	for _, f := range indexed {
+		if pathPrefix != "" && !strings.HasPrefix(f.Path, pathPrefix) {
+			continue
+		}
+		if len(extensions) > 0 && !extensions[strings.ToLower(filepath.Ext(f.Path))] {
+			continue
+		}
+		rel := s.filterPath(f.Path)
+		if len(include) > 0 && !pathutil.MatchPatterns(include, rel) {
+			continue
+		}
+		if pathutil.MatchPatterns(exclude, rel) {
+			continue
+		}
+		files.paths = append(files.paths, f.Path)
+		files.allowed[f.Path] = true
+	}
+
+	return files, nil
+}
+
+// resolveTests validates a test-code inclusion mode, returning TestsInclude
+// for an empty mode.
+func resolveTests(mode string) (string, error) {
+	switch mode {
+	case "":
+		return TestsInclude, nil
+	case TestsInclude, TestsExclude, TestsOnly:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: unknown mode %q, use %q, %q or %q", ErrInvalidTests, mode, TestsInclude, TestsExclude, TestsOnly)
+	}
+}
+
+// testFilesFor returns the test file flag a mode restricts results to, or
+// nil if it allows both.
+func testFilesFor(mode string) *bool {
+	var isTest bool
+	switch mode {
+	case TestsExclude:
+	case TestsOnly:
+		isTest = true
+	default:
+		return nil
+	}
+	return &isTest
+}
+
+// resolveLanguages normalizes language filters. A value that is a file
+// extension of a known language, such as "ts", is replaced by that language.
+func (s *Service) resolveLanguages(languages []string) []string {
+	if len(languages) == 0 {
+		return nil
+	}
+
+	resolved := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if lang == "" {
+			continue
+		}
+		if s.options.LanguageForExtension != nil {
+			if name, ok := s.options.LanguageForExtension(normalizeExtension(lang)); ok {
+				lang = name
+			}
+		}
+		resolved = append(resolved, lang)
+	}
+	return resolved
+}
+
+// filterPath returns the path include and exclude patterns are matched
+// against: relative to the project root, as in .gitignore.
+func (s *Service) filterPath(path string) string {
+	root := s.options.ProjectRoot
+	if root == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// normalizeExtension lowercases an extension and adds the leading dot.
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// filePaths returns the allowed files for the search legs, or nil if the
+// filter allows any file.
+func (f searchFilter) filePaths() []string {
+	if f.files == nil {
+		return nil
+	}
+	if f.files.paths == nil {
+		return []string{}
+	}
+	return f.files.paths
+}
+
+// matches reports whether a chunk satisfies the filter.
+func (f searchFilter) matches(chunk *models.Chunk) bool {
+	if len(f.levels) > 0 && !containsString(f.levels, string(chunk.Level)) {
+		return false
+	}
+	if len(f.languages) > 0 && !containsString(f.languages, chunk.Language) {
+		return false
+	}
+	if f.subproject != "" && (chunk.SubprojectID == nil || *chunk.SubprojectID != f.subproject) {
+		return false
+	}
+	if f.pathPrefix != "" && !strings.HasPrefix(chunk.FilePath, f.pathPrefix) {
+		return false
+	}
+	if f.testFiles != nil && chunk.IsTest != *f.testFiles {
+		return false
+	}
+	if f.files != nil && !f.files.allowed[chunk.FilePath] {
+		return false
+	}
+	return true
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/pommel-dev/pommel/internal/rerank"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupFilterTest indexes retry helpers in several languages and directories
+// of a project at /project.
+func setupFilterTest(t *testing.T) *Service {
+	t.Helper()
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for _, chunk := range []*models.Chunk{
+		{FilePath: "/project/api/retry.go", Language: "go", Level: models.ChunkLevelMethod, Name: "Retry", Content: "func Retry() { backoff() }"},
+		{FilePath: "/project/api/retry_test.go", Language: "go", Level: models.ChunkLevelMethod, Name: "TestRetry", Content: "func TestRetry() { Retry() }"},
+		{FilePath: "/project/vendor/lib/retry.go", Language: "go", Level: models.ChunkLevelMethod, Name: "Do", Content: "func Do() { retry() }"},
+		{FilePath: "/project/web/retry.ts", Language: "typescript", Level: models.ChunkLevelMethod, Name: "retry", Content: "function retry() { backoff() }"},
+	} {
+		chunk.StartLine, chunk.EndLine = 1, 3
+		insertIndexedChunk(t, ctx, database, mockEmb, chunk)
+	}
+	require.NoError(t, database.SetTestFiles(ctx, map[string]bool{"/project/api/retry_test.go": true}))
+
+	return NewServiceWithOptions(database, mockEmb, ServiceOptions{
+		Hybrid:      DefaultHybridConfig(),
+		ProjectRoot: "/project",
+		LanguageForExtension: func(ext string) (string, bool) {
+			if ext == ".ts" {
+				return "typescript", true
+			}
+			return "", false
+		},
+	})
+}
+
+func TestSearch_Filters(t *testing.T) {
+	svc := setupFilterTest(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{
+			name:  "exclude globs",
+			query: Query{Exclude: []string{"**/*_test.go", "vendor/**"}},
+			want:  []string{"/project/api/retry.go", "/project/web/retry.ts"},
+		},
+		{
+			name:  "include glob",
+			query: Query{Include: []string{"api/**"}},
+			want:  []string{"/project/api/retry.go", "/project/api/retry_test.go"},
+		},
+		{
+			name:  "include with negation",
+			query: Query{Include: []string{"api/**", "!**/*_test.go"}},
+			want:  []string{"/project/api/retry.go"},
+		},
+		{
+			name:  "language by extension",
+			query: Query{Languages: []string{"ts"}},
+			want:  []string{"/project/web/retry.ts"},
+		},
+		{
+			name:  "extension",
+			query: Query{Extensions: []string{"go"}, Exclude: []string{"vendor/"}},
+			want:  []string{"/project/api/retry.go", "/project/api/retry_test.go"},
+		},
+		{
+			name:  "combined with path scope",
+			query: Query{PathPrefix: "api/", Languages: []string{"go"}, Exclude: []string{"**/*_test.go"}},
+			want:  []string{"/project/api/retry.go"},
+		},
+		{
+			name:  "tests only",
+			query: Query{Tests: TestsOnly},
+			want:  []string{"/project/api/retry_test.go"},
+		},
+		{
+			name:  "tests excluded",
+			query: Query{Tests: TestsExclude, PathPrefix: "api/"},
+			want:  []string{"/project/api/retry.go"},
+		},
+		{
+			name:  "tests included",
+			query: Query{Tests: TestsInclude, PathPrefix: "api/"},
+			want:  []string{"/project/api/retry.go", "/project/api/retry_test.go"},
+		},
+		{
+			name:  "nothing matches",
+			query: Query{Include: []string{"docs/**"}},
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		query := tt.query
+		query.Text = "retry"
+		query.Limit = 10
+
+		resp, err := svc.Search(ctx, query)
+		require.NoError(t, err, tt.name)
+		assert.ElementsMatch(t, tt.want, resultPaths(resp.Results), tt.name)
+	}
+}
+
+func TestResolveFilter_CachesFileSetsPerGeneration(t *testing.T) {
+	svc := setupFilterTest(t)
+	ctx := context.Background()
+	query := Query{Extensions: []string{"go"}, Exclude: []string{"vendor/"}}
+	scope := &ResolvedScope{Mode: ScopeAll}
+
+	first, err := svc.resolveFilter(ctx, query, scope)
+	require.NoError(t, err)
+	second, err := svc.resolveFilter(ctx, query, scope)
+	require.NoError(t, err)
+	assert.Same(t, first.files, second.files, "an unchanged index should reuse the file set")
+	assert.ElementsMatch(t, []string{"/project/api/retry.go", "/project/api/retry_test.go"}, second.filePaths())
+
+	// Indexing a file starts a new generation
+	insertIndexedChunk(t, ctx, svc.db, embedder.NewMockEmbedder(), &models.Chunk{
+		FilePath: "/project/api/client.go", Language: "go", Level: models.ChunkLevelMethod,
+		Name: "Do", Content: "func Do() { Retry() }", StartLine: 1, EndLine: 3,
+	})
+	third, err := svc.resolveFilter(ctx, query, scope)
+	require.NoError(t, err)
+	assert.NotSame(t, first.files, third.files)
+	assert.ElementsMatch(t, []string{"/project/api/client.go", "/project/api/retry.go", "/project/api/retry_test.go"}, third.filePaths())
+}
+
+func TestSearch_FiltersApplyToKeywordLeg(t *testing.T) {
+	svc := setupFilterTest(t)
+	ctx := context.Background()
+
+	// Tests that outrank the implementation on keywords alone
+	for i := 0; i < 20; i++ {
+		insertIndexedChunk(t, ctx, svc.db, svc.embedder, &models.Chunk{
+			FilePath: fmt.Sprintf("/project/api/retry%d_test.go", i), Language: "go",
+			Level: models.ChunkLevelMethod, StartLine: 1, EndLine: 3,
+			Name: "TestRetry", Content: "backoff backoff backoff backoff",
+		})
+	}
+
+	resp, err := svc.Search(ctx, Query{Text: "backoff", Limit: 1, Exclude: []string{"**/*_test.go"}, Languages: []string{"go"}})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "/project/api/retry.go", resp.Results[0].Chunk.FilePath)
+	assert.Equal(t, "both", resp.Results[0].MatchSource, "keyword leg should find the chunk despite the excluded matches")
+}
+
+func TestSearch_TestsExcludeAppliesToBothLegs(t *testing.T) {
+	svc := setupFilterTest(t)
+	ctx := context.Background()
+
+	// Tests that outrank the implementation on keywords alone
+	flags := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		path := fmt.Sprintf("/project/api/retry%d_test.go", i)
+		insertIndexedChunk(t, ctx, svc.db, svc.embedder, &models.Chunk{
+			FilePath: path, Language: "go",
+			Level: models.ChunkLevelMethod, StartLine: 1, EndLine: 3,
+			Name: "TestRetry", Content: "backoff backoff backoff backoff",
+		})
+		flags[path] = true
+	}
+	require.NoError(t, svc.db.SetTestFiles(ctx, flags))
+
+	resp, err := svc.Search(ctx, Query{Text: "backoff", Limit: 1, Tests: TestsExclude, Languages: []string{"go"}})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "/project/api/retry.go", resp.Results[0].Chunk.FilePath)
+	assert.Equal(t, "both", resp.Results[0].MatchSource, "keyword leg should find the chunk despite the test matches")
+}
+
+func TestSearch_InvalidTests(t *testing.T) {
+	svc := setupFilterTest(t)
+
+	_, err := svc.Search(context.Background(), Query{Text: "retry", Tests: "sometimes"})
+	assert.ErrorIs(t, err, ErrInvalidTests)
+}
+
+func TestSearch_RerankerSeesTestFlag(t *testing.T) {
+	svc := setupFilterTest(t)
+	ctx := context.Background()
+	// A test file by project convention only, unknown to the reranker's patterns
+	require.NoError(t, svc.db.SetTestFiles(ctx, map[string]bool{"/project/web/retry.ts": true}))
+	svc.options.Reranker = rerank.NewHeuristicReranker()
+	svc.options.RerankEnabled = true
+
+	resp, err := svc.Search(ctx, Query{Text: "retry", Limit: 10, Languages: []string{"ts"}})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Results, 1)
+	require.NotNil(t, resp.Results[0].ScoreDetails)
+	assert.Negative(t, resp.Results[0].ScoreDetails.SignalScores["test_penalty"])
+}
+
+func TestSearch_RerankerSeesRelativePaths(t *testing.T) {
+	svc := setupFilterTest(t)
+	signals := rerank.DefaultSignalConfig()
+	signals.PathBoosts = []rerank.PathBoost{{Pattern: "api/**", Boost: 0.3}}
+	svc.options.Reranker = rerank.NewHeuristicRerankerWithConfig(signals)
+	svc.options.RerankEnabled = true
+
+	resp, err := svc.Search(context.Background(), Query{Text: "retry", Limit: 10})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, resp.Results)
+	for _, r := range resp.Results {
+		require.NotNil(t, r.ScoreDetails, r.Chunk.FilePath)
+		boost := r.ScoreDetails.SignalScores["path_boost"]
+		if strings.HasPrefix(r.Chunk.FilePath, "/project/api/") {
+			assert.Equal(t, 0.3, boost, r.Chunk.FilePath)
+		} else {
+			assert.Zero(t, boost, r.Chunk.FilePath)
+		}
+	}
+	assert.Equal(t, "/project/api/retry.go", resp.Results[0].Chunk.FilePath)
+	assert.Contains(t, resp.Results[0].MatchReasons, "path boost")
+}
+
+func TestResolveLanguages(t *testing.T) {
+	svc := setupFilterTest(t)
+
+	assert.Equal(t, []string{"typescript", "go", "python"}, svc.resolveLanguages([]string{"ts", " Go ", "python", ""}))
+	assert.Nil(t, svc.resolveLanguages(nil))
+}
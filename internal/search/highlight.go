@@ -0,0 +1,188 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pommel-dev/pommel/internal/models"
+)
+
+const (
+	// maxHighlights caps the spans returned for one result.
+	maxHighlights = 100
+	// snippetRadius is the number of lines kept on each side of the best
+	// matching line in a result's snippet.
+	snippetRadius = 2
+)
+
+// Highlight is a span of a result's content that matched the query.
+// Columns are 1-based byte offsets; EndColumn is one past the last byte.
+type Highlight struct {
+	Line        int
+	StartColumn int
+	EndColumn   int
+	Term        string
+}
+
+// termMatcher finds the query's terms and phrases in chunk content. It
+// approximates the keyword index: matching is case-insensitive, a term may
+// start a word or an identifier's sub-word (User in getUserByEmail), and it
+// may be followed by a suffix, as stemming allows (handle in handler).
+type termMatcher struct {
+	terms   []string
+	phrases []string
+}
+
+// newTermMatcher builds a matcher for the terms of a query, taken the same
+// way as for the keyword leg of the search.
+func newTermMatcher(text string, snippet bool) *termMatcher {
+	pq := PreprocessQuery(text)
+	if snippet {
+		pq = PreprocessSnippet(text)
+	}
+	m := &termMatcher{terms: pq.Terms}
+	for _, phrase := range pq.Phrases {
+		m.phrases = append(m.phrases, asciiLower(phrase))
+	}
+	return m
+}
+
+// highlight returns the spans of chunk's content that match, and a snippet
+// of the lines around the line with the most distinct matches. Both are nil
+// if nothing matches.
+func (m *termMatcher) highlight(chunk *models.Chunk) ([]Highlight, *SourceLines) {
+	if len(m.terms) == 0 && len(m.phrases) == 0 {
+		return nil, nil
+	}
+
+	lines := strings.Split(chunk.Content, "\n")
+	var highlights []Highlight
+	bestLine, bestCount := -1, 0
+
+	for i, line := range lines {
+		spans := m.matchLine(line)
+		if len(spans) == 0 {
+			continue
+		}
+
+		distinct := make(map[string]bool)
+		for _, span := range spans {
+			span.Line = chunk.StartLine + i
+			distinct[span.Term] = true
+			if len(highlights) < maxHighlights {
+				highlights = append(highlights, span)
+			}
+		}
+		if len(distinct) > bestCount {
+			bestLine, bestCount = i, len(distinct)
+		}
+	}
+
+	if bestLine < 0 {
+		return nil, nil
+	}
+
+	snippet := lineRange(lines, bestLine+1-snippetRadius, bestLine+1+snippetRadius)
+	if snippet != nil {
+		snippet.StartLine += chunk.StartLine - 1
+		snippet.EndLine += chunk.StartLine - 1
+	}
+	return highlights, snippet
+}
+
+// matchLine returns the non-overlapping matches in one line, in order.
+// Where matches overlap the longest one starting first wins, so a phrase or
+// a whole identifier beats its sub-words.
+func (m *termMatcher) matchLine(line string) []Highlight {
+	lower := asciiLower(line)
+
+	var spans []Highlight
+	for _, phrase := range m.phrases {
+		for from := 0; ; {
+			idx := strings.Index(lower[from:], phrase)
+			if idx < 0 {
+				break
+			}
+			start := from + idx
+			spans = append(spans, Highlight{StartColumn: start + 1, EndColumn: start + len(phrase) + 1, Term: phrase})
+			from = start + len(phrase)
+		}
+	}
+	for _, term := range m.terms {
+		for from := 0; ; {
+			idx := strings.Index(lower[from:], term)
+			if idx < 0 {
+				break
+			}
+			start, end := from+idx, from+idx+len(term)
+			if startsWord(line, start) && (len(term) >= 3 || endsWord(line, end)) {
+				spans = append(spans, Highlight{StartColumn: start + 1, EndColumn: end + 1, Term: term})
+			}
+			from = start + 1
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].StartColumn != spans[j].StartColumn {
+			return spans[i].StartColumn < spans[j].StartColumn
+		}
+		return spans[i].EndColumn > spans[j].EndColumn
+	})
+	kept := spans[:0]
+	end := 0
+	for _, span := range spans {
+		if span.StartColumn < end {
+			continue
+		}
+		kept = append(kept, span)
+		end = span.EndColumn
+	}
+	return kept
+}
+
+// startsWord reports whether a match at byte i of line starts a word or an
+// identifier sub-word.
+func startsWord(line string, i int) bool {
+	if i == 0 || !isWordByte(line[i-1]) || line[i-1] == '_' {
+		return true
+	}
+	prev, cur := line[i-1], line[i]
+	if !isUpper(cur) {
+		return false
+	}
+	// getUser, md5Sum
+	if isLower(prev) || isDigit(prev) {
+		return true
+	}
+	// HTTPServer: the last capital of an acronym starts the next word
+	return isUpper(prev) && i+1 < len(line) && isLower(line[i+1])
+}
+
+// endsWord reports whether a match ending before byte i of line ends a word
+// or an identifier sub-word.
+func endsWord(line string, i int) bool {
+	return i == len(line) || !isLower(line[i]) && !isDigit(line[i])
+}
+
+// asciiLower lowercases ASCII letters only, so byte offsets into the result
+// are valid in the original.
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if isUpper(c) {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+
+func isWordByte(b byte) bool {
+	return isLower(b) || isUpper(b) || isDigit(b) || b == '_' || b >= 0x80
+}
+
+func isLower(b byte) bool { return b >= 'a' && b <= 'z' }
+func isUpper(b byte) bool { return b >= 'A' && b <= 'Z' }
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
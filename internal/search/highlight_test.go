@@ -0,0 +1,128 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spanTexts returns the text of line covered by each span.
+func spanTexts(line string, spans []Highlight) []string {
+	texts := make([]string, len(spans))
+	for i, s := range spans {
+		texts[i] = line[s.StartColumn-1 : s.EndColumn-1]
+	}
+	return texts
+}
+
+func TestTermMatcher_MatchLine(t *testing.T) {
+	tests := []struct {
+		query string
+		line  string
+		want  []string
+	}{
+		{"user email", "func getUserByEmail(email string) *User {", []string{"User", "Email", "email", "User"}},
+		{"getUserByEmail", "u := getUserByEmail(addr)", []string{"getUserByEmail"}},
+		{"parse header", "def parse_header(raw):", []string{"parse", "header"}},
+		{"server", "srv := NewHTTPServer(cfg) // observer", []string{"Server"}},
+		{"handle", "type Handler struct{}; func handles()", []string{"Handle", "handle"}},
+		{"id", "userID := ids[0]; idle()", []string{"ID"}},
+		{`"retry with backoff"`, "// Retry with backoff until done", []string{"Retry with backoff"}},
+		{"retry", "no match here", nil},
+	}
+
+	for _, tt := range tests {
+		line := tt.line
+		spans := newTermMatcher(tt.query, false).matchLine(line)
+		if tt.want == nil {
+			assert.Empty(t, spans, tt.query)
+			continue
+		}
+		assert.Equal(t, tt.want, spanTexts(line, spans), tt.query)
+	}
+}
+
+func TestTermMatcher_Highlight(t *testing.T) {
+	chunk := &models.Chunk{
+		StartLine: 20,
+		Content: "func Retry(fn func() error) error {\n" +
+			"\tvar err error\n" +
+			"\tfor attempt := 0; attempt < 3; attempt++ {\n" +
+			"\t\t// retry with exponential backoff\n" +
+			"\t\tif err = fn(); err == nil {\n" +
+			"\t\t\treturn nil\n" +
+			"\t\t}\n" +
+			"\t\ttime.Sleep(backoff(attempt))\n" +
+			"\t}\n" +
+			"\treturn err\n" +
+			"}",
+	}
+
+	highlights, snippet := newTermMatcher("retry backoff", false).highlight(chunk)
+
+	assert.Equal(t, []Highlight{
+		{Line: 20, StartColumn: 6, EndColumn: 11, Term: "retry"},
+		{Line: 23, StartColumn: 6, EndColumn: 11, Term: "retry"},
+		{Line: 23, StartColumn: 29, EndColumn: 36, Term: "backoff"},
+		{Line: 27, StartColumn: 14, EndColumn: 21, Term: "backoff"},
+	}, highlights)
+
+	// Line 23 matches both terms, so the snippet is centred on it
+	require.NotNil(t, snippet)
+	assert.Equal(t, 21, snippet.StartLine)
+	assert.Equal(t, 25, snippet.EndLine)
+	assert.Contains(t, snippet.Content, "retry with exponential backoff")
+}
+
+func TestTermMatcher_NoMatch(t *testing.T) {
+	chunk := &models.Chunk{StartLine: 1, Content: "func main() {}"}
+
+	highlights, snippet := newTermMatcher("database connection", false).highlight(chunk)
+	assert.Nil(t, highlights)
+	assert.Nil(t, snippet)
+
+	highlights, snippet = newTermMatcher("the", false).highlight(chunk)
+	assert.Nil(t, highlights, "a query of stopwords has no terms")
+	assert.Nil(t, snippet)
+}
+
+func TestTermMatcher_SnippetQuery(t *testing.T) {
+	chunk := &models.Chunk{StartLine: 5, Content: "func (s *Store) LoadSession(id string) (*Session, error) {"}
+
+	highlights, _ := newTermMatcher("sess, err := store.LoadSession(r.Context(), id)", true).highlight(chunk)
+
+	terms := make([]string, len(highlights))
+	for i, h := range highlights {
+		terms[i] = h.Term
+	}
+	assert.Equal(t, []string{"store", "loadsession", "session"}, terms)
+}
+
+func TestSearch_ResultsHaveHighlights(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+	insertIndexedChunk(t, ctx, database, mockEmb, &models.Chunk{
+		FilePath: "/project/invoice.go", Language: "go", Level: models.ChunkLevelMethod,
+		StartLine: 10, EndLine: 12, Name: "InvoiceTotal",
+		Content: "func InvoiceTotal(items []Item) int {\n\treturn sum(items)\n}",
+	})
+	svc := NewServiceWithOptions(database, mockEmb, ServiceOptions{Hybrid: DefaultHybridConfig()})
+
+	resp, err := svc.Search(ctx, Query{Text: "invoice total"})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+
+	result := resp.Results[0]
+	assert.Equal(t, []Highlight{
+		{Line: 10, StartColumn: 6, EndColumn: 13, Term: "invoice"},
+		{Line: 10, StartColumn: 13, EndColumn: 18, Term: "total"},
+	}, result.Highlights)
+	require.NotNil(t, result.Snippet)
+	assert.Equal(t, 10, result.Snippet.StartLine)
+	assert.Equal(t, 12, result.Snippet.EndLine)
+}
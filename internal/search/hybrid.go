@@ -2,10 +2,14 @@ package search
 
 import (
 	"context"
+	"math"
+	"strings"
 	"sync"
 
+	"github.com/pommel-dev/pommel/internal/chunker"
 	"github.com/pommel-dev/pommel/internal/db"
 	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
 )
 
 // HybridConfig holds configuration for hybrid search behavior.
@@ -14,6 +18,7 @@ type HybridConfig struct {
 	RRFK          int     // RRF constant k (typically 60)
 	VectorWeight  float64 // Weight for vector search (0-1)
 	KeywordWeight float64 // Weight for keyword search (0-1)
+	Fusion        string  // Fusion method: FusionRRF (default) or FusionLinear
 }
 
 // DefaultHybridConfig returns the default hybrid search configuration.
@@ -23,14 +28,25 @@ func DefaultHybridConfig() HybridConfig {
 		RRFK:          DefaultRRFK,
 		VectorWeight:  1.0,
 		KeywordWeight: 1.0,
+		Fusion:        FusionRRF,
 	}
 }
 
 // HybridOptions holds options for a single hybrid search request.
 type HybridOptions struct {
-	HybridEnabled bool // Whether to use hybrid search for this request
-	RRFK          int  // RRF constant k
-	Limit         int  // Maximum number of results to return
+	HybridEnabled bool     // Whether to use hybrid search for this request
+	RRFK          int      // RRF constant k
+	Fusion        string   // Fusion method (empty = the searcher's config)
+	VectorWeight  float64  // Weight of the vector leg (both weights 0 = the searcher's config)
+	KeywordWeight float64  // Weight of the keyword leg
+	Limit         int      // Maximum number of results to return
+	Levels        []string // Filter results to specific chunk levels
+	Languages     []string // Filter results to specific languages
+	SubprojectID  string   // Filter results to a sub-project
+	PathPrefix    string   // Filter results by file path prefix
+	TestFiles     *bool    // Restrict results to test files (true) or other files (false); nil means both
+	FilePaths     []string // Restrict results to these files; nil means any file
+	Snippet       bool     // Treat the query as a code snippet rather than natural language
 }
 
 // DefaultHybridOptions returns the default hybrid search options.
@@ -71,14 +87,19 @@ type searchResults struct {
 func (h *HybridSearcher) Search(ctx context.Context, query string, opts HybridOptions) ([]MergedResult, error) {
 	// Preprocess the query
 	processed := PreprocessQuery(query)
+	if opts.Snippet {
+		processed = PreprocessSnippet(query)
+	}
 
 	// If hybrid is disabled or no terms, fall back to vector-only search
 	if !opts.HybridEnabled || !h.config.Enabled {
-		return h.vectorOnlySearch(ctx, query, opts.Limit)
+		return h.vectorOnlySearch(ctx, query, opts)
 	}
 
-	// Run vector and keyword searches in parallel
-	results := h.parallelSearch(ctx, query, processed, opts.Limit*2) // Fetch more for better fusion
+	// Run vector and keyword searches in parallel, fetching more for better fusion
+	fetchOpts := opts
+	fetchOpts.Limit = opts.Limit * 2
+	results := h.parallelSearch(ctx, query, processed, fetchOpts)
 
 	// Handle errors
 	if results.vectorErr != nil && results.keywordErr != nil {
@@ -86,18 +107,42 @@ func (h *HybridSearcher) Search(ctx context.Context, query string, opts HybridOp
 		return nil, results.vectorErr
 	}
 
-	// Merge results using RRF
-	k := opts.RRFK
-	if k <= 0 {
-		k = DefaultRRFK
-	}
-
-	merged := RRFMerge(results.vectorResults, results.keywordResults, k, opts.Limit)
+	merged := Fuse(results.vectorResults, results.keywordResults, h.fusionOptions(opts), opts.Limit)
 	return merged, nil
 }
 
+// fusionOptions resolves how a request's result lists are fused, falling
+// back to the searcher's config for anything the request leaves unset.
+func (h *HybridSearcher) fusionOptions(opts HybridOptions) FusionOptions {
+	fusion := FusionOptions{
+		Method:        opts.Fusion,
+		K:             opts.RRFK,
+		VectorWeight:  opts.VectorWeight,
+		KeywordWeight: opts.KeywordWeight,
+	}
+	if fusion.Method == "" {
+		fusion.Method = h.config.Fusion
+	}
+	if fusion.Method == "" {
+		fusion.Method = FusionRRF
+	}
+	if fusion.K <= 0 {
+		fusion.K = h.config.RRFK
+	}
+	if fusion.K <= 0 {
+		fusion.K = DefaultRRFK
+	}
+	if fusion.VectorWeight == 0 && fusion.KeywordWeight == 0 {
+		fusion.VectorWeight, fusion.KeywordWeight = h.config.VectorWeight, h.config.KeywordWeight
+	}
+	if fusion.VectorWeight == 0 && fusion.KeywordWeight == 0 {
+		fusion.VectorWeight, fusion.KeywordWeight = 1.0, 1.0
+	}
+	return fusion
+}
+
 // parallelSearch runs vector and keyword searches concurrently.
-func (h *HybridSearcher) parallelSearch(ctx context.Context, query string, processed ProcessedQuery, limit int) searchResults {
+func (h *HybridSearcher) parallelSearch(ctx context.Context, query string, processed ProcessedQuery, opts HybridOptions) searchResults {
 	var results searchResults
 	var wg sync.WaitGroup
 
@@ -105,7 +150,7 @@ func (h *HybridSearcher) parallelSearch(ctx context.Context, query string, proce
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		vectorResults, err := h.executeVectorSearch(ctx, query, limit)
+		vectorResults, err := h.executeVectorSearch(ctx, query, opts)
 		results.vectorResults = vectorResults
 		results.vectorErr = err
 	}()
@@ -114,7 +159,7 @@ func (h *HybridSearcher) parallelSearch(ctx context.Context, query string, proce
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		keywordResults, err := h.executeKeywordSearch(ctx, processed, limit)
+		keywordResults, err := h.executeKeywordSearch(ctx, processed, opts)
 		results.keywordResults = keywordResults
 		results.keywordErr = err
 	}()
@@ -124,26 +169,35 @@ func (h *HybridSearcher) parallelSearch(ctx context.Context, query string, proce
 }
 
 // executeVectorSearch performs vector similarity search.
-func (h *HybridSearcher) executeVectorSearch(ctx context.Context, query string, limit int) ([]RankedResult, error) {
+func (h *HybridSearcher) executeVectorSearch(ctx context.Context, query string, opts HybridOptions) ([]RankedResult, error) {
 	// Generate query embedding
-	embedding, err := h.embedder.EmbedSingle(ctx, query)
+	embedding, err := h.queryEmbedding(ctx, query, opts.Snippet)
 	if err != nil {
 		return nil, err
 	}
 
 	// Perform vector search
-	chunks, err := h.db.SearchSimilar(ctx, embedding, limit)
+	chunks, err := h.db.SearchChunks(ctx, db.SearchOptions{
+		Embedding:    embedding,
+		Limit:        opts.Limit,
+		Levels:       opts.Levels,
+		Languages:    opts.Languages,
+		SubprojectID: opts.SubprojectID,
+		PathPrefix:   opts.PathPrefix,
+		TestFiles:    opts.TestFiles,
+		FilePaths:    opts.FilePaths,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert to ranked results
-	// Note: Distance is lower is better, so we convert to similarity (1 - distance)
+	// Note: Distance is lower is better, so we convert to similarity
 	results := make([]RankedResult, len(chunks))
 	for i, chunk := range chunks {
 		results[i] = RankedResult{
 			ChunkID: chunk.ChunkID,
-			Score:   float64(1.0 - chunk.Distance), // Convert distance to similarity
+			Score:   float64(DistanceToSimilarity(chunk.Distance)),
 			Rank:    i,
 		}
 	}
@@ -151,15 +205,80 @@ func (h *HybridSearcher) executeVectorSearch(ctx context.Context, query string,
 	return results, nil
 }
 
+// queryEmbedding embeds the query text. A snippet is code looking for code
+// like it, so it is embedded as a document rather than a query, and held to
+// the same token limit as indexed chunks: oversized snippets are split the
+// way a long method would be and the embeddings of the parts are averaged
+// into one query vector.
+func (h *HybridSearcher) queryEmbedding(ctx context.Context, query string, snippet bool) ([]float32, error) {
+	if !snippet {
+		return h.embedder.EmbedQuery(ctx, query)
+	}
+
+	splits := chunker.NewSplitter(chunker.DefaultMaxTokens).SplitMethod(&models.Chunk{
+		Content:   query,
+		StartLine: 1,
+		EndLine:   strings.Count(query, "\n") + 1,
+	})
+	parts := make([]string, len(splits))
+	for i, split := range splits {
+		parts[i] = split.Content
+	}
+	embeddings, err := h.embedder.EmbedDocuments(ctx, parts)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 1 {
+		return embeddings[0], nil
+	}
+	return fuseEmbeddings(embeddings), nil
+}
+
+// fuseEmbeddings averages embeddings into a single unit-length vector.
+func fuseEmbeddings(embeddings [][]float32) []float32 {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	fused := make([]float32, len(embeddings[0]))
+	for _, embedding := range embeddings {
+		for i := range fused {
+			if i < len(embedding) {
+				fused[i] += embedding[i]
+			}
+		}
+	}
+
+	var norm float64
+	for _, v := range fused {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return fused
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range fused {
+		fused[i] *= scale
+	}
+	return fused
+}
+
 // executeKeywordSearch performs FTS keyword search.
-func (h *HybridSearcher) executeKeywordSearch(ctx context.Context, processed ProcessedQuery, limit int) ([]RankedResult, error) {
+func (h *HybridSearcher) executeKeywordSearch(ctx context.Context, processed ProcessedQuery, opts HybridOptions) ([]RankedResult, error) {
 	// Skip if no useful query terms
 	if processed.FTSQuery == "" {
 		return []RankedResult{}, nil
 	}
 
 	// Perform FTS search
-	ftsResults, err := h.db.FTSSearch(ctx, processed.FTSQuery, limit)
+	ftsResults, err := h.db.FTSSearchWithFilter(ctx, processed.FTSQuery, opts.Limit, db.FTSFilter{
+		Levels:       opts.Levels,
+		Languages:    opts.Languages,
+		SubprojectID: opts.SubprojectID,
+		PathPrefix:   opts.PathPrefix,
+		TestFiles:    opts.TestFiles,
+		FilePaths:    opts.FilePaths,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -178,8 +297,8 @@ func (h *HybridSearcher) executeKeywordSearch(ctx context.Context, processed Pro
 }
 
 // vectorOnlySearch performs a vector-only search (when hybrid is disabled).
-func (h *HybridSearcher) vectorOnlySearch(ctx context.Context, query string, limit int) ([]MergedResult, error) {
-	vectorResults, err := h.executeVectorSearch(ctx, query, limit)
+func (h *HybridSearcher) vectorOnlySearch(ctx context.Context, query string, opts HybridOptions) ([]MergedResult, error) {
+	vectorResults, err := h.executeVectorSearch(ctx, query, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,15 @@
 package search
 
 import (
+	"context"
+	"math"
+	"strings"
 	"testing"
+
+	"github.com/pommel-dev/pommel/internal/chunker"
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // ============================================================================
@@ -101,3 +109,84 @@ func TestMergedResult_MatchSource(t *testing.T) {
 		})
 	}
 }
+
+// ============================================================================
+// Snippet Query Embedding Tests
+// ============================================================================
+
+// recordingEmbedder records the texts it is asked to embed.
+type recordingEmbedder struct {
+	*embedder.MockEmbedder
+	queries   []string
+	documents [][]string
+}
+
+func (r *recordingEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	r.queries = append(r.queries, query)
+	return r.MockEmbedder.EmbedQuery(ctx, query)
+}
+
+func (r *recordingEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	r.documents = append(r.documents, texts)
+	return r.MockEmbedder.EmbedDocuments(ctx, texts)
+}
+
+func TestQueryEmbedding_ShortSnippetEmbeddedOnce(t *testing.T) {
+	emb := &recordingEmbedder{MockEmbedder: embedder.NewMockEmbedder()}
+	h := NewHybridSearcher(nil, emb, DefaultHybridConfig())
+
+	snippet := "func Retry(fn func() error) error {\n\treturn fn()\n}"
+	embedding, err := h.queryEmbedding(context.Background(), snippet, true)
+	require.NoError(t, err)
+
+	assert.Len(t, embedding, emb.Dimensions())
+	assert.Equal(t, [][]string{{snippet}}, emb.documents, "a snippet is embedded as a document")
+	assert.Empty(t, emb.queries)
+}
+
+func TestQueryEmbedding_LongSnippetSplitAndFused(t *testing.T) {
+	emb := &recordingEmbedder{MockEmbedder: embedder.NewMockEmbedder()}
+	h := NewHybridSearcher(nil, emb, DefaultHybridConfig())
+
+	line := "\tresult = append(result, transform(items[i], options, cache))"
+	lines := make([]string, 0, 2000)
+	for len(lines) < 2000 {
+		lines = append(lines, line)
+	}
+	snippet := strings.Join(lines, "\n")
+	require.Greater(t, embedder.EstimateTokens(snippet), chunker.DefaultMaxTokens)
+
+	embedding, err := h.queryEmbedding(context.Background(), snippet, true)
+	require.NoError(t, err)
+
+	assert.Empty(t, emb.queries)
+	require.Len(t, emb.documents, 1)
+	assert.Greater(t, len(emb.documents[0]), 1, "oversized snippet should be split")
+	for _, part := range emb.documents[0] {
+		assert.Less(t, len(part), len(snippet))
+	}
+	assert.Len(t, embedding, emb.Dimensions())
+}
+
+func TestQueryEmbedding_NaturalLanguageNotSplit(t *testing.T) {
+	emb := &recordingEmbedder{MockEmbedder: embedder.NewMockEmbedder()}
+	h := NewHybridSearcher(nil, emb, DefaultHybridConfig())
+
+	query := strings.Repeat("retry the request ", 3000)
+	_, err := h.queryEmbedding(context.Background(), query, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{query}, emb.queries)
+	assert.Empty(t, emb.documents)
+}
+
+func TestFuseEmbeddings(t *testing.T) {
+	fused := fuseEmbeddings([][]float32{{1, 0}, {0, 1}})
+
+	require.Len(t, fused, 2)
+	assert.InDelta(t, 1/math.Sqrt2, fused[0], 1e-6)
+	assert.InDelta(t, 1/math.Sqrt2, fused[1], 1e-6)
+
+	assert.Nil(t, fuseEmbeddings(nil))
+	assert.Equal(t, []float32{0, 0}, fuseEmbeddings([][]float32{{0, 0}}))
+}
@@ -0,0 +1,192 @@
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCachedSnapshots is the number of ranked result lists kept in memory.
+	maxCachedSnapshots = 32
+	// snapshotTTL is how long a ranked result list is kept in memory.
+	snapshotTTL = 10 * time.Minute
+)
+
+var (
+	// ErrInvalidCursor is returned when a search cursor can't be decoded.
+	ErrInvalidCursor = errors.New("invalid search cursor")
+	// ErrCursorExpired is returned when a search cursor's ranked list can no
+	// longer be paged through: the index has changed or the list is no longer
+	// cached.
+	ErrCursorExpired = errors.New("search cursor expired")
+)
+
+// cursor is the decoded form of Response.NextCursor. It identifies the
+// snapshot to page through. A cursor is never served from a new ranking,
+// which could order the results differently, so it expires with its snapshot.
+// PageSize is the Limit the search started with, which the snapshot's pages
+// are arranged by even if a later page overrides Limit.
+type cursor struct {
+	Query      Query `json:"q"`
+	Offset     int   `json:"o"`
+	PageSize   int   `json:"p,omitempty"`
+	Generation int64 `json:"g"`
+}
+
+// encodeCursor encodes a cursor as an opaque URL-safe string.
+func encodeCursor(c cursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor decodes a cursor produced by encodeCursor.
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.Offset < 0 || c.Query.Text == "" {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// snapshot is a fused, deduplicated and re-ranked result list that pages are
+// sliced from, so that every page of a search comes from the same ranking.
+// It holds only as many results as the pages requested so far needed.
+type snapshot struct {
+	// results are placed on pages, in page order; pool holds the ranked
+	// results not placed yet, in ranked order.
+	results    []Result
+	pool       []Result
+	scope      *ResolvedScope
+	confidence string
+	// complete is set when no results rank below the ones held.
+	complete  bool
+	createdAt time.Time
+}
+
+// exhausted reports whether every result of the ranking has been placed.
+func (s *snapshot) exhausted() bool {
+	return s.complete && len(s.pool) == 0
+}
+
+// with returns a copy of the snapshot holding the given results and pool.
+func (s *snapshot) with(results, pool []Result) *snapshot {
+	return &snapshot{
+		results:    results,
+		pool:       pool,
+		scope:      s.scope,
+		confidence: s.confidence,
+		complete:   s.complete,
+		createdAt:  time.Now(),
+	}
+}
+
+// extend returns the snapshot with the results of a deeper ranking added to
+// its pool. Placed results keep their positions, since pages may have been
+// handed out from them, and results already held are skipped.
+func (s *snapshot) extend(deeper *snapshot) *snapshot {
+	pool := make([]Result, len(s.pool), len(s.pool)+len(deeper.pool))
+	copy(pool, s.pool)
+	seen := make(map[string]bool, len(s.results)+len(s.pool))
+	for _, r := range s.results {
+		seen[resultKey(r)] = true
+	}
+	for _, r := range s.pool {
+		seen[resultKey(r)] = true
+	}
+	for _, r := range deeper.pool {
+		if !seen[resultKey(r)] {
+			pool = append(pool, r)
+		}
+	}
+
+	extended := s.with(s.results, pool)
+	extended.complete = deeper.complete
+	return extended
+}
+
+// resultKey identifies a result across rankings. The splits of a chunk are
+// deduplicated into one result, but not always the same split.
+func resultKey(r Result) string {
+	if r.Chunk.ParentChunkID != "" {
+		return r.Chunk.ParentChunkID
+	}
+	return r.Chunk.ID
+}
+
+// snapshotKey identifies the ranked list a query produces at an index
+// generation. Limit, Page and Cursor only select a page of it, and the
+// context options only add to the results on that page. With diversity or a
+// per-file cap, results are arranged in pages of pageSize, so it is part of
+// the key.
+func snapshotKey(query Query, pageSize int, generation int64) (string, error) {
+	query.Limit, query.Page, query.Cursor = 0, 0, ""
+	query.ContextLines, query.IncludeParent, query.IncludeImports = 0, false, false
+	if query.Diversity <= 0 && query.MaxPerFile <= 0 {
+		pageSize = 0
+	}
+	data, err := json.Marshal(struct {
+		Query      Query
+		PageSize   int
+		Generation int64
+	}{query, pageSize, generation})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// snapshotCache keeps recent snapshots in memory, evicting the oldest first.
+type snapshotCache struct {
+	mu      sync.Mutex
+	entries map[string]*snapshot
+	order   []string
+}
+
+func newSnapshotCache() *snapshotCache {
+	return &snapshotCache{entries: make(map[string]*snapshot)}
+}
+
+// get returns the snapshot for key, or nil if it is missing or too old.
+func (c *snapshotCache) get(key string) *snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snap, ok := c.entries[key]
+	if !ok || time.Since(snap.createdAt) > snapshotTTL {
+		return nil
+	}
+	return snap
+}
+
+// put stores a snapshot, evicting the oldest one if the cache is full.
+func (c *snapshotCache) put(key string, snap *snapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = snap
+
+	for len(c.order) > maxCachedSnapshots {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
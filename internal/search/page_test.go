@@ -0,0 +1,224 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPageTest indexes n handlers in separate files.
+func setupPageTest(t *testing.T, n int) *Service {
+	t.Helper()
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for i := 0; i < n; i++ {
+		insertIndexedChunk(t, ctx, database, mockEmb, &models.Chunk{
+			FilePath: fmt.Sprintf("/project/handlers/h%02d.go", i), Language: "go",
+			Level: models.ChunkLevelMethod, StartLine: 1, EndLine: 3,
+			Name: fmt.Sprintf("Handle%d", i), Content: fmt.Sprintf("func Handle%d() { handle request %d }", i, i),
+		})
+	}
+
+	return NewServiceWithOptions(database, mockEmb, ServiceOptions{Hybrid: DefaultHybridConfig(), RerankEnabled: true})
+}
+
+func resultIDs(results []Result) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Chunk.ID
+	}
+	return ids
+}
+
+func TestSearch_CursorPagesThroughResults(t *testing.T) {
+	svc := setupPageTest(t, 7)
+	ctx := context.Background()
+
+	all, err := svc.Search(ctx, Query{Text: "handle request", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all.Results, 7)
+	assert.Empty(t, all.NextCursor, "last page should have no cursor")
+
+	var paged []string
+	resp, err := svc.Search(ctx, Query{Text: "handle request", Limit: 3})
+	require.NoError(t, err)
+	pages := 1
+	for {
+		paged = append(paged, resultIDs(resp.Results)...)
+		if resp.NextCursor == "" {
+			break
+		}
+		resp, err = svc.Search(ctx, Query{Cursor: resp.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, "handle request", resp.Query)
+		pages++
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, resultIDs(all.Results), paged, "pages should slice the same ranking")
+}
+
+func TestSearch_PageMatchesCursor(t *testing.T) {
+	svc := setupPageTest(t, 7)
+	ctx := context.Background()
+
+	first, err := svc.Search(ctx, Query{Text: "handle request", Limit: 3})
+	require.NoError(t, err)
+	require.NotEmpty(t, first.NextCursor)
+
+	byCursor, err := svc.Search(ctx, Query{Cursor: first.NextCursor})
+	require.NoError(t, err)
+	byPage, err := svc.Search(ctx, Query{Text: "handle request", Limit: 3, Page: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, resultIDs(byCursor.Results), resultIDs(byPage.Results))
+	assert.Equal(t, byCursor.NextCursor, byPage.NextCursor)
+
+	beyond, err := svc.Search(ctx, Query{Text: "handle request", Limit: 3, Page: 10})
+	require.NoError(t, err)
+	assert.Empty(t, beyond.Results)
+	assert.Empty(t, beyond.NextCursor)
+}
+
+func TestSearch_CursorKeepsFilters(t *testing.T) {
+	svc := setupPageTest(t, 4)
+	ctx := context.Background()
+	insertIndexedChunk(t, ctx, svc.db, svc.embedder, &models.Chunk{
+		FilePath: "/project/web/handle.ts", Language: "typescript",
+		Level: models.ChunkLevelMethod, StartLine: 1, EndLine: 3,
+		Name: "handle", Content: "function handle() { handle request }",
+	})
+
+	resp, err := svc.Search(ctx, Query{Text: "handle request", Limit: 1, Languages: []string{"go"}})
+	require.NoError(t, err)
+	seen := resultPaths(resp.Results)
+	for resp.NextCursor != "" {
+		resp, err = svc.Search(ctx, Query{Cursor: resp.NextCursor})
+		require.NoError(t, err)
+		seen = append(seen, resultPaths(resp.Results)...)
+	}
+
+	assert.Len(t, seen, 4)
+	assert.NotContains(t, seen, "/project/web/handle.ts")
+}
+
+func TestSearch_CursorLimitOverride(t *testing.T) {
+	svc := setupPageTest(t, 7)
+	ctx := context.Background()
+
+	first, err := svc.Search(ctx, Query{Text: "handle request", Limit: 2})
+	require.NoError(t, err)
+
+	rest, err := svc.Search(ctx, Query{Cursor: first.NextCursor, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, rest.Results, 5)
+	assert.Empty(t, rest.NextCursor)
+}
+
+func TestSearch_CursorExpiresWhenIndexChanges(t *testing.T) {
+	svc := setupPageTest(t, 5)
+	ctx := context.Background()
+
+	first, err := svc.Search(ctx, Query{Text: "handle request", Limit: 2})
+	require.NoError(t, err)
+	require.NotEmpty(t, first.NextCursor)
+
+	require.NoError(t, svc.db.DeleteFileByPath(ctx, "/project/handlers/h00.go"))
+
+	_, err = svc.Search(ctx, Query{Cursor: first.NextCursor})
+	assert.ErrorIs(t, err, ErrCursorExpired)
+}
+
+func TestSearch_CursorExpiresWithSnapshot(t *testing.T) {
+	svc := setupPageTest(t, 5)
+	ctx := context.Background()
+
+	first, err := svc.Search(ctx, Query{Text: "handle request", Limit: 2})
+	require.NoError(t, err)
+	require.NotEmpty(t, first.NextCursor)
+
+	// A restart, or eviction, loses the ranking the cursor pages through
+	svc.snapshots = newSnapshotCache()
+
+	_, err = svc.Search(ctx, Query{Cursor: first.NextCursor})
+	assert.ErrorIs(t, err, ErrCursorExpired)
+
+	// Page numbers don't promise a ranking, so they rank again
+	resp, err := svc.Search(ctx, Query{Text: "handle request", Limit: 2, Page: 2})
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 2)
+}
+
+func TestSearch_RanksOnlyRequestedPages(t *testing.T) {
+	svc := setupPageTest(t, 9)
+	ctx := context.Background()
+
+	cached := func() *snapshot {
+		t.Helper()
+		require.Len(t, svc.snapshots.entries, 1)
+		for _, snap := range svc.snapshots.entries {
+			return snap
+		}
+		return nil
+	}
+
+	first, err := svc.Search(ctx, Query{Text: "handle request", Limit: 2})
+	require.NoError(t, err)
+	snap := cached()
+	assert.Len(t, snap.results, 3, "the first page ranks one result past itself")
+	for _, r := range snap.results {
+		assert.Nil(t, r.Highlights, "only returned results are highlighted")
+	}
+	assert.NotEmpty(t, first.Results[0].MatchReasons)
+
+	second, err := svc.Search(ctx, Query{Cursor: first.NextCursor})
+	require.NoError(t, err)
+	snap = cached()
+	assert.Len(t, snap.results, 5)
+	assert.Equal(t, resultIDs(first.Results), resultIDs(snap.results[:2]), "a deeper ranking keeps the served order")
+	assert.Equal(t, resultIDs(second.Results), resultIDs(snap.results[2:4]))
+}
+
+func TestSnapshot_ExtendKeepsServedResults(t *testing.T) {
+	parent := "split-parent"
+	chunk := func(id string) Result { return Result{Chunk: &models.Chunk{ID: id}} }
+	split := func(id string) Result { return Result{Chunk: &models.Chunk{ID: id, ParentChunkID: parent}} }
+
+	served := &snapshot{results: []Result{chunk("a"), split("s1")}, pool: []Result{chunk("d")}, confidence: ConfidenceHigh}
+	deeper := &snapshot{pool: []Result{chunk("b"), chunk("a"), split("s2"), chunk("d"), chunk("c")}, complete: true}
+
+	extended := served.extend(deeper)
+	assert.Equal(t, []string{"a", "s1"}, resultIDs(extended.results))
+	assert.Equal(t, []string{"d", "b", "c"}, resultIDs(extended.pool))
+	assert.Equal(t, ConfidenceHigh, extended.confidence)
+	assert.True(t, extended.complete)
+}
+
+func TestSearch_InvalidCursor(t *testing.T) {
+	svc := setupPageTest(t, 1)
+	ctx := context.Background()
+
+	for _, c := range []string{"not a cursor", "e30"} {
+		_, err := svc.Search(ctx, Query{Cursor: c})
+		assert.ErrorIs(t, err, ErrInvalidCursor, c)
+	}
+}
+
+func TestSnapshotCache_EvictsOldest(t *testing.T) {
+	cache := newSnapshotCache()
+	for i := 0; i <= maxCachedSnapshots; i++ {
+		cache.put(fmt.Sprint(i), &snapshot{createdAt: time.Now()})
+	}
+
+	assert.Nil(t, cache.get("0"))
+	assert.NotNil(t, cache.get("1"))
+	assert.NotNil(t, cache.get(fmt.Sprint(maxCachedSnapshots)))
+}
@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+
+	"github.com/pommel-dev/pommel/internal/tokenize"
 )
 
 // ProcessedQuery contains the preprocessed components of a search query.
@@ -83,6 +85,20 @@ func PreprocessQuery(query string) ProcessedQuery {
 		result.Terms = append(result.Terms, normalized)
 	}
 
+	// Add the sub-words of identifiers such as getUserByEmail, as they are
+	// indexed for the chunk's identifiers
+	for _, word := range words {
+		for _, sub := range identifierSubWords(word) {
+			if stopwords[sub] || containsString(result.Terms, sub) {
+				continue
+			}
+			if len(sub) == 1 && !unicode.IsDigit(rune(sub[0])) {
+				continue
+			}
+			result.Terms = append(result.Terms, sub)
+		}
+	}
+
 	// Build FTS query
 	result.FTSQuery = buildFTSQuery(result.Terms, result.Phrases)
 
@@ -108,3 +124,79 @@ func buildFTSQuery(terms []string, phrases []string) string {
 	// Join with OR for broad matching
 	return strings.Join(parts, " OR ")
 }
+
+// maxSnippetIdentifiers caps the keyword terms taken from a code snippet so a
+// large paste doesn't turn into an unbounded OR query.
+const maxSnippetIdentifiers = 64
+
+// identifierRegex matches identifiers as they appear in most languages
+var identifierRegex = regexp.MustCompile(`[\p{L}_][\p{L}\p{N}_]*`)
+
+// snippetKeywords are common language keywords and literals that carry no
+// meaning as search terms.
+var snippetKeywords = map[string]bool{
+	"async": true, "await": true, "break": true, "case": true, "catch": true,
+	"class": true, "const": true, "continue": true, "def": true, "default": true,
+	"defer": true, "elif": true, "else": true, "enum": true, "err": true,
+	"except": true, "export": true, "extends": true, "false": true, "final": true,
+	"finally": true, "from": true, "func": true, "function": true, "impl": true,
+	"import": true, "interface": true, "let": true, "new": true, "nil": true,
+	"none": true, "null": true, "package": true, "pass": true, "private": true,
+	"protected": true, "pub": true, "public": true, "raise": true, "range": true,
+	"return": true, "self": true, "static": true, "struct": true, "super": true,
+	"switch": true, "throw": true, "throws": true, "true": true, "try": true,
+	"type": true, "undefined": true, "use": true, "var": true, "void": true,
+	"while": true, "yield": true,
+}
+
+// PreprocessSnippet extracts identifiers from a code snippet, such as a block
+// of code or a stack trace, for the keyword leg of a search. Unlike
+// PreprocessQuery it keeps no phrases and drops language keywords rather than
+// English stopwords.
+func PreprocessSnippet(snippet string) ProcessedQuery {
+	result := ProcessedQuery{
+		Original: snippet,
+		Terms:    []string{},
+		Phrases:  []string{},
+	}
+
+	seen := make(map[string]bool)
+	addTerm := func(term string) bool {
+		if len(term) < 3 || seen[term] || snippetKeywords[term] || stopwords[term] {
+			return false
+		}
+		// Skip identifiers made only of underscores
+		if strings.Trim(term, "_") == "" {
+			return false
+		}
+		seen[term] = true
+		result.Terms = append(result.Terms, term)
+		return len(result.Terms) == maxSnippetIdentifiers
+	}
+
+snippet:
+	for _, ident := range identifierRegex.FindAllString(snippet, -1) {
+		if addTerm(strings.ToLower(ident)) {
+			break
+		}
+		for _, sub := range identifierSubWords(ident) {
+			if addTerm(sub) {
+				break snippet
+			}
+		}
+	}
+
+	result.FTSQuery = buildFTSQuery(result.Terms, nil)
+
+	return result
+}
+
+// identifierSubWords returns the sub-words of a compound identifier such as
+// getUserByEmail or parse_header, or nil if it is a single word.
+func identifierSubWords(word string) []string {
+	parts := tokenize.SplitIdentifier(word)
+	if len(parts) < 2 {
+		return nil
+	}
+	return parts
+}
@@ -1,7 +1,11 @@
 package search
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // ============================================================================
@@ -200,3 +204,67 @@ func TestPreprocessQuery_UnicodeTerms(t *testing.T) {
 		t.Errorf("Expected 2 unicode terms, got %d: %v", len(result.Terms), result.Terms)
 	}
 }
+
+func TestPreprocessQuery_SplitsIdentifiers(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"getUserByEmail", []string{"getuserbyemail", "get", "user", "email"}},
+		{"parse_http_header", []string{"parse_http_header", "parse", "http", "header"}},
+		{"HTTPServer config", []string{"httpserver", "config", "http", "server"}},
+		{"user getUser", []string{"user", "getuser", "get"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, PreprocessQuery(tt.query).Terms, tt.query)
+	}
+}
+
+func TestPreprocessSnippet_ExtractsIdentifiers(t *testing.T) {
+	snippet := `func (s *Store) Load(ctx context.Context) error {
+	if err := s.db.QueryRow(ctx, loadSQL); err != nil {
+		return fmt.Errorf("load failed: %w", err)
+	}
+	return nil
+}`
+
+	result := PreprocessSnippet(snippet)
+
+	assert.Equal(t, []string{"store", "load", "ctx", "context", "error", "queryrow", "query", "row", "loadsql", "sql", "fmt", "errorf", "failed"}, result.Terms)
+	assert.Empty(t, result.Phrases)
+	assert.Equal(t, "store OR load OR ctx OR context OR error OR queryrow OR query OR row OR loadsql OR sql OR fmt OR errorf OR failed", result.FTSQuery)
+}
+
+func TestPreprocessSnippet_StackTrace(t *testing.T) {
+	trace := `panic: runtime error: index out of range [3] with length 3
+
+goroutine 1 [running]:
+main.parseHeader(...)
+	/src/app/header.go:42 +0x1d`
+
+	result := PreprocessSnippet(trace)
+
+	assert.Contains(t, result.Terms, "parseheader")
+	assert.Contains(t, result.Terms, "header")
+	assert.Contains(t, result.Terms, "goroutine")
+	assert.NotContains(t, result.Terms, "with", "English stopwords should be dropped")
+	assert.NotContains(t, result.Terms, "0x1d", "identifiers can't start with a digit")
+}
+
+func TestPreprocessSnippet_CapsTerms(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < maxSnippetIdentifiers*2; i++ {
+		fmt.Fprintf(&sb, "ident%d := value%d\n", i, i)
+	}
+
+	result := PreprocessSnippet(sb.String())
+
+	assert.Len(t, result.Terms, maxSnippetIdentifiers)
+}
+
+func TestPreprocessSnippet_NoIdentifiers(t *testing.T) {
+	result := PreprocessSnippet("{ } 1 + 2; _")
+
+	assert.Empty(t, result.Terms)
+	assert.Empty(t, result.FTSQuery)
+}
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"errors"
 	"sort"
 )
 
@@ -14,14 +15,19 @@ type RankedResult struct {
 	Rank    int     // 0-indexed rank in the result list
 }
 
-// MergedResult represents a result after RRF fusion of multiple ranked lists.
+// MergedResult represents a result after fusion of multiple ranked lists.
 type MergedResult struct {
 	ChunkID      string  // Unique identifier for the chunk
-	RRFScore     float64 // Combined RRF score
+	RRFScore     float64 // Combined fusion score (RRF or linear, see FusionOptions)
 	VectorScore  float64 // Original vector similarity score (0 if not in vector results)
 	KeywordScore float64 // Original keyword/FTS score (0 if not in keyword results)
 	VectorRank   int     // Rank in vector results (-1 if not present)
 	KeywordRank  int     // Rank in keyword results (-1 if not present)
+
+	// VectorContribution and KeywordContribution are each leg's share of
+	// RRFScore, after weighting.
+	VectorContribution  float64
+	KeywordContribution float64
 }
 
 // MatchSource returns a string indicating which search sources matched this result.
@@ -41,45 +47,89 @@ func (m MergedResult) MatchSource() string {
 	return "none"
 }
 
+// ErrInvalidFusion is returned when a query's fusion options are invalid.
+var ErrInvalidFusion = errors.New("invalid fusion options")
+
+// Fusion methods for combining the vector and keyword result lists.
+const (
+	// FusionRRF scores a result by its rank in each list: w / (k + rank).
+	FusionRRF = "rrf"
+	// FusionLinear scores a result by its normalized score in each list:
+	// w * score / max score.
+	FusionLinear = "linear"
+)
+
+// FusionOptions controls how the vector and keyword result lists are combined.
+type FusionOptions struct {
+	Method        string  // FusionRRF (default) or FusionLinear
+	K             int     // RRF constant k
+	VectorWeight  float64 // Weight of the vector list
+	KeywordWeight float64 // Weight of the keyword list
+}
+
+// MaxScore returns the highest fused score possible: a result ranked first
+// (RRF) or scoring the maximum (linear) in both lists.
+func (o FusionOptions) MaxScore() float64 {
+	if o.Method == FusionLinear {
+		return o.VectorWeight + o.KeywordWeight
+	}
+	return (o.VectorWeight + o.KeywordWeight) / float64(o.K+1)
+}
+
 // RRFMerge combines two ranked result lists using Reciprocal Rank Fusion.
 // The formula is: RRF(d) = sum(1 / (k + rank(d)))
 // where k is a constant (typically 60) and rank is 0-indexed.
 func RRFMerge(vectorResults, keywordResults []RankedResult, k int, limit int) []MergedResult {
+	return Fuse(vectorResults, keywordResults, FusionOptions{
+		Method:        FusionRRF,
+		K:             k,
+		VectorWeight:  1.0,
+		KeywordWeight: 1.0,
+	}, limit)
+}
+
+// Fuse combines two ranked result lists into one. With FusionRRF each list
+// contributes weight / (k + rank(d)); with FusionLinear each contributes
+// weight * score(d) / max score in that list, so BM25 scores are comparable
+// with vector similarities.
+func Fuse(vectorResults, keywordResults []RankedResult, opts FusionOptions, limit int) []MergedResult {
 	if limit <= 0 {
 		return []MergedResult{}
 	}
 
 	// Map to accumulate scores by chunk ID
 	scoreMap := make(map[string]*MergedResult)
-
-	// Process vector results
-	for _, r := range vectorResults {
-		if _, exists := scoreMap[r.ChunkID]; !exists {
-			scoreMap[r.ChunkID] = &MergedResult{
-				ChunkID:     r.ChunkID,
+	merged := func(chunkID string) *MergedResult {
+		m, exists := scoreMap[chunkID]
+		if !exists {
+			m = &MergedResult{
+				ChunkID:     chunkID,
 				VectorRank:  -1,
 				KeywordRank: -1,
 			}
+			scoreMap[chunkID] = m
 		}
-		m := scoreMap[r.ChunkID]
+		return m
+	}
+
+	vectorMax, keywordMax := maxScore(vectorResults), maxScore(keywordResults)
+
+	// Process vector results
+	for _, r := range vectorResults {
+		m := merged(r.ChunkID)
 		m.VectorScore = r.Score
 		m.VectorRank = r.Rank
-		m.RRFScore += 1.0 / float64(k+r.Rank+1)
+		m.VectorContribution = contribution(r, opts, opts.VectorWeight, vectorMax)
+		m.RRFScore += m.VectorContribution
 	}
 
 	// Process keyword results
 	for _, r := range keywordResults {
-		if _, exists := scoreMap[r.ChunkID]; !exists {
-			scoreMap[r.ChunkID] = &MergedResult{
-				ChunkID:     r.ChunkID,
-				VectorRank:  -1,
-				KeywordRank: -1,
-			}
-		}
-		m := scoreMap[r.ChunkID]
+		m := merged(r.ChunkID)
 		m.KeywordScore = r.Score
 		m.KeywordRank = r.Rank
-		m.RRFScore += 1.0 / float64(k+r.Rank+1)
+		m.KeywordContribution = contribution(r, opts, opts.KeywordWeight, keywordMax)
+		m.RRFScore += m.KeywordContribution
 	}
 
 	// Convert map to slice
@@ -88,7 +138,7 @@ func RRFMerge(vectorResults, keywordResults []RankedResult, k int, limit int) []
 		results = append(results, *m)
 	}
 
-	// Sort by RRF score descending, then by ChunkID for stable ordering
+	// Sort by fused score descending, then by ChunkID for stable ordering
 	sort.Slice(results, func(i, j int) bool {
 		if results[i].RRFScore != results[j].RRFScore {
 			return results[i].RRFScore > results[j].RRFScore
@@ -104,3 +154,25 @@ func RRFMerge(vectorResults, keywordResults []RankedResult, k int, limit int) []
 
 	return results
 }
+
+// contribution returns a ranked result's weighted share of the fused score.
+func contribution(r RankedResult, opts FusionOptions, weight, max float64) float64 {
+	if opts.Method != FusionLinear {
+		return weight / float64(opts.K+r.Rank+1)
+	}
+	if max <= 0 {
+		return 0
+	}
+	return weight * r.Score / max
+}
+
+// maxScore returns the highest score in a result list.
+func maxScore(results []RankedResult) float64 {
+	var max float64
+	for _, r := range results {
+		if r.Score > max {
+			max = r.Score
+		}
+	}
+	return max
+}
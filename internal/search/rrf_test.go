@@ -299,3 +299,58 @@ func TestRRFMerge_StableOrdering(t *testing.T) {
 		}
 	}
 }
+
+func TestFuse_WeightedRRF(t *testing.T) {
+	vectorResults := []RankedResult{{ChunkID: "vector-top", Score: 0.9, Rank: 0}}
+	keywordResults := []RankedResult{{ChunkID: "keyword-top", Score: 12.0, Rank: 0}}
+
+	results := Fuse(vectorResults, keywordResults, FusionOptions{Method: FusionRRF, K: 60, VectorWeight: 0.7, KeywordWeight: 0.3}, 10)
+
+	if len(results) != 2 || results[0].ChunkID != "vector-top" {
+		t.Fatalf("Expected the heavier vector leg to win, got %+v", results)
+	}
+	if math.Abs(results[0].VectorContribution-0.7/61.0) > 1e-9 {
+		t.Errorf("Expected vector contribution 0.7/61, got %f", results[0].VectorContribution)
+	}
+	if math.Abs(results[1].KeywordContribution-0.3/61.0) > 1e-9 {
+		t.Errorf("Expected keyword contribution 0.3/61, got %f", results[1].KeywordContribution)
+	}
+	if results[0].KeywordContribution != 0 {
+		t.Errorf("Expected no keyword contribution for a vector-only result, got %f", results[0].KeywordContribution)
+	}
+}
+
+func TestFuse_Linear(t *testing.T) {
+	vectorResults := []RankedResult{
+		{ChunkID: "chunk-1", Score: 0.8, Rank: 0},
+		{ChunkID: "chunk-2", Score: 0.4, Rank: 1},
+	}
+	keywordResults := []RankedResult{
+		{ChunkID: "chunk-2", Score: 20.0, Rank: 0},
+		{ChunkID: "chunk-1", Score: 5.0, Rank: 1},
+	}
+
+	results := Fuse(vectorResults, keywordResults, FusionOptions{Method: FusionLinear, VectorWeight: 1.0, KeywordWeight: 1.0}, 10)
+
+	// chunk-1: 0.8/0.8 + 5/20 = 1.25; chunk-2: 0.4/0.8 + 20/20 = 1.5
+	if results[0].ChunkID != "chunk-2" {
+		t.Fatalf("Expected chunk-2 first, got %s", results[0].ChunkID)
+	}
+	if math.Abs(results[0].RRFScore-1.5) > 1e-9 || math.Abs(results[1].RRFScore-1.25) > 1e-9 {
+		t.Errorf("Expected scores 1.5 and 1.25, got %f and %f", results[0].RRFScore, results[1].RRFScore)
+	}
+	if results[0].VectorContribution+results[0].KeywordContribution != results[0].RRFScore {
+		t.Error("Expected contributions to add up to the fused score")
+	}
+}
+
+func TestFusionOptions_MaxScore(t *testing.T) {
+	rrf := FusionOptions{Method: FusionRRF, K: 60, VectorWeight: 0.7, KeywordWeight: 0.3}
+	if math.Abs(rrf.MaxScore()-1.0/61.0) > 1e-9 {
+		t.Errorf("Expected RRF max score 1/61, got %f", rrf.MaxScore())
+	}
+	linear := FusionOptions{Method: FusionLinear, VectorWeight: 0.7, KeywordWeight: 0.3}
+	if math.Abs(linear.MaxScore()-1.0) > 1e-9 {
+		t.Errorf("Expected linear max score 1, got %f", linear.MaxScore())
+	}
+}
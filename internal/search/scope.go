@@ -0,0 +1,147 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Scope modes accepted in Scope.Mode.
+const (
+	ScopeAll        = "all"        // Search the whole index
+	ScopePath       = "path"       // Search files under a path prefix
+	ScopeSubproject = "subproject" // Search a single sub-project by ID
+	ScopeAuto       = "auto"       // Search the sub-project containing the caller's working directory
+)
+
+// ErrInvalidScope is returned when a search scope can't be resolved.
+var ErrInvalidScope = errors.New("invalid search scope")
+
+// Scope restricts a search to part of the index.
+type Scope struct {
+	// Mode is one of ScopeAll, ScopePath, ScopeSubproject or ScopeAuto.
+	// Empty means ScopePath if the query has a PathPrefix, otherwise ScopeAll.
+	Mode string
+	// Value is the path prefix, sub-project ID or working directory, depending on Mode.
+	Value string
+}
+
+// ResolvedScope describes the part of the index a search actually covered.
+type ResolvedScope struct {
+	// Mode is the effective mode. Auto scopes resolve to ScopeSubproject or ScopeAll.
+	Mode string
+	// Subproject is the ID of the searched sub-project, if any. Chunks are
+	// matched against the sub-project they were tagged with when indexed.
+	Subproject string
+	// Path is the searched path relative to the project root, if any.
+	Path string
+	// PathPrefix is the prefix matched against indexed file paths, for
+	// path scopes.
+	PathPrefix string
+}
+
+// ResolveScope resolves a scope against the sub-projects stored in the index.
+// pathPrefix is the query's legacy PathPrefix, used when the scope doesn't
+// carry a path of its own.
+func (s *Service) ResolveScope(ctx context.Context, scope Scope, pathPrefix string) (*ResolvedScope, error) {
+	mode := scope.Mode
+	if mode == "" {
+		mode = ScopeAll
+		if pathPrefix != "" {
+			mode = ScopePath
+		}
+	}
+
+	switch mode {
+	case ScopeAll:
+		return &ResolvedScope{Mode: ScopeAll}, nil
+
+	case ScopePath:
+		path := scope.Value
+		if path == "" {
+			path = pathPrefix
+		}
+		if path == "" {
+			return nil, fmt.Errorf("%w: path scope requires a path", ErrInvalidScope)
+		}
+		return &ResolvedScope{
+			Mode:       ScopePath,
+			Path:       path,
+			PathPrefix: s.indexedPathPrefix(path),
+		}, nil
+
+	case ScopeSubproject:
+		if scope.Value == "" {
+			return nil, fmt.Errorf("%w: subproject scope requires a sub-project ID", ErrInvalidScope)
+		}
+		sp, err := s.db.GetSubproject(ctx, scope.Value)
+		if err != nil {
+			return nil, err
+		}
+		if sp == nil {
+			return nil, fmt.Errorf("%w: unknown sub-project %q", ErrInvalidScope, scope.Value)
+		}
+		return &ResolvedScope{Mode: ScopeSubproject, Subproject: sp.ID, Path: sp.Path}, nil
+
+	case ScopeAuto:
+		relDir, ok := s.projectRelative(scope.Value)
+		if !ok {
+			return &ResolvedScope{Mode: ScopeAll}, nil
+		}
+		sp, err := s.db.GetSubprojectByPath(ctx, relDir)
+		if err != nil {
+			return nil, err
+		}
+		if sp == nil {
+			return &ResolvedScope{Mode: ScopeAll}, nil
+		}
+		return &ResolvedScope{Mode: ScopeSubproject, Subproject: sp.ID, Path: sp.Path}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidScope, scope.Mode)
+	}
+}
+
+// indexedPathPrefix converts a project-relative path prefix to the form file
+// paths are stored in the index. Trailing separators are kept so that a
+// directory prefix doesn't match sibling directories sharing its name.
+func (s *Service) indexedPathPrefix(path string) string {
+	root := s.options.ProjectRoot
+	if root == "" || filepath.IsAbs(path) {
+		return path
+	}
+	prefix := filepath.Join(root, path)
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return prefix
+}
+
+// projectRelative returns dir relative to the project root. It reports false
+// for an empty dir or one outside the project.
+func (s *Service) projectRelative(dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	root := s.options.ProjectRoot
+	if root == "" || !filepath.IsAbs(dir) {
+		return filepath.Clean(dir), true
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		// The root or working directory may be reached through a symlink
+		realRoot, rootErr := filepath.EvalSymlinks(root)
+		realDir, dirErr := filepath.EvalSymlinks(dir)
+		if rootErr != nil || dirErr != nil {
+			return "", false
+		}
+		rel, err = filepath.Rel(realRoot, realDir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+	}
+	return rel, true
+}
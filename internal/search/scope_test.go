@@ -0,0 +1,197 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupScopeTest indexes chunks in three sub-projects of a project at /project.
+func setupScopeTest(t *testing.T) *Service {
+	t.Helper()
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for _, sp := range []*models.Subproject{
+		{ID: "billing", Path: "services/billing"},
+		{ID: "billing-admin", Path: "services/billing-admin"},
+		{ID: "web", Path: "web"},
+	} {
+		sp.SetTimestamps()
+		require.NoError(t, database.InsertSubproject(ctx, sp))
+	}
+
+	for path, subproject := range map[string]string{
+		"/project/services/billing/invoice.go":       "billing",
+		"/project/services/billing/internal/tax.go":  "billing",
+		"/project/services/billing-admin/invoice.go": "billing-admin",
+		"/project/web/invoice.ts":                    "web",
+	} {
+		insertIndexedChunk(t, ctx, database, mockEmb, &models.Chunk{
+			FilePath:     path,
+			SubprojectID: &subproject,
+			StartLine:    1,
+			EndLine:      10,
+			Level:        models.ChunkLevelMethod,
+			Name:         "invoiceTotal",
+			Content:      "func invoiceTotal() { // compute invoice total }",
+			Language:     "go",
+		})
+	}
+
+	return NewServiceWithOptions(database, mockEmb, ServiceOptions{
+		Hybrid:      DefaultHybridConfig(),
+		ProjectRoot: "/project",
+	})
+}
+
+func TestResolveScope(t *testing.T) {
+	svc := setupScopeTest(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		scope      Scope
+		pathPrefix string
+		want       ResolvedScope
+	}{
+		{
+			name: "default is all",
+			want: ResolvedScope{Mode: ScopeAll},
+		},
+		{
+			name:       "legacy path prefix",
+			pathPrefix: "web",
+			want:       ResolvedScope{Mode: ScopePath, Path: "web", PathPrefix: "/project/web"},
+		},
+		{
+			name:  "path",
+			scope: Scope{Mode: ScopePath, Value: "services/"},
+			want:  ResolvedScope{Mode: ScopePath, Path: "services/", PathPrefix: "/project/services/"},
+		},
+		{
+			name:  "subproject",
+			scope: Scope{Mode: ScopeSubproject, Value: "billing"},
+			want: ResolvedScope{
+				Mode: ScopeSubproject, Subproject: "billing",
+				Path: "services/billing",
+			},
+		},
+		{
+			name:  "auto inside a sub-project",
+			scope: Scope{Mode: ScopeAuto, Value: "/project/services/billing/internal"},
+			want: ResolvedScope{
+				Mode: ScopeSubproject, Subproject: "billing",
+				Path: "services/billing",
+			},
+		},
+		{
+			name:  "auto picks the sub-project on a path boundary",
+			scope: Scope{Mode: ScopeAuto, Value: "/project/services/billing-admin"},
+			want: ResolvedScope{
+				Mode: ScopeSubproject, Subproject: "billing-admin",
+				Path: "services/billing-admin",
+			},
+		},
+		{
+			name:  "auto at the project root",
+			scope: Scope{Mode: ScopeAuto, Value: "/project"},
+			want:  ResolvedScope{Mode: ScopeAll},
+		},
+		{
+			name:  "auto outside the project",
+			scope: Scope{Mode: ScopeAuto, Value: "/elsewhere/services/billing"},
+			want:  ResolvedScope{Mode: ScopeAll},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.ResolveScope(ctx, tt.scope, tt.pathPrefix)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *got)
+		})
+	}
+}
+
+func TestResolveScope_Invalid(t *testing.T) {
+	svc := setupScopeTest(t)
+	ctx := context.Background()
+
+	for _, scope := range []Scope{
+		{Mode: ScopeSubproject, Value: "unknown"},
+		{Mode: ScopeSubproject},
+		{Mode: ScopePath},
+		{Mode: "everything"},
+	} {
+		_, err := svc.ResolveScope(ctx, scope, "")
+		assert.ErrorIs(t, err, ErrInvalidScope, "scope %+v", scope)
+	}
+}
+
+func TestSearch_AutoScopeSearchesContainingSubproject(t *testing.T) {
+	svc := setupScopeTest(t)
+
+	resp, err := svc.Search(context.Background(), Query{
+		Text:  "invoice total",
+		Scope: Scope{Mode: ScopeAuto, Value: "/project/services/billing/internal"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Results, 2)
+	for _, r := range resp.Results {
+		assert.True(t, strings.HasPrefix(r.Chunk.FilePath, "/project/services/billing/"), r.Chunk.FilePath)
+	}
+	require.NotNil(t, resp.Scope)
+	assert.Equal(t, ScopeSubproject, resp.Scope.Mode)
+	assert.Equal(t, "billing", resp.Scope.Subproject)
+}
+
+func TestSearch_SubprojectScopeMatchesTaggedChunks(t *testing.T) {
+	svc := setupScopeTest(t)
+	ctx := context.Background()
+
+	// A nested sub-project claims a directory inside billing
+	require.NoError(t, svc.db.SetFileSubprojects(ctx, map[string]*models.Subproject{
+		"/project/services/billing/internal/tax.go": {ID: "billing-internal", Path: "services/billing/internal"},
+	}))
+
+	resp, err := svc.Search(ctx, Query{
+		Text:  "invoice total",
+		Scope: Scope{Mode: ScopeSubproject, Value: "billing"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "/project/services/billing/invoice.go", resp.Results[0].Chunk.FilePath)
+}
+
+func TestSearch_AllScopeSearchesEverything(t *testing.T) {
+	svc := setupScopeTest(t)
+
+	resp, err := svc.Search(context.Background(), Query{
+		Text:  "invoice total",
+		Scope: Scope{Mode: ScopeAll},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, resp.Results, 4)
+	require.NotNil(t, resp.Scope)
+	assert.Equal(t, ScopeAll, resp.Scope.Mode)
+}
+
+func TestSearch_InvalidScope(t *testing.T) {
+	svc := setupScopeTest(t)
+
+	_, err := svc.Search(context.Background(), Query{
+		Text:  "invoice total",
+		Scope: Scope{Mode: ScopeSubproject, Value: "unknown"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidScope)
+}
@@ -3,12 +3,16 @@ package search
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/pommel-dev/pommel/internal/chunker"
 	"github.com/pommel-dev/pommel/internal/db"
 	"github.com/pommel-dev/pommel/internal/embedder"
 	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/pommel-dev/pommel/internal/rerank"
 )
 
 // DefaultLimit is the default number of results to return when no limit is specified.
@@ -18,12 +22,71 @@ const DefaultLimit = 10
 type Query struct {
 	// Text is the search query text (required).
 	Text string
+	// Snippet treats Text as a code snippet, such as a block of code or a stack
+	// trace, rather than a natural-language query.
+	Snippet bool
 	// Limit is the maximum number of results to return (default: 10).
 	Limit int
 	// Levels filters results to specific chunk levels (e.g., "method", "class", "file").
 	Levels []string
 	// PathPrefix filters results to chunks whose file path starts with this prefix.
 	PathPrefix string
+	// Languages filters results to chunks in these languages (e.g., "go", "python").
+	// A file extension of a known language, such as "ts", selects that language.
+	Languages []string
+	// Extensions filters results to files with these extensions (e.g., ".go" or "go").
+	Extensions []string
+	// Include restricts results to files matching any of these gitignore-style
+	// patterns, relative to the project root (e.g., "internal/**").
+	Include []string
+	// Exclude drops results in files matching any of these gitignore-style
+	// patterns (e.g., "**/*_test.go", "vendor/**").
+	Exclude []string
+	// Tests selects whether results come from test files: TestsInclude (the
+	// default), TestsExclude or TestsOnly. Files are flagged as tests at index
+	// time from language conventions and the configured test patterns.
+	Tests string
+	// Scope restricts the search to a path or sub-project (default: PathPrefix or all).
+	Scope Scope
+	// HybridEnabled overrides the service default for hybrid search (nil = use default).
+	HybridEnabled *bool
+	// RerankEnabled overrides the service default for re-ranking (nil = use default).
+	RerankEnabled *bool
+	// Fusion overrides how the vector and keyword legs are fused: FusionRRF
+	// or FusionLinear (empty = use default).
+	Fusion string
+	// RRFK overrides the RRF constant k (0 = use default).
+	RRFK int
+	// VectorWeight and KeywordWeight override the weight of each leg in
+	// fusion (nil = use default).
+	VectorWeight  *float64
+	KeywordWeight *float64
+	// Diversity trades relevance for variety when selecting results, from 0
+	// (ranked by relevance only, the default) to 1, using maximal marginal
+	// relevance.
+	Diversity float64
+	// MaxPerFile caps the number of results from any one file on a page
+	// (0 = no cap).
+	MaxPerFile int
+	// MinScore drops results whose ScoreDetails.Relevance is below it, and
+	// MinRelativeScore drops results scoring below that fraction of the top
+	// score (nil = use default, 0 = no cutoff).
+	MinScore         *float64
+	MinRelativeScore *float64
+	// Page is the 1-based page of Limit results to return (default: 1).
+	Page int
+	// Cursor continues a previous search from its Response.NextCursor. The
+	// query and filters are taken from the cursor; Limit may still be set.
+	Cursor string
+	// ContextLines is the number of lines before and after each result to
+	// read from disk into Result.Context (0 = none, at most MaxContextLines).
+	ContextLines int
+	// IncludeParent adds the signature and header of each result's parent
+	// chunk to Result.Context.
+	IncludeParent bool
+	// IncludeImports adds the import block of each result's file to
+	// Result.Context.
+	IncludeImports bool
 }
 
 // Result represents a single search result.
@@ -31,12 +94,55 @@ type Result struct {
 	// Chunk is the matching code chunk.
 	Chunk *models.Chunk
 	// Score is the similarity score (0-1, higher is more similar).
+	// After re-ranking this is the reranker's final score.
 	Score float32
 	// Parent contains info about the parent chunk, if any.
 	Parent *ParentInfo
 	// MatchedSplits is the number of splits that matched for this chunk.
 	// Only set when multiple splits of the same original chunk matched.
 	MatchedSplits int
+	// MatchSource indicates which search legs found this chunk ("vector", "keyword", or "both").
+	MatchSource string
+	// ScoreDetails contains the per-stage score breakdown.
+	ScoreDetails *ScoreDetails
+	// MatchReasons contains human-readable explanations of why the chunk matched.
+	MatchReasons []string
+	// Highlights are the spans of the chunk's content that match the
+	// query's terms.
+	Highlights []Highlight
+	// Snippet is a few lines of the chunk around its best match, or nil if
+	// no term matches.
+	Snippet *SourceLines
+	// Context is the code around the chunk, if the query asked for it.
+	Context *ResultContext
+}
+
+// ScoreDetails contains the score contributed by each stage of the search pipeline.
+type ScoreDetails struct {
+	// VectorScore is the vector similarity score (0 if not found by vector search).
+	VectorScore float64
+	// KeywordScore is the BM25 score (0 if not found by keyword search).
+	KeywordScore float64
+	// RRFScore is the fused score of both legs, by RRF or linear fusion (0 if
+	// hybrid search was not used).
+	RRFScore float64
+	// Fusion is the fusion method used, FusionRRF or FusionLinear (empty if
+	// hybrid search was not used).
+	Fusion string
+	// VectorContribution and KeywordContribution are each leg's weighted
+	// share of RRFScore.
+	VectorContribution  float64
+	KeywordContribution float64
+	// RerankerScore is the reranker's adjustment, or its relevance score for
+	// a model reranker (0 if re-ranking was not used).
+	RerankerScore float64
+	// Relevance is the result's relevance on an absolute scale from 0 to 1:
+	// the model reranker's score if one scored the result, else VectorScore.
+	// Unlike the fused score it is comparable across queries, so confidence
+	// and MinScore go by it.
+	Relevance float64
+	// SignalScores contains the individual reranker signal contributions.
+	SignalScores map[string]float64
 }
 
 // ParentInfo contains information about a chunk's parent.
@@ -59,26 +165,125 @@ type Response struct {
 	TotalResults int
 	// SearchTimeMs is the search duration in milliseconds.
 	SearchTimeMs int64
+	// HybridEnabled reports whether hybrid (vector + keyword) search was used.
+	HybridEnabled bool
+	// RerankEnabled reports whether the re-ranking stage was applied.
+	RerankEnabled bool
+	// Scope is the part of the index that was searched.
+	Scope *ResolvedScope
+	// Confidence rates how likely the results are to contain what was asked
+	// for: ConfidenceHigh, ConfidenceMedium or ConfidenceLow.
+	Confidence string
+	// NextCursor fetches the next page of results. Empty on the last page.
+	NextCursor string
+}
+
+// ServiceOptions configures the hybrid retrieval and re-ranking stages of a Service.
+type ServiceOptions struct {
+	// Hybrid configures keyword + vector fusion. Hybrid.Enabled is the default
+	// for queries that don't set HybridEnabled explicitly.
+	Hybrid HybridConfig
+	// RerankEnabled is the default for queries that don't set RerankEnabled explicitly.
+	RerankEnabled bool
+	// Reranker re-scores the fused candidates (default: heuristic reranker).
+	Reranker rerank.Reranker
+	// RerankCandidates is the number of top candidates passed to the reranker.
+	RerankCandidates int
+	// ProjectRoot resolves project-relative scope paths against the absolute
+	// paths stored in the index. Empty means paths are matched as given.
+	ProjectRoot string
+	// LanguageForExtension maps a file extension such as ".ts" to the name of
+	// its language, so language filters accept extensions. Optional.
+	LanguageForExtension func(ext string) (string, bool)
+	// MinScore and MinRelativeScore are the defaults for queries that don't
+	// set them (0 = no cutoff).
+	MinScore         float64
+	MinRelativeScore float64
+	// Imports returns the import block of a source file for
+	// Query.IncludeImports. Optional; without it no imports are returned.
+	Imports func(ctx context.Context, path string, content []byte) (*chunker.ImportBlock, error)
 }
 
 // Service provides semantic code search functionality.
 type Service struct {
-	db       *db.DB
-	embedder embedder.Embedder
+	db        *db.DB
+	embedder  embedder.Embedder
+	hybrid    *HybridSearcher
+	options   ServiceOptions
+	snapshots *snapshotCache
+	fileSets  *fileSetCache
 }
 
-// NewService creates a new search service.
+// NewService creates a new vector-only search service.
+// Hybrid search and re-ranking can still be requested per query.
 func NewService(database *db.DB, emb embedder.Embedder) *Service {
+	hybrid := DefaultHybridConfig()
+	hybrid.Enabled = false
+	return NewServiceWithOptions(database, emb, ServiceOptions{Hybrid: hybrid})
+}
+
+// NewServiceWithOptions creates a new search service running the full
+// hybrid retrieval, RRF fusion, split deduplication and re-ranking pipeline.
+func NewServiceWithOptions(database *db.DB, emb embedder.Embedder, opts ServiceOptions) *Service {
+	if opts.Hybrid.RRFK <= 0 {
+		opts.Hybrid.RRFK = DefaultRRFK
+	}
+	if opts.Reranker == nil {
+		opts.Reranker = rerank.NewHeuristicReranker()
+	}
+
+	// Whether a query is hybrid is decided per request by the service,
+	// so the underlying searcher is always allowed to fuse.
+	hybridCfg := opts.Hybrid
+	hybridCfg.Enabled = true
+
 	return &Service{
-		db:       database,
-		embedder: emb,
+		db:        database,
+		embedder:  emb,
+		hybrid:    NewHybridSearcher(database, emb, hybridCfg),
+		options:   opts,
+		snapshots: newSnapshotCache(),
+		fileSets:  newFileSetCache(),
 	}
 }
 
 // Search performs a semantic search for code chunks matching the query.
+// Candidates are retrieved by vector (and optionally keyword) search, fused
+// with RRF, deduplicated across splits and finally re-ranked. Only as many
+// results are ranked as the requested page needs. The ranked list is kept so
+// that later pages, requested by Page or by the returned NextCursor, are
+// sliced from the same ranking.
 func (s *Service) Search(ctx context.Context, query Query) (*Response, error) {
 	start := time.Now()
 
+	generation, err := s.db.IndexGeneration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cursor carries the original query; only the page size and the
+	// context added to results may change
+	offset, pageSize := -1, 0
+	fromCursor := query.Cursor != ""
+	if fromCursor {
+		c, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Generation != generation {
+			return nil, fmt.Errorf("%w: the index has changed since the search was run", ErrCursorExpired)
+		}
+		if query.Limit > 0 {
+			c.Query.Limit = query.Limit
+		}
+		if query.ContextLines > 0 {
+			c.Query.ContextLines = query.ContextLines
+		}
+		c.Query.IncludeParent = c.Query.IncludeParent || query.IncludeParent
+		c.Query.IncludeImports = c.Query.IncludeImports || query.IncludeImports
+		query, offset, pageSize = c.Query, c.Offset, c.PageSize
+	}
+
 	// Validate query text
 	trimmedText := strings.TrimSpace(query.Text)
 	if trimmedText == "" {
@@ -90,50 +295,326 @@ func (s *Service) Search(ctx context.Context, query Query) (*Response, error) {
 	if limit <= 0 {
 		limit = DefaultLimit
 	}
+	if pageSize <= 0 {
+		pageSize = limit
+	}
+	if offset < 0 {
+		offset = 0
+		if query.Page > 1 {
+			offset = (query.Page - 1) * limit
+		}
+	}
 
-	// Generate embedding for query
-	queryEmbedding, err := s.embedder.EmbedSingle(ctx, trimmedText)
+	// Pin the per-request defaults so later pages rank the same way
+	hybridEnabled := s.options.Hybrid.Enabled
+	if query.HybridEnabled != nil {
+		hybridEnabled = *query.HybridEnabled
+	}
+	rerankEnabled := s.options.RerankEnabled
+	if query.RerankEnabled != nil {
+		rerankEnabled = *query.RerankEnabled
+	}
+	fusion, err := s.resolveFusion(query)
 	if err != nil {
 		return nil, err
 	}
+	if query.Tests, err = resolveTests(query.Tests); err != nil {
+		return nil, err
+	}
+	query.Limit, query.Page, query.Cursor = limit, 0, ""
+	query.HybridEnabled, query.RerankEnabled = &hybridEnabled, &rerankEnabled
+	query.Fusion, query.RRFK = fusion.Method, fusion.K
+	query.VectorWeight, query.KeywordWeight = &fusion.VectorWeight, &fusion.KeywordWeight
+	minScore, minRelative := s.options.MinScore, s.options.MinRelativeScore
+	if query.MinScore != nil {
+		minScore = *query.MinScore
+	}
+	if query.MinRelativeScore != nil {
+		minRelative = *query.MinRelativeScore
+	}
+	query.MinScore, query.MinRelativeScore = &minScore, &minRelative
+
+	// Rank one result past the page to tell whether another page follows
+	depth := offset + limit + 1
+	key, err := snapshotKey(query, pageSize, generation)
+	if err != nil {
+		return nil, err
+	}
+	snap := s.snapshots.get(key)
+	if snap == nil && fromCursor {
+		return nil, fmt.Errorf("%w: the search results are no longer cached", ErrCursorExpired)
+	}
+	if snap == nil || (len(snap.results) < depth && !snap.exhausted()) {
+		snap, err = s.fill(ctx, snap, query, trimmedText, depth, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		s.snapshots.put(key, snap)
+	}
+
+	// Copy the page so that annotating it leaves the snapshot untouched
+	var results []Result
+	if offset < len(snap.results) {
+		end := offset + limit
+		if end > len(snap.results) {
+			end = len(snap.results)
+		}
+		results = append([]Result(nil), snap.results[offset:end]...)
+	}
+	matcher := newTermMatcher(trimmedText, query.Snippet)
+	for i := range results {
+		results[i].MatchReasons = buildMatchReasons(&results[i])
+		results[i].Highlights, results[i].Snippet = matcher.highlight(results[i].Chunk)
+	}
+	results = s.expandContext(ctx, results, query)
 
-	// Build search options
-	searchOpts := db.SearchOptions{
-		Embedding:  queryEmbedding,
-		Limit:      limit,
-		Levels:     query.Levels,
-		PathPrefix: query.PathPrefix,
+	var nextCursor string
+	if offset+limit < len(snap.results) {
+		nextCursor, err = encodeCursor(cursor{Query: query, Offset: offset + limit, PageSize: pageSize, Generation: generation})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	elapsed := time.Since(start)
+	searchTimeMs := elapsed.Milliseconds()
+	// Ensure at least 1ms is reported if there was any elapsed time
+	if searchTimeMs == 0 && elapsed > 0 {
+		searchTimeMs = 1
+	}
+
+	return &Response{
+		Query:         query.Text,
+		Results:       results,
+		TotalResults:  len(results),
+		SearchTimeMs:  searchTimeMs,
+		HybridEnabled: hybridEnabled,
+		RerankEnabled: rerankEnabled,
+		Scope:         snap.scope,
+		Confidence:    snap.confidence,
+		NextCursor:    nextCursor,
+	}, nil
+}
+
+// resolveFusion applies a query's fusion overrides to the service defaults.
+func (s *Service) resolveFusion(query Query) (FusionOptions, error) {
+	fusion := s.hybrid.fusionOptions(HybridOptions{})
+
+	switch query.Fusion {
+	case "":
+	case FusionRRF, FusionLinear:
+		fusion.Method = query.Fusion
+	default:
+		return fusion, fmt.Errorf("%w: unknown fusion method %q, use %q or %q", ErrInvalidFusion, query.Fusion, FusionRRF, FusionLinear)
+	}
+	if query.RRFK < 0 {
+		return fusion, fmt.Errorf("%w: rrf_k must not be negative", ErrInvalidFusion)
+	}
+	if query.RRFK > 0 {
+		fusion.K = query.RRFK
+	}
+	if query.VectorWeight != nil {
+		fusion.VectorWeight = *query.VectorWeight
+	}
+	if query.KeywordWeight != nil {
+		fusion.KeywordWeight = *query.KeywordWeight
+	}
+	if fusion.VectorWeight < 0 || fusion.KeywordWeight < 0 {
+		return fusion, fmt.Errorf("%w: weights must not be negative", ErrInvalidFusion)
+	}
+	if fusion.VectorWeight == 0 && fusion.KeywordWeight == 0 {
+		return fusion, fmt.Errorf("%w: at least one weight must be positive", ErrInvalidFusion)
+	}
+	return fusion, nil
+}
+
+// fill returns snap with at least depth results placed on pages, or all of
+// them if the ranking holds fewer. The query is ranked again, deeper, for as
+// long as arranging the pages needs more candidates. A nil snap starts from
+// a new ranking.
+func (s *Service) fill(ctx context.Context, snap *snapshot, query Query, text string, depth, pageSize int) (*snapshot, error) {
+	if snap == nil {
+		ranked, err := s.rank(ctx, query, text, depth)
+		if err != nil {
+			return nil, err
+		}
+		snap = ranked
 	}
 
-	// Perform vector search
-	vectorResults, err := s.db.SearchChunks(ctx, searchOpts)
+	for {
+		arranged, short, err := s.arrange(ctx, snap, query, depth, pageSize)
+		if err != nil || !short {
+			return arranged, err
+		}
+		deeper, err := s.rank(ctx, query, text, 2*(len(snap.results)+len(snap.pool)))
+		if err != nil {
+			return nil, err
+		}
+		snap = snap.extend(deeper)
+	}
+}
+
+// rank runs the search pipeline for a query, returning a snapshot whose pool
+// holds at least depth ranked results if there are that many. Candidates fetched for the reranker are
+// ranked too, so the list may be longer. The query's HybridEnabled,
+// RerankEnabled and fusion options must be set.
+func (s *Service) rank(ctx context.Context, query Query, text string, depth int) (*snapshot, error) {
+	// Resolve the scope into the sub-project or path prefix the index is filtered by
+	scope, err := s.ResolveScope(ctx, query.Scope, query.PathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := s.resolveFilter(ctx, query, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	hybridEnabled, rerankEnabled := *query.HybridEnabled, *query.RerankEnabled
+	fusion := FusionOptions{
+		Method:        query.Fusion,
+		K:             query.RRFK,
+		VectorWeight:  *query.VectorWeight,
+		KeywordWeight: *query.KeywordWeight,
+	}
+
+	// Fetch enough candidates to feed the reranker, and to leave the pages
+	// arranged by diversity or a per-file cap something to choose from
+	candidates := depth
+	if query.Diversity > 0 || query.MaxPerFile > 0 {
+		candidates *= diversityPoolFactor
+	}
+	if rerankEnabled && s.options.RerankCandidates > candidates {
+		candidates = s.options.RerankCandidates
+	}
+
+	// Retrieve and fuse candidates
+	merged, err := s.hybrid.Search(ctx, text, HybridOptions{
+		HybridEnabled: hybridEnabled,
+		RRFK:          fusion.K,
+		Fusion:        fusion.Method,
+		VectorWeight:  fusion.VectorWeight,
+		KeywordWeight: fusion.KeywordWeight,
+		Limit:         candidates,
+		Levels:        filter.levels,
+		Languages:     filter.languages,
+		SubprojectID:  filter.subproject,
+		PathPrefix:    filter.pathPrefix,
+		TestFiles:     filter.testFiles,
+		FilePaths:     filter.filePaths(),
+		Snippet:       query.Snippet,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// Build results with chunk details
-	results := make([]Result, 0, len(vectorResults))
-	for _, vr := range vectorResults {
-		// Get chunk details
-		chunk, err := s.db.GetChunk(ctx, vr.ChunkID)
+	var fused *FusionOptions
+	if hybridEnabled {
+		fused = &fusion
+	}
+	results, err := s.buildResults(ctx, merged, filter, fused)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deduplicate split chunks and boost scores for multiple matches
+	results = DeduplicateSplitResults(results)
+
+	// Re-rank the top candidates
+	if rerankEnabled {
+		rerankQuery := text
+		if query.Snippet {
+			// Rank on the snippet's identifiers rather than the raw code
+			rerankQuery = strings.Join(PreprocessSnippet(text).Terms, " ")
+		}
+		results, err = s.rerankResults(ctx, rerankQuery, results)
 		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Rate the ranking before the cutoff, which would hide how flat it is
+	confidence := assessConfidence(results)
+	ranked := len(results)
+	results = applyScoreCutoff(results, *query.MinScore, *query.MinRelativeScore)
+
+	// A deeper ranking finds nothing new once the index ran out of
+	// candidates or the cutoff reached into the ranking
+	return &snapshot{
+		pool:       results,
+		scope:      scope,
+		confidence: confidence,
+		complete:   len(merged) < candidates || len(results) < ranked,
+		createdAt:  time.Now(),
+	}, nil
+}
+
+// buildResults loads chunk details for fused candidates, applies filters the
+// keyword leg can't apply itself, and attaches parent information. fusion is
+// nil if the candidates weren't fused.
+func (s *Service) buildResults(ctx context.Context, merged []MergedResult, filter searchFilter, fusion *FusionOptions) ([]Result, error) {
+	ids := make([]string, len(merged))
+	for i, m := range merged {
+		ids[i] = m.ChunkID
+	}
+
+	chunks, err := s.db.GetChunksByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	chunkMap := make(map[string]*models.Chunk, len(chunks))
+	for _, chunk := range chunks {
+		chunkMap[chunk.ID] = chunk
+	}
+
+	parents, err := s.parentChunks(ctx, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(merged))
+	for _, m := range merged {
+		chunk, ok := chunkMap[m.ChunkID]
+		if !ok {
 			// Skip chunks that can't be retrieved (shouldn't happen in normal operation)
 			continue
 		}
+		if !filter.matches(chunk) {
+			continue
+		}
+
+		details := &ScoreDetails{
+			VectorScore:  m.VectorScore,
+			KeywordScore: m.KeywordScore,
+			Relevance:    m.VectorScore,
+		}
+		score := m.RRFScore
+		if fusion != nil {
+			// Normalize the fused score into 0-1 so scores stay comparable with vector-only search
+			details.RRFScore = m.RRFScore
+			details.Fusion = fusion.Method
+			details.VectorContribution = m.VectorContribution
+			details.KeywordContribution = m.KeywordContribution
+			score = m.RRFScore / fusion.MaxScore()
+			if score > 1.0 {
+				score = 1.0
+			}
+		}
 
 		result := Result{
-			Chunk: chunk,
-			Score: DistanceToSimilarity(vr.Distance),
+			Chunk:        chunk,
+			Score:        float32(score),
+			MatchSource:  m.MatchSource(),
+			ScoreDetails: details,
 		}
 
-		// Get parent info if ParentID is set
 		if chunk.ParentID != nil {
-			parentChunk, err := s.db.GetChunk(ctx, *chunk.ParentID)
-			if err == nil && parentChunk != nil {
+			if parent, ok := parents[*chunk.ParentID]; ok {
 				result.Parent = &ParentInfo{
-					ID:    parentChunk.ID,
-					Name:  parentChunk.Name,
-					Level: string(parentChunk.Level),
+					ID:    parent.ID,
+					Name:  parent.Name,
+					Level: string(parent.Level),
 				}
 			}
 		}
@@ -141,27 +622,127 @@ func (s *Service) Search(ctx context.Context, query Query) (*Response, error) {
 		results = append(results, result)
 	}
 
-	// Deduplicate split chunks and boost scores for multiple matches
-	results = DeduplicateSplitResults(results)
+	return results, nil
+}
 
-	// Ensure we don't exceed the requested limit after deduplication
-	if len(results) > limit {
-		results = results[:limit]
+// parentChunks loads the parents of chunks in one query, keyed by ID.
+func (s *Service) parentChunks(ctx context.Context, chunks []*models.Chunk) (map[string]*models.Chunk, error) {
+	var ids []string
+	for _, chunk := range chunks {
+		if chunk.ParentID != nil {
+			ids = append(ids, *chunk.ParentID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
 	}
 
-	elapsed := time.Since(start)
-	searchTimeMs := elapsed.Milliseconds()
-	// Ensure at least 1ms is reported if there was any elapsed time
-	if searchTimeMs == 0 && elapsed > 0 {
-		searchTimeMs = 1
+	loaded, err := s.db.GetChunksByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[string]*models.Chunk, len(loaded))
+	for _, parent := range loaded {
+		parents[parent.ID] = parent
 	}
+	return parents, nil
+}
 
-	return &Response{
-		Query:        query.Text,
-		Results:      results,
-		TotalResults: len(results),
-		SearchTimeMs: searchTimeMs,
-	}, nil
+// rerankResults re-scores the top candidates with the configured reranker.
+// Results beyond the candidate window keep their order after the re-ranked ones.
+func (s *Service) rerankResults(ctx context.Context, query string, results []Result) ([]Result, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	window := len(results)
+	if s.options.RerankCandidates > 0 && s.options.RerankCandidates < window {
+		window = s.options.RerankCandidates
+	}
+
+	byID := make(map[string]Result, window)
+	candidates := make([]rerank.Candidate, window)
+	for i, r := range results[:window] {
+		byID[r.Chunk.ID] = r
+		candidates[i] = rerank.Candidate{
+			ChunkID:   r.Chunk.ID,
+			Content:   r.Chunk.Content,
+			Name:      r.Chunk.Name,
+			FilePath:  s.filterPath(r.Chunk.FilePath), // Relative, so path patterns match as in filters
+			ChunkType: string(r.Chunk.Level),
+			Signature: r.Chunk.Signature,
+			BaseScore: float64(r.Score),
+			ModTime:   r.Chunk.LastModified,
+			IsTest:    r.Chunk.IsTest,
+		}
+	}
+
+	ranked, err := s.options.Reranker.Rerank(ctx, query, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	reranked := make([]Result, 0, len(results))
+	for _, rc := range ranked {
+		result, ok := byID[rc.ChunkID]
+		if !ok {
+			continue
+		}
+		result.Score = float32(rc.FinalScore)
+		if result.ScoreDetails == nil {
+			result.ScoreDetails = &ScoreDetails{}
+		}
+		result.ScoreDetails.RerankerScore = rc.RerankerScore
+		if rc.ModelScore {
+			result.ScoreDetails.Relevance = rc.RerankerScore
+		}
+		result.ScoreDetails.SignalScores = rc.SignalScores
+		reranked = append(reranked, result)
+	}
+
+	return append(reranked, results[window:]...), nil
+}
+
+// signalReasons maps reranker signal names to human-readable match reasons.
+var signalReasons = map[string]string{
+	"name_match":    "name relevance boost",
+	"path_match":    "path relevance boost",
+	"exact_phrase":  "exact phrase boost",
+	"recency":       "recently modified",
+	"chunk_type":    "chunk type relevance",
+	"cross_encoder": "cross-encoder relevance",
+	"llm_grade":     "LLM relevance grade",
+	"path_boost":    "path boost",
+}
+
+// buildMatchReasons explains why a result matched, based on its match source
+// and the significant reranker signals.
+func buildMatchReasons(result *Result) []string {
+	reasons := []string{}
+
+	switch result.MatchSource {
+	case "vector":
+		reasons = append(reasons, "semantic similarity")
+	case "keyword":
+		reasons = append(reasons, "keyword match via BM25")
+	case "both":
+		reasons = append(reasons, "semantic similarity", "keyword match via BM25")
+	}
+
+	if result.ScoreDetails != nil {
+		signals := make([]string, 0, len(result.ScoreDetails.SignalScores))
+		for name, score := range result.ScoreDetails.SignalScores {
+			if _, ok := signalReasons[name]; ok && score > 0.05 {
+				signals = append(signals, name)
+			}
+		}
+		sort.Strings(signals)
+		for _, name := range signals {
+			reasons = append(reasons, signalReasons[name])
+		}
+	}
+
+	return reasons
 }
 
 // DistanceToSimilarity converts a vector distance to a similarity score.
@@ -2,6 +2,7 @@ package search
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -51,10 +52,10 @@ func insertTestChunk(t *testing.T, ctx context.Context, database *db.DB, chunk *
 func insertTestEmbedding(t *testing.T, ctx context.Context, database *db.DB, emb embedder.Embedder, chunkID string, content string) {
 	t.Helper()
 
-	embedding, err := emb.EmbedSingle(ctx, content)
+	embeddings, err := emb.EmbedDocuments(ctx, []string{content})
 	require.NoError(t, err)
 
-	err = database.InsertEmbedding(ctx, chunkID, embedding)
+	err = database.InsertEmbedding(ctx, chunkID, embeddings[0])
 	require.NoError(t, err)
 }
 
@@ -496,3 +497,292 @@ func TestSearch_TotalResultsCount(t *testing.T) {
 func hasPathPrefix(path, prefix string) bool {
 	return len(path) >= len(prefix) && path[:len(prefix)] == prefix
 }
+
+// =============================================================================
+// Hybrid Pipeline Tests
+// =============================================================================
+
+// insertIndexedChunk inserts a chunk with its embedding and FTS entry.
+func insertIndexedChunk(t *testing.T, ctx context.Context, database *db.DB, emb embedder.Embedder, chunk *models.Chunk) {
+	t.Helper()
+
+	insertTestChunk(t, ctx, database, chunk)
+	insertTestEmbedding(t, ctx, database, emb, chunk.ID, chunk.Content)
+	require.NoError(t, database.InsertFTSEntry(ctx, chunk))
+}
+
+func pipelineTestChunks() []*models.Chunk {
+	return []*models.Chunk{
+		{
+			FilePath:  "/project/auth/login.go",
+			StartLine: 10,
+			EndLine:   25,
+			Level:     models.ChunkLevelMethod,
+			Name:      "HandleLogin",
+			Content:   "func HandleLogin(w http.ResponseWriter, r *http.Request) { validateCredentials(r) }",
+			Language:  "go",
+		},
+		{
+			FilePath:  "/project/auth/session.go",
+			StartLine: 1,
+			EndLine:   20,
+			Level:     models.ChunkLevelClass,
+			Name:      "SessionStore",
+			Content:   "type SessionStore struct { sessions map[string]Session }",
+			Language:  "go",
+		},
+		{
+			FilePath:  "/project/db/connection.go",
+			StartLine: 1,
+			EndLine:   50,
+			Level:     models.ChunkLevelFile,
+			Name:      "connection.go",
+			Content:   "package db\n\nfunc Connect() *sql.DB { return nil }",
+			Language:  "go",
+		},
+	}
+}
+
+func TestSearch_HybridPipeline(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for _, chunk := range pipelineTestChunks() {
+		insertIndexedChunk(t, ctx, database, mockEmb, chunk)
+	}
+
+	svc := NewServiceWithOptions(database, mockEmb, ServiceOptions{
+		Hybrid:           DefaultHybridConfig(),
+		RerankEnabled:    true,
+		RerankCandidates: 20,
+	})
+
+	response, err := svc.Search(ctx, Query{Text: "validateCredentials", Limit: 10})
+	require.NoError(t, err)
+
+	assert.True(t, response.HybridEnabled)
+	assert.True(t, response.RerankEnabled)
+	require.NotEmpty(t, response.Results)
+
+	var found bool
+	for _, result := range response.Results {
+		require.NotNil(t, result.ScoreDetails)
+		assert.NotEmpty(t, result.MatchSource)
+		assert.NotEmpty(t, result.MatchReasons)
+
+		if result.Chunk.Name == "HandleLogin" {
+			found = true
+			assert.Contains(t, []string{"keyword", "both"}, result.MatchSource)
+			assert.Greater(t, result.ScoreDetails.KeywordScore, 0.0)
+			assert.Greater(t, result.ScoreDetails.RRFScore, 0.0)
+			assert.NotNil(t, result.ScoreDetails.SignalScores)
+			assert.Contains(t, result.MatchReasons, "keyword match via BM25")
+		}
+	}
+	assert.True(t, found, "keyword match should be returned by hybrid search")
+}
+
+func TestSearch_HybridKeywordResultsRespectFilters(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for _, chunk := range pipelineTestChunks() {
+		insertIndexedChunk(t, ctx, database, mockEmb, chunk)
+	}
+
+	svc := NewServiceWithOptions(database, mockEmb, ServiceOptions{Hybrid: DefaultHybridConfig()})
+
+	response, err := svc.Search(ctx, Query{
+		Text:       "validateCredentials",
+		Limit:      10,
+		PathPrefix: "/project/db/",
+	})
+	require.NoError(t, err)
+
+	for _, result := range response.Results {
+		assert.True(t, strings.HasPrefix(result.Chunk.FilePath, "/project/db/"),
+			"result %s should match path filter", result.Chunk.FilePath)
+	}
+
+	response, err = svc.Search(ctx, Query{
+		Text:   "validateCredentials",
+		Limit:  10,
+		Levels: []string{"class"},
+	})
+	require.NoError(t, err)
+
+	for _, result := range response.Results {
+		assert.Equal(t, models.ChunkLevelClass, result.Chunk.Level)
+	}
+}
+
+func TestSearch_SnippetMatchesIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for _, chunk := range pipelineTestChunks() {
+		insertIndexedChunk(t, ctx, database, mockEmb, chunk)
+	}
+
+	svc := NewServiceWithOptions(database, mockEmb, ServiceOptions{
+		Hybrid:        DefaultHybridConfig(),
+		RerankEnabled: true,
+	})
+
+	snippet := "store := &SessionStore{sessions: make(map[string]Session)}\nreturn store"
+	response, err := svc.Search(ctx, Query{Text: snippet, Snippet: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, snippet, response.Query)
+
+	var found bool
+	for _, result := range response.Results {
+		if result.Chunk.Name == "SessionStore" {
+			found = true
+			assert.Contains(t, []string{"keyword", "both"}, result.MatchSource)
+		}
+	}
+	assert.True(t, found, "snippet identifiers should drive the keyword leg")
+}
+
+func TestSearch_KeywordMatchesIdentifierSubWords(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	insertIndexedChunk(t, ctx, database, mockEmb, &models.Chunk{
+		FilePath: "/project/accounts.go", Language: "go", Level: models.ChunkLevelMethod,
+		StartLine: 1, EndLine: 3, Name: "getUserByEmail",
+		Content: "func getUserByEmail(addr string) (*Account, error) { return lookup(addr) }",
+	})
+
+	svc := NewServiceWithOptions(database, mockEmb, ServiceOptions{Hybrid: DefaultHybridConfig()})
+
+	response, err := svc.Search(ctx, Query{Text: "user email", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, response.Results, 1)
+	assert.Equal(t, "both", response.Results[0].MatchSource, "sub-words of getUserByEmail should match by keyword")
+}
+
+func TestSearch_QueryOverridesServiceDefaults(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for _, chunk := range pipelineTestChunks() {
+		insertIndexedChunk(t, ctx, database, mockEmb, chunk)
+	}
+
+	svc := NewServiceWithOptions(database, mockEmb, ServiceOptions{
+		Hybrid:        DefaultHybridConfig(),
+		RerankEnabled: true,
+	})
+
+	disabled := false
+	response, err := svc.Search(ctx, Query{
+		Text:          "session storage",
+		Limit:         10,
+		HybridEnabled: &disabled,
+		RerankEnabled: &disabled,
+	})
+	require.NoError(t, err)
+
+	assert.False(t, response.HybridEnabled)
+	assert.False(t, response.RerankEnabled)
+	for _, result := range response.Results {
+		assert.Equal(t, "vector", result.MatchSource)
+		assert.Equal(t, 0.0, result.ScoreDetails.RRFScore)
+		assert.Equal(t, []string{"semantic similarity"}, result.MatchReasons)
+	}
+}
+
+func TestSearch_FusionOverrides(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for _, chunk := range pipelineTestChunks() {
+		insertIndexedChunk(t, ctx, database, mockEmb, chunk)
+	}
+
+	hybrid := DefaultHybridConfig()
+	hybrid.VectorWeight, hybrid.KeywordWeight = 0.7, 0.3
+	svc := NewServiceWithOptions(database, mockEmb, ServiceOptions{Hybrid: hybrid})
+
+	response, err := svc.Search(ctx, Query{Text: "session storage", Limit: 10})
+	require.NoError(t, err)
+	require.NotEmpty(t, response.Results)
+	for _, result := range response.Results {
+		details := result.ScoreDetails
+		assert.Equal(t, FusionRRF, details.Fusion)
+		assert.InDelta(t, details.RRFScore, details.VectorContribution+details.KeywordContribution, 1e-9)
+		if result.MatchSource == "both" {
+			assert.Greater(t, details.VectorContribution, details.KeywordContribution, "config weights should favour the vector leg")
+		}
+	}
+
+	vectorOff := 0.0
+	response, err = svc.Search(ctx, Query{Text: "session storage", Limit: 10, Fusion: FusionLinear, RRFK: 10, VectorWeight: &vectorOff})
+	require.NoError(t, err)
+	require.NotEmpty(t, response.Results)
+	for _, result := range response.Results {
+		assert.Equal(t, FusionLinear, result.ScoreDetails.Fusion)
+		assert.Equal(t, 0.0, result.ScoreDetails.VectorContribution)
+	}
+	assert.Contains(t, []string{"keyword", "both"}, response.Results[0].MatchSource, "only the keyword leg should count")
+
+	negative := -1.0
+	for _, query := range []Query{
+		{Text: "session", Fusion: "average"},
+		{Text: "session", RRFK: -1},
+		{Text: "session", KeywordWeight: &negative},
+		{Text: "session", VectorWeight: &vectorOff, KeywordWeight: &vectorOff},
+	} {
+		_, err := svc.Search(ctx, query)
+		assert.ErrorIs(t, err, ErrInvalidFusion, "%+v", query)
+	}
+}
+
+func TestSearch_NewServiceIsVectorOnly(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for _, chunk := range pipelineTestChunks() {
+		insertIndexedChunk(t, ctx, database, mockEmb, chunk)
+	}
+
+	svc := NewService(database, mockEmb)
+
+	response, err := svc.Search(ctx, Query{Text: "validateCredentials", Limit: 10})
+	require.NoError(t, err)
+
+	assert.False(t, response.HybridEnabled)
+	assert.False(t, response.RerankEnabled)
+	for _, result := range response.Results {
+		assert.Equal(t, "vector", result.MatchSource)
+	}
+}
+
+func TestSearch_AttachesParentInfo(t *testing.T) {
+	svc := setupDiversityTest(t)
+
+	resp, err := svc.Search(context.Background(), Query{Text: "retry backoff", Limit: 10, Levels: []string{"method"}})
+	require.NoError(t, err)
+
+	var withParent int
+	for _, r := range resp.Results {
+		if r.Chunk.ParentID == nil {
+			assert.Nil(t, r.Parent)
+			continue
+		}
+		withParent++
+		require.NotNil(t, r.Parent)
+		assert.Equal(t, *r.Chunk.ParentID, r.Parent.ID)
+		assert.Equal(t, "Retrier", r.Parent.Name)
+		assert.Equal(t, "class", r.Parent.Level)
+	}
+	assert.Equal(t, 6, withParent)
+}
@@ -0,0 +1,222 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pommel-dev/pommel/internal/db"
+	"github.com/pommel-dev/pommel/internal/models"
+)
+
+// ErrSourceNotFound is returned when the source chunk of a similarity search
+// can't be found in the index.
+var ErrSourceNotFound = errors.New("source chunk not found")
+
+// SimilarQuery represents a request for chunks similar to an indexed chunk.
+// The source is identified by ChunkID, or by a File and Line it covers.
+type SimilarQuery struct {
+	// ChunkID is the ID of the source chunk.
+	ChunkID string
+	// File is the source file, absolute or relative to the project root.
+	File string
+	// Line is a line within File; the innermost chunk covering it is the source.
+	Line int
+	// Limit is the maximum number of results to return (default: 10).
+	Limit int
+	// Levels filters results to specific chunk levels (e.g., "method", "class", "file").
+	Levels []string
+	// PathPrefix filters results to chunks whose file path starts with this prefix.
+	PathPrefix string
+	// Languages, Extensions, Include, Exclude and Tests filter results as
+	// they do for Query.
+	Languages  []string
+	Extensions []string
+	Include    []string
+	Exclude    []string
+	Tests      string
+	// Scope restricts the search to a path or sub-project (default: PathPrefix or all).
+	Scope Scope
+}
+
+// SimilarResponse represents the chunks most similar to a source chunk.
+type SimilarResponse struct {
+	// Source is the chunk the results are similar to.
+	Source *models.Chunk
+	// Results contains the similar chunks ordered by similarity.
+	Results []Result
+	// TotalResults is the count of results returned.
+	TotalResults int
+	// SearchTimeMs is the search duration in milliseconds.
+	SearchTimeMs int64
+	// Scope is the part of the index that was searched.
+	Scope *ResolvedScope
+}
+
+// Similar finds the chunks nearest to a source chunk, using the source's
+// stored embedding rather than embedding it again. The source and the other
+// splits of the same original chunk are excluded from the results.
+func (s *Service) Similar(ctx context.Context, query SimilarQuery) (*SimilarResponse, error) {
+	start := time.Now()
+
+	tests, err := resolveTests(query.Tests)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.similarSource(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	embedding, err := s.db.GetEmbedding(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	if embedding == nil {
+		return nil, fmt.Errorf("%w: chunk %s has no embedding", ErrSourceNotFound, source.ID)
+	}
+
+	scope, err := s.ResolveScope(ctx, query.Scope, query.PathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := s.resolveFilter(ctx, Query{
+		Levels:     query.Levels,
+		Languages:  query.Languages,
+		Extensions: query.Extensions,
+		Include:    query.Include,
+		Exclude:    query.Exclude,
+		Tests:      tests,
+	}, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	excluded, err := s.splitFamily(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.similarResults(ctx, embedding, filter, excluded, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].MatchReasons = buildMatchReasons(&results[i])
+	}
+
+	elapsed := time.Since(start)
+	searchTimeMs := elapsed.Milliseconds()
+	if searchTimeMs == 0 && elapsed > 0 {
+		searchTimeMs = 1
+	}
+
+	return &SimilarResponse{
+		Source:       source,
+		Results:      results,
+		TotalResults: len(results),
+		SearchTimeMs: searchTimeMs,
+		Scope:        scope,
+	}, nil
+}
+
+// similarResults returns up to limit neighbors of an embedding matching
+// filter, leaving out the excluded chunks. Splits of one chunk collapse into
+// a single result, so neighbors are fetched in growing batches until the
+// limit is filled or the index runs out.
+func (s *Service) similarResults(ctx context.Context, embedding []float32, filter searchFilter, excluded map[string]bool, limit int) ([]Result, error) {
+	fetch := limit + len(excluded)
+	for {
+		neighbors, err := s.db.SearchChunks(ctx, db.SearchOptions{
+			Embedding:    embedding,
+			Limit:        fetch,
+			Levels:       filter.levels,
+			Languages:    filter.languages,
+			SubprojectID: filter.subproject,
+			PathPrefix:   filter.pathPrefix,
+			FilePaths:    filter.filePaths(),
+			TestFiles:    filter.testFiles,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		merged := make([]MergedResult, 0, len(neighbors))
+		for _, n := range neighbors {
+			if excluded[n.ChunkID] {
+				continue
+			}
+			score := float64(DistanceToSimilarity(n.Distance))
+			merged = append(merged, MergedResult{
+				ChunkID:     n.ChunkID,
+				RRFScore:    score,
+				VectorScore: score,
+				VectorRank:  len(merged),
+				KeywordRank: -1,
+			})
+		}
+
+		results, err := s.buildResults(ctx, merged, filter, nil)
+		if err != nil {
+			return nil, err
+		}
+		results = DeduplicateSplitResults(results)
+		if len(results) >= limit || len(neighbors) < fetch {
+			if len(results) > limit {
+				results = results[:limit]
+			}
+			return results, nil
+		}
+		fetch *= 2
+	}
+}
+
+// similarSource loads the source chunk of a similarity search.
+func (s *Service) similarSource(ctx context.Context, query SimilarQuery) (*models.Chunk, error) {
+	var (
+		source *models.Chunk
+		err    error
+	)
+	switch {
+	case query.ChunkID != "":
+		source, err = s.db.GetChunkByID(ctx, query.ChunkID)
+		if err == nil && source == nil {
+			err = fmt.Errorf("%w: no chunk with ID %s", ErrSourceNotFound, query.ChunkID)
+		}
+	case query.File != "":
+		source, err = s.db.GetChunkAt(ctx, s.indexedPathPrefix(query.File), query.Line)
+		if err == nil && source == nil {
+			err = fmt.Errorf("%w: no chunk covers %s:%d", ErrSourceNotFound, query.File, query.Line)
+		}
+	default:
+		err = fmt.Errorf("%w: a chunk ID or file location is required", ErrSourceNotFound)
+	}
+	return source, err
+}
+
+// splitFamily returns the IDs to exclude for a source chunk: the chunk itself
+// and, if it is a split, the original chunk and all of its splits.
+func (s *Service) splitFamily(ctx context.Context, source *models.Chunk) (map[string]bool, error) {
+	family := map[string]bool{source.ID: true}
+	if source.ParentChunkID == "" {
+		return family, nil
+	}
+
+	family[source.ParentChunkID] = true
+	ids, err := s.db.GetSplitChunkIDs(ctx, source.ParentChunkID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		family[id] = true
+	}
+	return family, nil
+}
@@ -0,0 +1,198 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pommel-dev/pommel/internal/embedder"
+	"github.com/pommel-dev/pommel/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSimilarTest indexes a retry function split in two, plus related chunks
+// elsewhere in a project at /project.
+func setupSimilarTest(t *testing.T) *Service {
+	t.Helper()
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	for _, chunk := range []*models.Chunk{
+		{
+			FilePath: "/project/retry.go", StartLine: 1, EndLine: 20,
+			Level: models.ChunkLevelMethod, Name: "Retry", Language: "go",
+			Content:       "func Retry(fn func() error) error { for attempt := 0; attempt < 3; attempt++ {",
+			ParentChunkID: "retry-original", ChunkIndex: 0, IsPartial: true,
+		},
+		{
+			FilePath: "/project/retry.go", StartLine: 21, EndLine: 40,
+			Level: models.ChunkLevelMethod, Name: "Retry", Language: "go",
+			Content:       "if err := fn(); err == nil { return nil }; sleep(backoff(attempt)) } }",
+			ParentChunkID: "retry-original", ChunkIndex: 1, IsPartial: true,
+		},
+		{
+			FilePath: "/project/http/client.go", StartLine: 10, EndLine: 30,
+			Level: models.ChunkLevelMethod, Name: "doWithRetry", Language: "go",
+			Content: "func doWithRetry(req *Request) error { for attempt := 0; attempt < 5; attempt++ {",
+		},
+		{
+			FilePath: "/project/db/conn.go", StartLine: 1, EndLine: 50,
+			Level: models.ChunkLevelClass, Name: "RetryingConn", Language: "go",
+			Content: "type RetryingConn struct { attempts int; backoff time.Duration }",
+		},
+	} {
+		insertIndexedChunk(t, ctx, database, mockEmb, chunk)
+	}
+
+	return NewServiceWithOptions(database, mockEmb, ServiceOptions{
+		Hybrid:      DefaultHybridConfig(),
+		ProjectRoot: "/project",
+	})
+}
+
+func resultPaths(results []Result) []string {
+	paths := make([]string, len(results))
+	for i, r := range results {
+		paths[i] = r.Chunk.FilePath
+	}
+	return paths
+}
+
+func TestSimilar_ExcludesSourceAndSplitSiblings(t *testing.T) {
+	svc := setupSimilarTest(t)
+
+	resp, err := svc.Similar(context.Background(), SimilarQuery{File: "retry.go", Line: 25})
+	require.NoError(t, err)
+
+	require.NotNil(t, resp.Source)
+	assert.Equal(t, 21, resp.Source.StartLine, "source should be the split covering the line")
+	assert.ElementsMatch(t, []string{"/project/http/client.go", "/project/db/conn.go"}, resultPaths(resp.Results))
+	assert.Equal(t, len(resp.Results), resp.TotalResults)
+	for _, r := range resp.Results {
+		assert.Equal(t, "vector", r.MatchSource)
+		assert.Equal(t, []string{"semantic similarity"}, r.MatchReasons)
+	}
+}
+
+func TestSimilar_ByChunkID(t *testing.T) {
+	svc := setupSimilarTest(t)
+	ctx := context.Background()
+
+	located, err := svc.Similar(ctx, SimilarQuery{File: "/project/http/client.go", Line: 10})
+	require.NoError(t, err)
+
+	resp, err := svc.Similar(ctx, SimilarQuery{ChunkID: located.Source.ID, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, located.Source.ID, resp.Source.ID)
+	require.Len(t, resp.Results, 1)
+	assert.NotEqual(t, "/project/http/client.go", resp.Results[0].Chunk.FilePath)
+}
+
+func TestSimilar_AppliesFilters(t *testing.T) {
+	svc := setupSimilarTest(t)
+	ctx := context.Background()
+
+	resp, err := svc.Similar(ctx, SimilarQuery{File: "retry.go", Line: 1, Levels: []string{"class"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/project/db/conn.go"}, resultPaths(resp.Results))
+
+	resp, err = svc.Similar(ctx, SimilarQuery{File: "retry.go", Line: 1, Scope: Scope{Mode: ScopePath, Value: "http/"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/project/http/client.go"}, resultPaths(resp.Results))
+	require.NotNil(t, resp.Scope)
+	assert.Equal(t, ScopePath, resp.Scope.Mode)
+}
+
+func TestSimilar_SourceNotFound(t *testing.T) {
+	svc := setupSimilarTest(t)
+	ctx := context.Background()
+
+	for _, query := range []SimilarQuery{
+		{ChunkID: "missing"},
+		{File: "retry.go", Line: 100},
+		{File: "missing.go", Line: 1},
+		{},
+	} {
+		_, err := svc.Similar(ctx, query)
+		assert.ErrorIs(t, err, ErrSourceNotFound, "query %+v", query)
+	}
+}
+
+func TestSimilar_AppliesFileFilters(t *testing.T) {
+	svc := setupSimilarTest(t)
+	ctx := context.Background()
+
+	insertIndexedChunk(t, ctx, svc.db, embedder.NewMockEmbedder(), &models.Chunk{
+		FilePath: "/project/web/retry.ts", StartLine: 1, EndLine: 10,
+		Level: models.ChunkLevelMethod, Name: "retry", Language: "typescript",
+		Content: "async function retry(fn) { for (let attempt = 0; attempt < 3; attempt++) {",
+	})
+	require.NoError(t, svc.db.SetTestFiles(ctx, map[string]bool{"/project/db/conn.go": true}))
+
+	tests := []struct {
+		name  string
+		query SimilarQuery
+		want  []string
+	}{
+		{"languages", SimilarQuery{Languages: []string{"typescript"}}, []string{"/project/web/retry.ts"}},
+		{"extensions", SimilarQuery{Extensions: []string{"ts"}}, []string{"/project/web/retry.ts"}},
+		{"include", SimilarQuery{Include: []string{"http/**"}}, []string{"/project/http/client.go"}},
+		{"exclude", SimilarQuery{Exclude: []string{"*.go"}}, []string{"/project/web/retry.ts"}},
+		{"tests only", SimilarQuery{Tests: TestsOnly}, []string{"/project/db/conn.go"}},
+		{"tests excluded", SimilarQuery{Tests: TestsExclude}, []string{"/project/http/client.go", "/project/web/retry.ts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.File, tt.query.Line = "retry.go", 1
+			resp, err := svc.Similar(ctx, tt.query)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, resultPaths(resp.Results))
+		})
+	}
+
+	_, err := svc.Similar(ctx, SimilarQuery{File: "retry.go", Line: 1, Tests: "sometimes"})
+	assert.ErrorIs(t, err, ErrInvalidTests)
+}
+
+func TestSimilar_FillsLimitAfterDeduplication(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	mockEmb := embedder.NewMockEmbedder()
+
+	content := "func parseConfig(path string) (*Config, error) { data, err := os.ReadFile(path)"
+	chunks := []*models.Chunk{{
+		FilePath: "/project/config.go", StartLine: 1, EndLine: 10,
+		Level: models.ChunkLevelMethod, Name: "parseConfig", Language: "go", Content: content,
+	}}
+	// The nearest neighbors are splits of one chunk, which collapse into one result
+	for i := 0; i < 4; i++ {
+		chunks = append(chunks, &models.Chunk{
+			FilePath: "/project/legacy/config.go", StartLine: i*10 + 1, EndLine: i*10 + 10,
+			Level: models.ChunkLevelMethod, Name: "parseLegacyConfig", Language: "go", Content: content,
+			ParentChunkID: "legacy-original", ChunkIndex: i, IsPartial: true,
+		})
+	}
+	chunks = append(chunks,
+		&models.Chunk{
+			FilePath: "/project/env.go", StartLine: 1, EndLine: 10,
+			Level: models.ChunkLevelMethod, Name: "loadEnv", Language: "go",
+			Content: "func loadEnv() map[string]string { return os.Environ() }",
+		},
+		&models.Chunk{
+			FilePath: "/project/flags.go", StartLine: 1, EndLine: 10,
+			Level: models.ChunkLevelMethod, Name: "parseFlags", Language: "go",
+			Content: "func parseFlags(args []string) *Flags { fs := flag.NewFlagSet()",
+		},
+	)
+	for _, chunk := range chunks {
+		insertIndexedChunk(t, ctx, database, mockEmb, chunk)
+	}
+	svc := NewServiceWithOptions(database, mockEmb, ServiceOptions{ProjectRoot: "/project"})
+
+	resp, err := svc.Similar(ctx, SimilarQuery{ChunkID: chunks[0].ID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "/project/legacy/config.go", resp.Results[0].Chunk.FilePath)
+	assert.Equal(t, 4, resp.Results[0].MatchedSplits)
+}
@@ -0,0 +1,78 @@
+// Package tokenize splits source code identifiers into the words they are
+// made of, so that keyword search can match "user email" against
+// getUserByEmail. The same splitting is applied when chunks are indexed and
+// when queries are preprocessed.
+package tokenize
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// identifierRegex matches identifiers, including dotted and kebab-case
+// names such as pkg.Func and user-profile.
+var identifierRegex = regexp.MustCompile(`[\p{L}_$][\p{L}\p{N}_$]*(?:[.\-][\p{L}_$][\p{L}\p{N}_$]*)*`)
+
+// SplitIdentifier splits an identifier into its lowercase sub-words. It
+// splits on camelCase and PascalCase boundaries, acronyms (HTTPServer is
+// "http" and "server"), and on the separators of snake_case, kebab-case and
+// dotted names. Digits stay with the word they follow, so utf8Decode is
+// "utf8" and "decode". A single-word identifier gives one lowercase word.
+func SplitIdentifier(ident string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(ident, isSeparator) {
+		words = append(words, splitCase(part)...)
+	}
+	return words
+}
+
+// isSeparator reports whether r separates the parts of an identifier.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// splitCase splits a word without separators on case changes.
+func splitCase(word string) []string {
+	runes := []rune(word)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prev := runes[i-1]
+		// getUser, md5Sum
+		boundary := unicode.IsLower(prev) || unicode.IsDigit(prev)
+		// HTTPServer: the last capital of an acronym starts the next word
+		if unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			boundary = true
+		}
+		if boundary {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+	}
+	return append(words, strings.ToLower(string(runes[start:])))
+}
+
+// SubWords returns the distinct sub-words of the compound identifiers in
+// text, in order of first appearance. Identifiers made of a single word are
+// left out, since a word tokenizer already indexes them.
+func SubWords(text string) []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, ident := range identifierRegex.FindAllString(text, -1) {
+		parts := SplitIdentifier(ident)
+		if len(parts) < 2 {
+			continue
+		}
+		for _, part := range parts {
+			if !seen[part] {
+				seen[part] = true
+				words = append(words, part)
+			}
+		}
+	}
+	return words
+}
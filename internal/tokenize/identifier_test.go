@@ -0,0 +1,43 @@
+package tokenize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := []struct {
+		ident string
+		want  []string
+	}{
+		{"getUserByEmail", []string{"get", "user", "by", "email"}},
+		{"GetUserByEmail", []string{"get", "user", "by", "email"}},
+		{"get_user_by_email", []string{"get", "user", "by", "email"}},
+		{"GET_USER", []string{"get", "user"}},
+		{"user-profile", []string{"user", "profile"}},
+		{"pkg.Func", []string{"pkg", "func"}},
+		{"HTTPServer", []string{"http", "server"}},
+		{"parseHTTPRequest", []string{"parse", "http", "request"}},
+		{"userID", []string{"user", "id"}},
+		{"utf8Decode", []string{"utf8", "decode"}},
+		{"md5Sum", []string{"md5", "sum"}},
+		{"__init__", []string{"init"}},
+		{"retry", []string{"retry"}},
+		{"URL", []string{"url"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SplitIdentifier(tt.ident), tt.ident)
+	}
+}
+
+func TestSubWords(t *testing.T) {
+	text := "func (s *HTTPServer) getUser(id int) { return s.store.findUser(id) }"
+
+	assert.Equal(t, []string{"http", "server", "get", "user", "s", "store", "find"}, SubWords(text))
+}
+
+func TestSubWords_NoCompoundIdentifiers(t *testing.T) {
+	assert.Empty(t, SubWords("return the value, 42"))
+	assert.Empty(t, SubWords(""))
+}